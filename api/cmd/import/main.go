@@ -0,0 +1,70 @@
+package main
+
+import (
+	"api/src/config"
+	"api/src/database"
+	"api/src/exports"
+	"api/src/repository"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+)
+
+// Recria uma conta a partir de um arquivo de exportação de dados.
+//
+//	go run ./cmd/import [-dry-run] [-password senha] arquivo.zip
+//
+// Sem -password, uma senha temporária é gerada e exibida no relatório. Com a
+// busca em memória, as publicações importadas aparecem depois de reiniciar a API.
+func main() {
+	dryRun := flag.Bool("dry-run", false, "apenas lista o plano e os conflitos, sem gravar nada")
+	password := flag.String("password", "", "senha da conta importada")
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		fmt.Println("uso: import [-dry-run] [-password senha] arquivo.zip")
+		os.Exit(2)
+	}
+
+	data, err := ioutil.ReadFile(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	takeout, err := exports.Read(data)
+	if err != nil {
+		log.Fatal(err)
+	}
+	temporary, err := exports.PrepareProfile(&takeout, *password)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	config.Load()
+	db, err := database.Connect()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	report, err := repository.NewImportsRepository(db).Import(takeout, *dryRun)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if report.UserID != 0 {
+		report.TemporaryPassword = temporary
+	}
+
+	output, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(string(output))
+
+	if len(report.Conflicts) > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"api/src/config"
+	"api/src/database"
+	"api/src/repository"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+)
+
+// Reconstrói ou verifica as timelines do feed.
+//
+//	go run ./cmd/timelines rebuild [userId]
+//	go run ./cmd/timelines check [userId]
+//
+// Sem userId, a operação é feita para todos os usuários.
+func main() {
+	if len(os.Args) < 2 || (os.Args[1] != "rebuild" && os.Args[1] != "check") {
+		fmt.Println("uso: timelines rebuild|check [userId]")
+		os.Exit(2)
+	}
+
+	config.Load()
+	db, err := database.Connect()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := repository.NewTimelinesRepository(db)
+
+	var userIDs []uint64
+	if len(os.Args) > 2 {
+		userID, err := strconv.ParseUint(os.Args[2], 10, 64)
+		if err != nil {
+			log.Fatal(err)
+		}
+		userIDs = []uint64{userID}
+	} else if userIDs, err = repo.UserIDs(); err != nil {
+		log.Fatal(err)
+	}
+
+	inconsistent := 0
+	for _, userID := range userIDs {
+		if os.Args[1] == "rebuild" {
+			if err = repo.Rebuild(userID); err != nil {
+				log.Fatal(err)
+			}
+			continue
+		}
+
+		check, err := repo.Check(userID)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if check.Missing > 0 || check.Extra > 0 {
+			inconsistent++
+			fmt.Printf("usuário %d: %d faltando, %d sobrando\n", check.UserID, check.Missing, check.Extra)
+		}
+	}
+
+	if os.Args[1] == "rebuild" {
+		fmt.Printf("%d timelines reconstruídas\n", len(userIDs))
+		return
+	}
+
+	fmt.Printf("%d de %d timelines inconsistentes\n", inconsistent, len(userIDs))
+	if inconsistent > 0 {
+		os.Exit(1)
+	}
+}
@@ -1,15 +1,88 @@
 package main
 
 import (
+	"api/src/accounts"
 	"api/src/config"
+	"api/src/controllers"
+	"api/src/database"
+	"api/src/events"
+	"api/src/exports"
+	"api/src/feed"
+	"api/src/filter"
+	"api/src/jobs"
+	"api/src/models"
+	"api/src/oidc"
+	"api/src/repository"
 	"api/src/router"
+	"api/src/scheduler"
+	"api/src/search"
+	"api/src/storage"
+	"api/src/webhooks"
 	"fmt"
 	"log"
 	"net/http"
+	"time"
 )
 
 func main() {
 	config.Load()
+	events.Broker = events.NewMemoryHub(config.StreamBufferSize)
+	feed.Default = feed.NewRanker(feed.Weights{
+		Recency:      config.FeedRecencyWeight,
+		Likes:        config.FeedLikesWeight,
+		Comments:     config.FeedCommentsWeight,
+		Affinity:     config.FeedAffinityWeight,
+		HalfLife:     config.FeedHalfLife,
+		SecondDegree: config.FeedSecondDegreeWeight,
+	})
+	if config.StorageDriver == "s3" {
+		storage.Store = storage.NewS3Store(
+			config.S3Endpoint, config.S3Region, config.S3Bucket, config.S3AccessKey, config.S3SecretKey,
+		)
+	} else {
+		storage.Store = storage.NewLocalStore(config.StoragePath)
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		log.Fatal(err)
+	}
+	filter.Default = filter.NewPipeline(
+		filter.NewRepeatedChars(config.FilterMaxRepeatedChars, config.FilterRepeatedAction),
+		filter.NewDuplicate(
+			repository.NewPublishRepository(db).HasDuplicate, config.FilterDuplicateWindow, config.FilterDuplicateAction,
+		),
+	)
+	if err = controllers.ReloadFilters(db); err != nil {
+		log.Fatal(err)
+	}
+
+	go webhooks.NewDispatcher(db, config.WebhookInterval).Run()
+	go scheduler.NewScheduler(
+		repository.NewPublishRepository(db), scheduler.SystemClock{}, config.SchedulerInterval,
+		func(publish models.Publish) error { return controllers.AnnouncePublish(db, publish) },
+	).Run()
+	go accounts.NewPurger(repository.NewUsersRepository(db), scheduler.SystemClock{}, config.PurgeInterval).Run()
+
+	runner := jobs.NewRunner(repository.NewJobsRepository(db), scheduler.SystemClock{}, config.JobInterval)
+	runner.Register(models.JobExport, exports.NewHandler(db, config.ExportRetention))
+	runner.Register(models.JobExportExpire, exports.ExpireHandler)
+	go runner.Run()
+
+	for name, provider := range config.OIDCProviders {
+		oidc.Providers[name] = oidc.NewProvider(provider, &http.Client{Timeout: 10 * time.Second})
+	}
+
+	if config.SearchDriver == "memory" {
+		index := search.NewMemoryIndex()
+		if err = repository.NewPublishRepository(db).Each(index.Index); err != nil {
+			log.Fatal(err)
+		}
+		search.Engine = index
+	} else {
+		search.Engine = search.NewMySQLIndex(db)
+	}
+
 	fmt.Println("Rodando API")
 	r := router.Generate()
 
@@ -1,6 +1,35 @@
 CREATE DATABASE IF NOT EXISTS devbook;
 USE devbook;
 
+DROP TABLE IF EXISTS user_identities;
+DROP TABLE IF EXISTS oauth_states;
+DROP TABLE IF EXISTS api_keys;
+DROP TABLE IF EXISTS sessions;
+DROP TABLE IF EXISTS audit_head;
+DROP TABLE IF EXISTS audit_log;
+DROP TABLE IF EXISTS jobs;
+DROP TABLE IF EXISTS content_filters;
+DROP TABLE IF EXISTS moderation_actions;
+DROP TABLE IF EXISTS reports;
+DROP TABLE IF EXISTS poll_votes;
+DROP TABLE IF EXISTS poll_voters;
+DROP TABLE IF EXISTS poll_options;
+DROP TABLE IF EXISTS polls;
+DROP TABLE IF EXISTS bookmarks;
+DROP TABLE IF EXISTS collections;
+DROP TABLE IF EXISTS suggestion_dismissals;
+DROP TABLE IF EXISTS timelines;
+DROP TABLE IF EXISTS publish_media;
+DROP TABLE IF EXISTS media;
+DROP TABLE IF EXISTS follow_requests;
+DROP TABLE IF EXISTS mutes;
+DROP TABLE IF EXISTS blocks;
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhook_outbox;
+DROP TABLE IF EXISTS webhooks;
+DROP TABLE IF EXISTS notification_preferences;
+DROP TABLE IF EXISTS notifications;
+DROP TABLE IF EXISTS publish_likes;
 DROP TABLE IF EXISTS publishes;
 DROP TABLE IF EXISTS followers;
 DROP TABLE IF EXISTS users;
@@ -9,9 +38,20 @@ CREATE TABLE users(
     id INT auto_increment primary key,
     name VARCHAR(50) NOT NULL,
     nick VARCHAR(50) NOT NULL UNIQUE,
-    email VARCHAR(50) NOT NULL UNIQUE,
+    email VARCHAR(255) NOT NULL UNIQUE,
     password VARCHAR(100) NOT NULL,
-    created_at timestamp default current_timestamp()
+    role VARCHAR(20) NOT NULL DEFAULT 'user',
+    is_private BOOLEAN NOT NULL DEFAULT FALSE,
+    bio VARCHAR(160) NOT NULL DEFAULT '',
+    location VARCHAR(50) NOT NULL DEFAULT '',
+    website VARCHAR(100) NOT NULL DEFAULT '',
+    avatar_url VARCHAR(255) NOT NULL DEFAULT '',
+    banner_url VARCHAR(255) NOT NULL DEFAULT '',
+    avatar_key VARCHAR(100) NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'active',
+    delete_after timestamp NULL,
+    created_at timestamp default current_timestamp(),
+    INDEX (status, delete_after)
 ) ENGINE=INNODB;
 
 CREATE TABLE followers(
@@ -29,5 +69,327 @@ CREATE TABLE publishes(
     author_id int not null,
     FOREIGN KEY (author_id) REFERENCES users(id) ON DELETE CASCADE,
     likes int default 0,
+    created_at timestamp default current_timestamp,
+    status varchar(20) not null default 'published',
+    publish_at timestamp null,
+    parent_id int null,
+    FOREIGN KEY (parent_id) REFERENCES publishes(id) ON DELETE SET NULL,
+    FULLTEXT (title, content),
+    INDEX (status, publish_at)
+) ENGINE=INNODB;
+
+CREATE TABLE publish_likes(
+    publish_id int not null,
+    FOREIGN KEY (publish_id) REFERENCES publishes(id) ON DELETE CASCADE,
+    user_id int not null,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    primary key (publish_id, user_id)
+) ENGINE=INNODB;
+
+CREATE TABLE notifications(
+    id int auto_increment primary key,
+    user_id int not null,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    actor_id int null,
+    FOREIGN KEY (actor_id) REFERENCES users(id) ON DELETE CASCADE,
+    type varchar(20) not null,
+    publish_id int null,
+    FOREIGN KEY (publish_id) REFERENCES publishes(id) ON DELETE CASCADE,
+    report_id int null,
+    is_read boolean not null default false,
+    created_at timestamp default current_timestamp,
+    index (user_id, is_read)
+) ENGINE=INNODB;
+
+CREATE TABLE notification_preferences(
+    user_id int not null,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    type varchar(20) not null,
+    enabled boolean not null default true,
+    primary key (user_id, type)
+) ENGINE=INNODB;
+
+CREATE TABLE webhooks(
+    id int auto_increment primary key,
+    user_id int not null,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    url varchar(255) not null,
+    secret varchar(64) not null,
+    events varchar(255) not null,
+    active boolean not null default true,
+    created_at timestamp default current_timestamp
+) ENGINE=INNODB;
+
+CREATE TABLE webhook_outbox(
+    id int auto_increment primary key,
+    event_type varchar(50) not null,
+    user_id int not null,
+    payload text not null,
+    dispatched boolean not null default false,
+    created_at timestamp default current_timestamp,
+    index (dispatched)
+) ENGINE=INNODB;
+
+CREATE TABLE webhook_deliveries(
+    id int auto_increment primary key,
+    webhook_id int not null,
+    FOREIGN KEY (webhook_id) REFERENCES webhooks(id) ON DELETE CASCADE,
+    outbox_id int not null,
+    FOREIGN KEY (outbox_id) REFERENCES webhook_outbox(id) ON DELETE CASCADE,
+    status varchar(20) not null default 'pending',
+    attempts int not null default 0,
+    status_code int not null default 0,
+    error varchar(255) not null default '',
+    next_attempt_at timestamp default current_timestamp,
+    created_at timestamp default current_timestamp,
+    index (status, next_attempt_at)
+) ENGINE=INNODB;
+
+CREATE TABLE blocks(
+    user_id int not null,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    blocked_id int not null,
+    FOREIGN KEY (blocked_id) REFERENCES users(id) ON DELETE CASCADE,
+    primary key (user_id, blocked_id)
+) ENGINE=INNODB;
+
+CREATE TABLE mutes(
+    user_id int not null,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    muted_id int not null,
+    FOREIGN KEY (muted_id) REFERENCES users(id) ON DELETE CASCADE,
+    primary key (user_id, muted_id)
+) ENGINE=INNODB;
+
+CREATE TABLE follow_requests(
+    user_id int not null,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    requester_id int not null,
+    FOREIGN KEY (requester_id) REFERENCES users(id) ON DELETE CASCADE,
+    created_at timestamp default current_timestamp,
+    primary key (user_id, requester_id)
+) ENGINE=INNODB;
+
+CREATE TABLE media(
+    id int auto_increment primary key,
+    user_id int not null,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    storage_key varchar(100) not null unique,
+    thumbnail_key varchar(100) not null,
+    content_type varchar(50) not null,
+    size int not null,
+    width int not null,
+    height int not null,
     created_at timestamp default current_timestamp
-)
\ No newline at end of file
+) ENGINE=INNODB;
+
+CREATE TABLE publish_media(
+    publish_id int not null,
+    FOREIGN KEY (publish_id) REFERENCES publishes(id) ON DELETE CASCADE,
+    media_id int not null,
+    FOREIGN KEY (media_id) REFERENCES media(id) ON DELETE CASCADE,
+    position int not null default 0,
+    primary key (publish_id, media_id)
+) ENGINE=INNODB;
+
+CREATE TABLE timelines(
+    user_id int not null,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    publish_id int not null,
+    FOREIGN KEY (publish_id) REFERENCES publishes(id) ON DELETE CASCADE,
+    author_id int not null,
+    primary key (user_id, publish_id),
+    index (user_id, author_id)
+) ENGINE=INNODB;
+
+CREATE TABLE suggestion_dismissals(
+    user_id int not null,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    dismissed_id int not null,
+    FOREIGN KEY (dismissed_id) REFERENCES users(id) ON DELETE CASCADE,
+    created_at timestamp default current_timestamp,
+    primary key (user_id, dismissed_id)
+) ENGINE=INNODB;
+
+CREATE TABLE collections(
+    id int auto_increment primary key,
+    user_id int not null,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    name varchar(50) not null,
+    created_at timestamp default current_timestamp,
+    unique (user_id, name)
+) ENGINE=INNODB;
+
+CREATE TABLE bookmarks(
+    user_id int not null,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    publish_id int not null,
+    FOREIGN KEY (publish_id) REFERENCES publishes(id) ON DELETE CASCADE,
+    collection_id int null,
+    FOREIGN KEY (collection_id) REFERENCES collections(id) ON DELETE SET NULL,
+    created_at timestamp default current_timestamp,
+    primary key (user_id, publish_id)
+) ENGINE=INNODB;
+
+CREATE TABLE polls(
+    id int auto_increment primary key,
+    publish_id int not null unique,
+    FOREIGN KEY (publish_id) REFERENCES publishes(id) ON DELETE CASCADE,
+    multiple boolean not null default false,
+    expires_at timestamp not null,
+    voters int not null default 0
+) ENGINE=INNODB;
+
+CREATE TABLE poll_options(
+    id int auto_increment primary key,
+    poll_id int not null,
+    FOREIGN KEY (poll_id) REFERENCES polls(id) ON DELETE CASCADE,
+    position int not null,
+    text varchar(50) not null,
+    votes int not null default 0
+) ENGINE=INNODB;
+
+CREATE TABLE poll_voters(
+    poll_id int not null,
+    FOREIGN KEY (poll_id) REFERENCES polls(id) ON DELETE CASCADE,
+    user_id int not null,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    created_at timestamp default current_timestamp,
+    primary key (poll_id, user_id)
+) ENGINE=INNODB;
+
+CREATE TABLE poll_votes(
+    poll_id int not null,
+    user_id int not null,
+    FOREIGN KEY (poll_id, user_id) REFERENCES poll_voters(poll_id, user_id) ON DELETE CASCADE,
+    option_id int not null,
+    FOREIGN KEY (option_id) REFERENCES poll_options(id) ON DELETE CASCADE,
+    primary key (poll_id, user_id, option_id)
+) ENGINE=INNODB;
+
+CREATE TABLE reports(
+    id int auto_increment primary key,
+    reporter_id int null,
+    FOREIGN KEY (reporter_id) REFERENCES users(id) ON DELETE CASCADE,
+    target_type varchar(20) not null,
+    target_user_id int not null,
+    FOREIGN KEY (target_user_id) REFERENCES users(id) ON DELETE CASCADE,
+    publish_id int null,
+    FOREIGN KEY (publish_id) REFERENCES publishes(id) ON DELETE SET NULL,
+    reason varchar(20) not null,
+    details varchar(500) not null default '',
+    status varchar(20) not null default 'open',
+    moderator_id int null,
+    FOREIGN KEY (moderator_id) REFERENCES users(id) ON DELETE SET NULL,
+    created_at timestamp default current_timestamp,
+    updated_at timestamp default current_timestamp on update current_timestamp,
+    index (status, id)
+) ENGINE=INNODB;
+
+CREATE TABLE moderation_actions(
+    id int auto_increment primary key,
+    report_id int not null,
+    FOREIGN KEY (report_id) REFERENCES reports(id) ON DELETE CASCADE,
+    moderator_id int null,
+    FOREIGN KEY (moderator_id) REFERENCES users(id) ON DELETE SET NULL,
+    action varchar(20) not null,
+    reason varchar(500) not null default '',
+    created_at timestamp default current_timestamp
+) ENGINE=INNODB;
+
+CREATE TABLE content_filters(
+    id int auto_increment primary key,
+    kind varchar(20) not null,
+    pattern varchar(255) not null,
+    action varchar(20) not null,
+    created_at timestamp default current_timestamp
+) ENGINE=INNODB;
+
+CREATE TABLE jobs(
+    id int auto_increment primary key,
+    type varchar(50) not null,
+    user_id int null,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    status varchar(20) not null default 'pending',
+    payload text not null,
+    result varchar(255) not null default '',
+    error varchar(255) not null default '',
+    attempts int not null default 0,
+    run_after timestamp default current_timestamp,
+    created_at timestamp default current_timestamp,
+    finished_at timestamp null,
+    index (status, run_after),
+    index (user_id, type)
+) ENGINE=INNODB;
+
+CREATE TABLE audit_log(
+    id int auto_increment primary key,
+    actor_id int not null default 0,
+    target_id int not null default 0,
+    action varchar(50) not null,
+    ip varchar(45) not null default '',
+    user_agent varchar(255) not null default '',
+    details text not null,
+    created_at timestamp not null,
+    prev_hash char(64) not null,
+    hash char(64) not null,
+    index (actor_id, id),
+    index (target_id, id),
+    index (action, id),
+    index (created_at)
+) ENGINE=INNODB;
+
+CREATE TABLE audit_head(
+    id int primary key,
+    hash char(64) not null
+) ENGINE=INNODB;
+
+INSERT INTO audit_head (id, hash) VALUES (1, '');
+
+CREATE TABLE sessions(
+    id int auto_increment primary key,
+    user_id int not null,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    device varchar(100) not null default '',
+    user_agent varchar(255) not null default '',
+    ip varchar(45) not null default '',
+    created_at timestamp default current_timestamp,
+    last_seen_at timestamp default current_timestamp,
+    expires_at timestamp not null,
+    revoked_at timestamp null,
+    index (user_id, revoked_at)
+) ENGINE=INNODB;
+
+CREATE TABLE api_keys(
+    id int auto_increment primary key,
+    user_id int not null,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    name varchar(50) not null,
+    prefix char(12) not null unique,
+    key_hash char(64) not null,
+    scopes varchar(500) not null,
+    expires_at timestamp null,
+    last_used_at timestamp null,
+    created_at timestamp default current_timestamp
+) ENGINE=INNODB;
+
+CREATE TABLE oauth_states(
+    state varchar(64) primary key,
+    provider varchar(50) not null,
+    code_verifier varchar(128) not null,
+    nonce varchar(64) not null,
+    expires_at timestamp not null,
+    created_at timestamp default current_timestamp,
+    index (expires_at)
+) ENGINE=INNODB;
+
+CREATE TABLE user_identities(
+    provider varchar(50) not null,
+    subject varchar(255) not null,
+    user_id int not null,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    email varchar(255) not null default '',
+    created_at timestamp default current_timestamp,
+    primary key (provider, subject),
+    index (user_id)
+) ENGINE=INNODB;
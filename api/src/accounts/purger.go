@@ -0,0 +1,55 @@
+package accounts
+
+import (
+	"api/src/scheduler"
+	"log"
+	"time"
+)
+
+// Quantidade de contas apagadas por transação
+const purgeBatchSize = 50
+
+// Repository apaga de vez as contas excluídas cujo período de carência terminou
+type Repository interface {
+	PurgeDeleted(now time.Time, limit int) ([]uint64, error)
+}
+
+// Purger apaga periodicamente as contas excluídas depois do período de carência
+type Purger struct {
+	repo     Repository
+	clock    scheduler.Clock
+	interval time.Duration
+}
+
+func NewPurger(repo Repository, clock scheduler.Clock, interval time.Duration) *Purger {
+	return &Purger{repo: repo, clock: clock, interval: interval}
+}
+
+// Run executa o purger indefinidamente, devendo ser chamado em uma goroutine
+func (p *Purger) Run() {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := p.Tick(); err != nil {
+			log.Println("purger:", err)
+		}
+	}
+}
+
+// Tick apaga, em lotes, todas as contas vencidas na hora atual do Clock
+func (p *Purger) Tick() error {
+	now := p.clock.Now()
+	for {
+		IDs, err := p.repo.PurgeDeleted(now, purgeBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(IDs) > 0 {
+			log.Printf("purger: %d contas apagadas", len(IDs))
+		}
+		if len(IDs) < purgeBatchSize {
+			return nil
+		}
+	}
+}
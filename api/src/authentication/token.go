@@ -2,6 +2,8 @@ package authentication
 
 import (
 	"api/src/config"
+	"api/src/models"
+	"context"
 	"errors"
 	"fmt"
 	jwt "github.com/dgrijalva/jwt-go"
@@ -11,11 +13,16 @@ import (
 	"time"
 )
 
-func CreateToken(userID uint64) (string, error) {
+// TokenTTL é a validade dos tokens, e também das sessões criadas com eles
+const TokenTTL = time.Hour * 6
+
+// CreateToken gera o token da sessão sessionID do usuário
+func CreateToken(userID, sessionID uint64) (string, error) {
 	permissions := jwt.MapClaims{}
 	permissions["authorized"] = true
-	permissions["exp"] = time.Now().Add(time.Hour * 6).Unix()
+	permissions["exp"] = time.Now().Add(TokenTTL).Unix()
 	permissions["userId"] = userID
+	permissions["sessionId"] = sessionID
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, permissions)
 
 	return token.SignedString(config.SecretKey)
@@ -35,7 +42,34 @@ func ValidateToken(r *http.Request) error {
 	return nil
 }
 
+type contextKey int
+
+const apiKeyContext contextKey = iota
+
+// WithAPIKey guarda na requisição a chave de API com que ela foi autenticada
+func WithAPIKey(r *http.Request, key models.APIKey) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), apiKeyContext, key))
+}
+
+// APIKeyFromRequest retorna a chave de API da requisição, se ela não usou um JWT
+func APIKeyFromRequest(r *http.Request) (models.APIKey, bool) {
+	key, ok := r.Context().Value(apiKeyContext).(models.APIKey)
+	return key, ok
+}
+
+// ExtractAPIKey retorna a chave enviada no cabeçalho Authorization quando ela
+// não é um JWT
+func ExtractAPIKey(r *http.Request) (string, bool) {
+	token := extractToken(r)
+	return token, strings.HasPrefix(token, models.APIKeyPrefix)
+}
+
+// ExtractUserIDFromToken retorna o usuário do JWT ou o dono da chave de API
 func ExtractUserIDFromToken(r *http.Request) (uint64, error) {
+	if key, ok := APIKeyFromRequest(r); ok {
+		return key.UserID, nil
+	}
+
 	strToken := extractToken(r)
 	token, err := jwt.Parse(strToken, getSecretKey)
 	if err != nil {
@@ -55,6 +89,36 @@ func ExtractUserIDFromToken(r *http.Request) (uint64, error) {
 	return userID, nil
 }
 
+// ExtractSessionIDFromToken retorna a sessão do token, ou 0 em tokens emitidos
+// antes de existirem sessões e em requisições com chave de API
+func ExtractSessionIDFromToken(r *http.Request) (uint64, error) {
+	if _, ok := APIKeyFromRequest(r); ok {
+		return 0, nil
+	}
+
+	strToken := extractToken(r)
+	token, err := jwt.Parse(strToken, getSecretKey)
+	if err != nil {
+		return 0, err
+	}
+
+	permissions, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return 0, errors.New("Token inválido")
+	}
+
+	if _, found := permissions["sessionId"]; !found {
+		return 0, nil
+	}
+
+	sessionID, err := strconv.ParseUint(fmt.Sprintf("%.0f", permissions["sessionId"]), 10, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	return sessionID, nil
+}
+
 func extractToken(r *http.Request) string {
 	bearerToken := r.Header.Get("Authorization")
 
@@ -5,6 +5,8 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -14,8 +16,79 @@ var (
 	DbConnStr = ""
 	Port      = 0
 	SecretKey []byte
+	// Quantidade de eventos que cada conexão de stream pode acumular
+	StreamBufferSize = 64
+	// Intervalo entre as execuções do envio de webhooks
+	WebhookInterval = 5 * time.Second
+	// Intervalo entre as verificações de publicações agendadas
+	SchedulerInterval = 15 * time.Second
+
+	// Armazenamento de mídia: "local" ou "s3"
+	StorageDriver = "local"
+	StoragePath   = "uploads"
+	S3Endpoint    = ""
+	S3Region      = ""
+	S3Bucket      = ""
+	S3AccessKey   = ""
+	S3SecretKey   = ""
+	// Tamanho máximo de um upload, em bytes
+	MediaMaxSize int64 = 5 << 20
+	// Dimensões máximas de uma imagem: por lado e em total de pixels
+	MediaMaxDimension = 8192
+	MediaMaxPixels    = 40000000
+	// Validade das URLs assinadas de mídia
+	MediaURLTTL = time.Hour
+
+	// Índice de busca de publicações: "mysql" ou "memory"
+	SearchDriver = "mysql"
+
+	// Pesos do feed ranqueado
+	FeedRecencyWeight      = 1.0
+	FeedLikesWeight        = 0.5
+	FeedCommentsWeight     = 0.8
+	FeedAffinityWeight     = 1.0
+	FeedSecondDegreeWeight = 0.5
+	FeedHalfLife           = 12 * time.Hour
+
+	// Autores com mais seguidores que isso não têm as publicações copiadas
+	// para as timelines; elas são buscadas na leitura
+	FanOutThreshold = 10000
+
+	// Filtro de conteúdo: quantidade de repetições seguidas de um caractere e
+	// janela em que publicações iguais do mesmo autor são consideradas duplicadas
+	FilterMaxRepeatedChars = 10
+	FilterRepeatedAction   = "flag"
+	FilterDuplicateWindow  = 24 * time.Hour
+	FilterDuplicateAction  = "reject"
+
+	// Período em que uma conta excluída pode ser restaurada fazendo login, e
+	// intervalo entre as execuções da rotina que apaga as contas vencidas
+	AccountGracePeriod = 30 * 24 * time.Hour
+	PurgeInterval      = time.Hour
+
+	// Intervalo entre as execuções do runner de jobs e tempo em que o arquivo
+	// de uma exportação de dados fica disponível para download
+	JobInterval     = 5 * time.Second
+	ExportRetention = 7 * 24 * time.Hour
+	// Tamanho máximo de um arquivo enviado para importação, em bytes
+	ImportMaxSize int64 = 100 << 20
+
+	// Provedores OpenID Connect para login externo, pelo nome
+	OIDCProviders = map[string]OIDCProvider{}
 )
 
+// OIDCProvider é a configuração de um provedor OpenID Connect. Cada provedor
+// listado em OIDC_PROVIDERS é lido de OIDC_<NOME>_ISSUER, _CLIENT_ID,
+// _CLIENT_SECRET, _REDIRECT_URL e, opcionalmente, _SCOPES.
+type OIDCProvider struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
 func Load() {
 	var err error
 	if err = godotenv.Load(); err != nil {
@@ -34,4 +107,134 @@ func Load() {
 	)
 
 	SecretKey = []byte(os.Getenv("SECRET_KEY"))
+
+	if bufferSize, err := strconv.Atoi(os.Getenv("STREAM_BUFFER_SIZE")); err == nil && bufferSize > 0 {
+		StreamBufferSize = bufferSize
+	}
+
+	if seconds, err := strconv.Atoi(os.Getenv("WEBHOOK_INTERVAL_SECONDS")); err == nil && seconds > 0 {
+		WebhookInterval = time.Duration(seconds) * time.Second
+	}
+
+	if seconds, err := strconv.Atoi(os.Getenv("SCHEDULER_INTERVAL_SECONDS")); err == nil && seconds > 0 {
+		SchedulerInterval = time.Duration(seconds) * time.Second
+	}
+
+	if driver := os.Getenv("STORAGE_DRIVER"); driver != "" {
+		StorageDriver = driver
+	}
+	if path := os.Getenv("STORAGE_PATH"); path != "" {
+		StoragePath = path
+	}
+	S3Endpoint = os.Getenv("S3_ENDPOINT")
+	S3Region = os.Getenv("S3_REGION")
+	S3Bucket = os.Getenv("S3_BUCKET")
+	S3AccessKey = os.Getenv("S3_ACCESS_KEY")
+	S3SecretKey = os.Getenv("S3_SECRET_KEY")
+
+	if maxSize, err := strconv.ParseInt(os.Getenv("MEDIA_MAX_SIZE"), 10, 64); err == nil && maxSize > 0 {
+		MediaMaxSize = maxSize
+	}
+	if dimension, err := strconv.Atoi(os.Getenv("MEDIA_MAX_DIMENSION")); err == nil && dimension > 0 {
+		MediaMaxDimension = dimension
+	}
+	if pixels, err := strconv.Atoi(os.Getenv("MEDIA_MAX_PIXELS")); err == nil && pixels > 0 {
+		MediaMaxPixels = pixels
+	}
+	if seconds, err := strconv.Atoi(os.Getenv("MEDIA_URL_TTL_SECONDS")); err == nil && seconds > 0 {
+		MediaURLTTL = time.Duration(seconds) * time.Second
+	}
+
+	if driver := os.Getenv("SEARCH_DRIVER"); driver != "" {
+		SearchDriver = driver
+	}
+
+	if threshold, err := strconv.Atoi(os.Getenv("FANOUT_THRESHOLD")); err == nil && threshold > 0 {
+		FanOutThreshold = threshold
+	}
+
+	FeedRecencyWeight = floatEnv("FEED_WEIGHT_RECENCY", FeedRecencyWeight)
+	FeedLikesWeight = floatEnv("FEED_WEIGHT_LIKES", FeedLikesWeight)
+	FeedCommentsWeight = floatEnv("FEED_WEIGHT_COMMENTS", FeedCommentsWeight)
+	FeedAffinityWeight = floatEnv("FEED_WEIGHT_AFFINITY", FeedAffinityWeight)
+	FeedSecondDegreeWeight = floatEnv("FEED_WEIGHT_SECOND_DEGREE", FeedSecondDegreeWeight)
+	if hours := floatEnv("FEED_HALF_LIFE_HOURS", 0); hours > 0 {
+		FeedHalfLife = time.Duration(hours * float64(time.Hour))
+	}
+
+	if repeated, err := strconv.Atoi(os.Getenv("FILTER_MAX_REPEATED_CHARS")); err == nil && repeated > 1 {
+		FilterMaxRepeatedChars = repeated
+	}
+	FilterRepeatedAction = filterEnv("FILTER_REPEATED_ACTION", FilterRepeatedAction)
+	if minutes, err := strconv.Atoi(os.Getenv("FILTER_DUPLICATE_WINDOW_MINUTES")); err == nil && minutes > 0 {
+		FilterDuplicateWindow = time.Duration(minutes) * time.Minute
+	}
+	FilterDuplicateAction = filterEnv("FILTER_DUPLICATE_ACTION", FilterDuplicateAction)
+
+	if days, err := strconv.Atoi(os.Getenv("ACCOUNT_GRACE_PERIOD_DAYS")); err == nil && days >= 0 {
+		AccountGracePeriod = time.Duration(days) * 24 * time.Hour
+	}
+	if seconds, err := strconv.Atoi(os.Getenv("PURGE_INTERVAL_SECONDS")); err == nil && seconds > 0 {
+		PurgeInterval = time.Duration(seconds) * time.Second
+	}
+
+	if seconds, err := strconv.Atoi(os.Getenv("JOB_INTERVAL_SECONDS")); err == nil && seconds > 0 {
+		JobInterval = time.Duration(seconds) * time.Second
+	}
+	if hours, err := strconv.Atoi(os.Getenv("EXPORT_RETENTION_HOURS")); err == nil && hours > 0 {
+		ExportRetention = time.Duration(hours) * time.Hour
+	}
+	if maxSize, err := strconv.ParseInt(os.Getenv("IMPORT_MAX_SIZE"), 10, 64); err == nil && maxSize > 0 {
+		ImportMaxSize = maxSize
+	}
+
+	for _, name := range strings.Split(os.Getenv("OIDC_PROVIDERS"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			OIDCProviders[name] = oidcProviderEnv(name)
+		}
+	}
+}
+
+// oidcProviderEnv lê a configuração do provedor, com o nome em maiúsculas e
+// caracteres que não sejam letras ou números trocados por _
+func oidcProviderEnv(name string) OIDCProvider {
+	prefix := "OIDC_" + strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, strings.ToUpper(name)) + "_"
+
+	scopes := strings.Fields(os.Getenv(prefix + "SCOPES"))
+	if len(scopes) == 0 {
+		scopes = []string{"openid", "email", "profile"}
+	}
+
+	return OIDCProvider{
+		Name:         name,
+		Issuer:       strings.TrimSuffix(os.Getenv(prefix+"ISSUER"), "/"),
+		ClientID:     os.Getenv(prefix + "CLIENT_ID"),
+		ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+		RedirectURL:  os.Getenv(prefix + "REDIRECT_URL"),
+		Scopes:       scopes,
+	}
+}
+
+// filterEnv lê a ação de uma regra do filtro ou retorna o valor padrão
+func filterEnv(name, fallback string) string {
+	switch value := os.Getenv(name); value {
+	case "allow", "flag", "reject":
+		return value
+	default:
+		return fallback
+	}
+}
+
+// floatEnv lê um número decimal da variável de ambiente ou retorna o valor padrão
+func floatEnv(name string, fallback float64) float64 {
+	value, err := strconv.ParseFloat(os.Getenv(name), 64)
+	if err != nil {
+		return fallback
+	}
+	return value
 }
@@ -0,0 +1,147 @@
+package controllers
+
+import (
+	"api/src/authentication"
+	"api/src/database"
+	"api/src/models"
+	"api/src/repository"
+	"api/src/responses"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// CreateAPIKey cria uma chave de API para o usuário. A chave só é exibida
+// nesta resposta; depois disso apenas o prefixo fica visível.
+func CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	userID, err := authentication.ExtractUserIDFromToken(r)
+	if err != nil {
+		responses.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	requestBody, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var key models.APIKey
+	if err = json.Unmarshal(requestBody, &key); err != nil {
+		responses.Error(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	if err = key.Prepare(); err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+	if err = key.Generate(); err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	key.UserID = userID
+
+	db, err := database.Connect()
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewAPIKeysRepository(db)
+	if key.ID, err = repo.Create(key); err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if err = audit(db, r, models.AuditEntry{
+		ActorID:  userID,
+		TargetID: userID,
+		Action:   models.AuditAPIKeyCreated,
+		Details: map[string]string{
+			"key_id": strconv.FormatUint(key.ID, 10),
+			"name":   key.Name,
+			"scopes": strings.Join(key.Scopes, ","),
+		},
+	}); err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	responses.JSON(w, http.StatusCreated, key)
+}
+
+func GetAPIKeys(w http.ResponseWriter, r *http.Request) {
+	userID, err := authentication.ExtractUserIDFromToken(r)
+	if err != nil {
+		responses.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewAPIKeysRepository(db)
+	keys, err := repo.Get(userID)
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	responses.JSON(w, http.StatusOK, keys)
+}
+
+// DeleteAPIKey revoga a chave, que deixa de ser aceita imediatamente
+func DeleteAPIKey(w http.ResponseWriter, r *http.Request) {
+	userID, err := authentication.ExtractUserIDFromToken(r)
+	if err != nil {
+		responses.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	params := mux.Vars(r)
+	keyID, err := strconv.ParseUint(params["keyId"], 10, 64)
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewAPIKeysRepository(db)
+	deleted, err := repo.Delete(keyID, userID)
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	if !deleted {
+		responses.Error(w, http.StatusNotFound, errors.New("Chave de API não encontrada"))
+		return
+	}
+
+	if err = audit(db, r, models.AuditEntry{
+		ActorID:  userID,
+		TargetID: userID,
+		Action:   models.AuditAPIKeyRevoked,
+		Details:  map[string]string{"key_id": strconv.FormatUint(keyID, 10)},
+	}); err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	responses.JSON(w, http.StatusNoContent, nil)
+}
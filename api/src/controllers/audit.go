@@ -0,0 +1,104 @@
+package controllers
+
+import (
+	"api/src/database"
+	"api/src/models"
+	"api/src/repository"
+	"api/src/responses"
+	"database/sql"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// GetAuditLog consulta o log de auditoria. Aceita os filtros actor, target,
+// action e o intervalo from/to em RFC 3339, além da paginação.
+func GetAuditLog(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := models.AuditFilter{Action: query.Get("action")}
+
+	var err error
+	if raw := query.Get("actor"); raw != "" {
+		if filter.ActorID, err = strconv.ParseUint(raw, 10, 64); err != nil {
+			responses.Error(w, http.StatusBadRequest, errors.New("actor inválido"))
+			return
+		}
+	}
+	if raw := query.Get("target"); raw != "" {
+		if filter.TargetID, err = strconv.ParseUint(raw, 10, 64); err != nil {
+			responses.Error(w, http.StatusBadRequest, errors.New("target inválido"))
+			return
+		}
+	}
+	if raw := query.Get("from"); raw != "" {
+		if filter.From, err = time.Parse(time.RFC3339, raw); err != nil {
+			responses.Error(w, http.StatusBadRequest, errors.New("from deve estar no formato RFC 3339"))
+			return
+		}
+	}
+	if raw := query.Get("to"); raw != "" {
+		if filter.To, err = time.Parse(time.RFC3339, raw); err != nil {
+			responses.Error(w, http.StatusBadRequest, errors.New("to deve estar no formato RFC 3339"))
+			return
+		}
+	}
+
+	_, limit, offset := pagination(r)
+
+	db, err := database.Connect()
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewAuditRepository(db)
+	entries, err := repo.Get(filter, limit, offset)
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	responses.JSON(w, http.StatusOK, entries)
+}
+
+// VerifyAuditLog confere a cadeia de hashes do log de auditoria
+func VerifyAuditLog(w http.ResponseWriter, r *http.Request) {
+	db, err := database.Connect()
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewAuditRepository(db)
+	verification, err := repo.Verify()
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	responses.JSON(w, http.StatusOK, verification)
+}
+
+// audit registra a ação no log de auditoria com o IP e o user agent da requisição
+func audit(db *sql.DB, r *http.Request, entry models.AuditEntry) error {
+	entry.IP = clientIP(r)
+	entry.UserAgent = r.UserAgent()
+
+	repo := repository.NewAuditRepository(db)
+	_, err := repo.Append(entry)
+	return err
+}
+
+// clientIP usa o endereço da conexão; cabeçalhos como X-Forwarded-For podem
+// ser forjados pelo cliente e por isso são ignorados
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
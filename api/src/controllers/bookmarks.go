@@ -0,0 +1,330 @@
+package controllers
+
+import (
+	"api/src/authentication"
+	"api/src/database"
+	"api/src/models"
+	"api/src/repository"
+	"api/src/responses"
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+)
+
+// BookmarkPublish salva a publicação, opcionalmente em uma coleção do usuário
+func BookmarkPublish(w http.ResponseWriter, r *http.Request) {
+	userID, err := authentication.ExtractUserIDFromToken(r)
+	if err != nil {
+		responses.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	params := mux.Vars(r)
+	publishID, err := strconv.ParseUint(params["publishId"], 10, 64)
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	requestBody, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var bookmark models.Bookmark
+	if len(requestBody) > 0 {
+		if err = json.Unmarshal(requestBody, &bookmark); err != nil {
+			responses.Error(w, http.StatusUnprocessableEntity, err)
+			return
+		}
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	publishesRepo := repository.NewPublishRepository(db)
+	publish, err := publishesRepo.GetPublish(publishID)
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	usersRepo := repository.NewUsersRepository(db)
+	blocked, err := usersRepo.IsBlocked(userID, publish.AuthorID)
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	allowed, err := usersRepo.CanViewContent(userID, publish.AuthorID)
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	if publish.ID == 0 || !publish.IsPublished() || blocked || !allowed {
+		responses.Error(w, http.StatusNotFound, errors.New("Publicação não encontrada"))
+		return
+	}
+
+	repo := repository.NewBookmarksRepository(db)
+	if bookmark.CollectionID != 0 {
+		collection, err := repo.GetCollection(bookmark.CollectionID)
+		if err != nil {
+			responses.Error(w, http.StatusInternalServerError, err)
+			return
+		}
+		if collection.UserID != userID {
+			responses.Error(w, http.StatusForbidden, errors.New("Não é possível salvar em uma coleção que não seja a sua"))
+			return
+		}
+	}
+
+	if err = repo.Save(userID, publishID, bookmark.CollectionID); err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	responses.JSON(w, http.StatusNoContent, nil)
+}
+
+func UnbookmarkPublish(w http.ResponseWriter, r *http.Request) {
+	userID, err := authentication.ExtractUserIDFromToken(r)
+	if err != nil {
+		responses.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	params := mux.Vars(r)
+	publishID, err := strconv.ParseUint(params["publishId"], 10, 64)
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewBookmarksRepository(db)
+	if err = repo.Remove(userID, publishID); err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	responses.JSON(w, http.StatusNoContent, nil)
+}
+
+// GetBookmarks lista as publicações salvas pelo usuário, filtrando pela coleção em collection
+func GetBookmarks(w http.ResponseWriter, r *http.Request) {
+	userID, err := authentication.ExtractUserIDFromToken(r)
+	if err != nil {
+		responses.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	var collectionID uint64
+	if rawCollection := r.URL.Query().Get("collection"); rawCollection != "" {
+		if collectionID, err = strconv.ParseUint(rawCollection, 10, 64); err != nil {
+			responses.Error(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	_, limit, offset := pagination(r)
+
+	db, err := database.Connect()
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewBookmarksRepository(db)
+	publishes, err := repo.Get(userID, collectionID, limit, offset)
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if err = decoratePublishes(db, userID, publishes); err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	responses.JSON(w, http.StatusOK, publishes)
+}
+
+func CreateCollection(w http.ResponseWriter, r *http.Request) {
+	userID, err := authentication.ExtractUserIDFromToken(r)
+	if err != nil {
+		responses.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	requestBody, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var collection models.Collection
+	if err = json.Unmarshal(requestBody, &collection); err != nil {
+		responses.Error(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	if err = collection.Prepare(); err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+	collection.UserID = userID
+
+	db, err := database.Connect()
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewBookmarksRepository(db)
+	collection.ID, err = repo.CreateCollection(collection)
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	responses.JSON(w, http.StatusCreated, collection)
+}
+
+func GetCollections(w http.ResponseWriter, r *http.Request) {
+	userID, err := authentication.ExtractUserIDFromToken(r)
+	if err != nil {
+		responses.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewBookmarksRepository(db)
+	collections, err := repo.GetCollections(userID)
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	responses.JSON(w, http.StatusOK, collections)
+}
+
+func UpdateCollection(w http.ResponseWriter, r *http.Request) {
+	userID, err := authentication.ExtractUserIDFromToken(r)
+	if err != nil {
+		responses.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	params := mux.Vars(r)
+	collectionID, err := strconv.ParseUint(params["collectionId"], 10, 64)
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	requestBody, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var collection models.Collection
+	if err = json.Unmarshal(requestBody, &collection); err != nil {
+		responses.Error(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	if err = collection.Prepare(); err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewBookmarksRepository(db)
+	storedCollection, err := repo.GetCollection(collectionID)
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if storedCollection.UserID != userID {
+		responses.Error(w, http.StatusForbidden, errors.New("Não é possível alterar uma coleção que não seja a sua"))
+		return
+	}
+
+	if err = repo.RenameCollection(collectionID, collection.Name); err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	responses.JSON(w, http.StatusNoContent, nil)
+}
+
+func DeleteCollection(w http.ResponseWriter, r *http.Request) {
+	userID, err := authentication.ExtractUserIDFromToken(r)
+	if err != nil {
+		responses.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	params := mux.Vars(r)
+	collectionID, err := strconv.ParseUint(params["collectionId"], 10, 64)
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewBookmarksRepository(db)
+	storedCollection, err := repo.GetCollection(collectionID)
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if storedCollection.UserID != userID {
+		responses.Error(w, http.StatusForbidden, errors.New("Não é possível deletar uma coleção que não seja a sua"))
+		return
+	}
+
+	if err = repo.DeleteCollection(collectionID); err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	responses.JSON(w, http.StatusNoContent, nil)
+}
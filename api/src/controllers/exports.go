@@ -0,0 +1,210 @@
+package controllers
+
+import (
+	"api/src/authentication"
+	"api/src/config"
+	"api/src/database"
+	"api/src/exports"
+	"api/src/media"
+	"api/src/models"
+	"api/src/repository"
+	"api/src/responses"
+	"api/src/search"
+	"errors"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// ExportUser enfileira a exportação dos dados do usuário. Se já houver uma
+// exportação em andamento, ela é retornada em vez de criar outra.
+func ExportUser(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	userID, err := strconv.ParseUint(params["userId"], 10, 64)
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	userIDInToken, err := authentication.ExtractUserIDFromToken(r)
+	if err != nil {
+		responses.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	if userID != userIDInToken {
+		responses.Error(w, http.StatusForbidden, errors.New("Não é possível exportar os dados de um usuário que não seja o seu"))
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewJobsRepository(db)
+	job, found, err := repo.GetUnfinished(models.JobExport, userID)
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if !found {
+		jobID, err := repo.Create(models.Job{Type: models.JobExport, UserID: userID, Payload: "{}"})
+		if err != nil {
+			responses.Error(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if job, err = repo.Get(jobID); err != nil {
+			responses.Error(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	responses.JSON(w, http.StatusAccepted, job)
+}
+
+// GetExport retorna a situação da exportação e, quando concluída e ainda
+// disponível, a URL assinada para baixar o arquivo
+func GetExport(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	userID, err := strconv.ParseUint(params["userId"], 10, 64)
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	jobID, err := strconv.ParseUint(params["jobId"], 10, 64)
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	userIDInToken, err := authentication.ExtractUserIDFromToken(r)
+	if err != nil {
+		responses.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	if userID != userIDInToken {
+		responses.Error(w, http.StatusForbidden, errors.New("Não é possível ver a exportação de um usuário que não seja o seu"))
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewJobsRepository(db)
+	job, err := repo.Get(jobID)
+	if err != nil || job.Type != models.JobExport || job.UserID != userID {
+		responses.Error(w, http.StatusNotFound, errors.New("Exportação não encontrada"))
+		return
+	}
+
+	if job.Status == models.JobDone && job.FinishedAt != nil {
+		expiresAt := job.FinishedAt.Add(config.ExportRetention)
+		job.ExpiresAt = &expiresAt
+		if time.Now().Before(expiresAt) {
+			job.DownloadURL = media.SignedURL(job.Result)
+		}
+	}
+
+	responses.JSON(w, http.StatusOK, job)
+}
+
+// ImportUser recria uma conta a partir de um arquivo de exportação enviado no
+// campo "file". Com ?dry_run=true apenas informa o plano e os conflitos.
+func ImportUser(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, config.ImportMaxSize+1<<20)
+	if err := r.ParseMultipartForm(config.ImportMaxSize); err != nil {
+		responses.Error(w, http.StatusRequestEntityTooLarge, errors.New("O arquivo excede o tamanho máximo"))
+		return
+	}
+
+	dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dry_run"))
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, errors.New("Envie o arquivo no campo file"))
+		return
+	}
+	defer file.Close()
+
+	data, err := ioutil.ReadAll(file)
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	takeout, err := exports.Read(data)
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	temporary, err := exports.PrepareProfile(&takeout, r.FormValue("password"))
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	report, err := repository.NewImportsRepository(db).Import(takeout, dryRun)
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if dryRun {
+		responses.JSON(w, http.StatusOK, report)
+		return
+	}
+	if len(report.Conflicts) > 0 {
+		responses.JSON(w, http.StatusConflict, report)
+		return
+	}
+
+	adminID, err := authentication.ExtractUserIDFromToken(r)
+	if err != nil {
+		responses.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+	if err = audit(db, r, models.AuditEntry{
+		ActorID:  adminID,
+		TargetID: report.UserID,
+		Action:   models.AuditUserImported,
+		Details:  map[string]string{"nick": report.Nick},
+	}); err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	for _, publish := range report.Imported {
+		if !publish.IsPublished() {
+			continue
+		}
+		if err = search.Engine.Index(publish); err != nil {
+			log.Println("busca:", err)
+		}
+	}
+
+	report.TemporaryPassword = temporary
+	responses.JSON(w, http.StatusCreated, report)
+}
@@ -0,0 +1,69 @@
+package controllers
+
+import (
+	"api/src/authentication"
+	"api/src/database"
+	"api/src/feed"
+	"api/src/models"
+	"api/src/repository"
+	"api/src/responses"
+	"errors"
+	"net/http"
+	"time"
+)
+
+const (
+	// Janela e quantidade máxima de publicações consideradas no feed ranqueado
+	rankedFeedWindow     = 7 * 24 * time.Hour
+	rankedFeedCandidates = 500
+)
+
+// GetFeed retorna o feed cronológico (mode=chronological, padrão) ou o ranqueado (mode=ranked)
+func GetFeed(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Query().Get("mode") {
+	case "", "chronological":
+		GetPublishes(w, r)
+	case "ranked":
+		getRankedFeed(w, r)
+	default:
+		responses.Error(w, http.StatusBadRequest, errors.New("Modo de feed inválido"))
+	}
+}
+
+func getRankedFeed(w http.ResponseWriter, r *http.Request) {
+	userID, err := authentication.ExtractUserIDFromToken(r)
+	if err != nil {
+		responses.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	_, limit, offset := pagination(r)
+
+	db, err := database.Connect()
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewPublishRepository(db)
+	candidates, err := repo.GetFeedCandidates(userID, feed.Default.Now().Add(-rankedFeedWindow), rankedFeedCandidates)
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	ranked := feed.Default.Rank(candidates)
+
+	publishes := []models.Publish{}
+	for i := offset; i < uint64(len(ranked)) && i < offset+limit; i++ {
+		publishes = append(publishes, ranked[i].Publish)
+	}
+
+	if err = decoratePublishes(db, userID, publishes); err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	responses.JSON(w, http.StatusOK, publishes)
+}
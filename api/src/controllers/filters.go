@@ -0,0 +1,161 @@
+package controllers
+
+import (
+	"api/src/database"
+	"api/src/filter"
+	"api/src/models"
+	"api/src/repository"
+	"api/src/responses"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gorilla/mux"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+func GetContentFilters(w http.ResponseWriter, r *http.Request) {
+	db, err := database.Connect()
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewContentFiltersRepository(db)
+	filters, err := repo.Get()
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	responses.JSON(w, http.StatusOK, filters)
+}
+
+// CreateContentFilter adiciona uma entrada às listas de bloqueio e recarrega o filtro desta instância
+func CreateContentFilter(w http.ResponseWriter, r *http.Request) {
+	requestBody, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var contentFilter models.ContentFilter
+	if err = json.Unmarshal(requestBody, &contentFilter); err != nil {
+		responses.Error(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	if err = contentFilter.Prepare(); err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewContentFiltersRepository(db)
+	contentFilter.ID, err = repo.Create(contentFilter)
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if err = ReloadFilters(db); err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	responses.JSON(w, http.StatusCreated, contentFilter)
+}
+
+func DeleteContentFilter(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	filterID, err := strconv.ParseUint(params["filterId"], 10, 64)
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewContentFiltersRepository(db)
+	if err = repo.Delete(filterID); err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if err = ReloadFilters(db); err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	responses.JSON(w, http.StatusNoContent, nil)
+}
+
+// ReloadContentFilters relê as listas de bloqueio do banco, por exemplo depois
+// de alterações feitas por outra instância da API
+func ReloadContentFilters(w http.ResponseWriter, r *http.Request) {
+	db, err := database.Connect()
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	if err = ReloadFilters(db); err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	responses.JSON(w, http.StatusNoContent, nil)
+}
+
+// ReloadFilters carrega as listas de bloqueio do banco no filtro padrão
+func ReloadFilters(db *sql.DB) error {
+	filters, err := repository.NewContentFiltersRepository(db).Get()
+	if err != nil {
+		return err
+	}
+
+	return filter.Default.Reload(filters)
+}
+
+// flagPublish abre uma denúncia automática para que a publicação seja revisada pelos moderadores
+func flagPublish(db *sql.DB, publish models.Publish, decision filter.Decision) error {
+	repo := repository.NewReportsRepository(db)
+	_, err := repo.Create(models.Report{
+		TargetType:   models.ReportTargetPublish,
+		TargetUserID: publish.AuthorID,
+		PublishID:    publish.ID,
+		Reason:       models.ReportAutomated,
+		Details:      filterDetails(decision),
+	})
+	return err
+}
+
+// rejectPublish registra a regra que rejeitou a publicação e retorna o erro
+// mostrado ao autor, sem a entrada da lista de bloqueio
+func rejectPublish(authorID uint64, decision filter.Decision) error {
+	log.Printf("filtro: publicação do usuário %d rejeitada, %s", authorID, filterDetails(decision))
+	return errors.New(decision.Reason)
+}
+
+func filterDetails(decision filter.Decision) string {
+	if decision.Detail == "" {
+		return fmt.Sprintf("%s: %s", decision.Rule, decision.Reason)
+	}
+	return fmt.Sprintf("%s: %s", decision.Rule, decision.Detail)
+}
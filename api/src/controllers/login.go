@@ -1,15 +1,17 @@
 package controllers
 
 import (
-	"api/src/authentication"
 	"api/src/database"
 	"api/src/models"
 	"api/src/repository"
 	"api/src/responses"
 	"api/src/security"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"io/ioutil"
 	"net/http"
+	"time"
 )
 
 func Login(w http.ResponseWriter, r *http.Request) {
@@ -39,14 +41,62 @@ func Login(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := security.VerifyPassword(storedUser.Password, user.Password); err != nil {
+		if auditErr := auditLoginFailure(db, r, storedUser.ID, user.Email, "senha inválida"); auditErr != nil {
+			responses.Error(w, http.StatusInternalServerError, auditErr)
+			return
+		}
 		responses.Error(w, http.StatusUnauthorized, err)
 		return
 	}
 
-	token, err := authentication.CreateToken(storedUser.ID)
+	completeLogin(w, r, db, storedUser, user.Email, nil)
+}
+
+// completeLogin confere se a conta pode entrar, restaurando contas desativadas
+// ou ainda no período de carência, e responde com o token de uma nova sessão.
+// details é registrado no log de auditoria junto com o login.
+func completeLogin(w http.ResponseWriter, r *http.Request, db *sql.DB, storedUser models.User, email string, details map[string]string) {
+	// Contas excluídas só podem ser restauradas durante o período de carência
+	if storedUser.Status == models.UserDeleted && storedUser.DeleteAfter != nil && !time.Now().Before(*storedUser.DeleteAfter) {
+		if err := auditLoginFailure(db, r, storedUser.ID, email, "conta excluída"); err != nil {
+			responses.Error(w, http.StatusInternalServerError, err)
+			return
+		}
+		responses.Error(w, http.StatusUnauthorized, errors.New("Esta conta foi excluída"))
+		return
+	}
+	if storedUser.Status != models.UserActive {
+		if err := repository.NewUsersRepository(db).Restore(storedUser.ID); err != nil {
+			responses.Error(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	if err := audit(db, r, models.AuditEntry{
+		ActorID:  storedUser.ID,
+		TargetID: storedUser.ID,
+		Action:   models.AuditLoginSucceeded,
+		Details:  details,
+	}); err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	token, err := startSession(db, r, storedUser.ID)
 	if err != nil {
 		responses.Error(w, http.StatusInternalServerError, err)
+		return
 	}
 
 	w.Write([]byte(token))
 }
+
+// auditLoginFailure registra a tentativa de login recusada. userID é 0 quando
+// o e-mail não pertence a nenhuma conta.
+func auditLoginFailure(db *sql.DB, r *http.Request, userID uint64, email, reason string) error {
+	return audit(db, r, models.AuditEntry{
+		TargetID: userID,
+		Action:   models.AuditLoginFailed,
+		Details:  map[string]string{"email": email, "reason": reason},
+	})
+}
@@ -0,0 +1,229 @@
+package controllers
+
+import (
+	"api/src/authentication"
+	"api/src/config"
+	"api/src/database"
+	"api/src/media"
+	"api/src/models"
+	"api/src/repository"
+	"api/src/responses"
+	"api/src/security"
+	"api/src/storage"
+	"database/sql"
+	"errors"
+	"github.com/gorilla/mux"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+)
+
+func UploadMedia(w http.ResponseWriter, r *http.Request) {
+	userID, err := authentication.ExtractUserIDFromToken(r)
+	if err != nil {
+		responses.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	img, err := readImageUpload(w, r)
+	if err != nil {
+		responses.Error(w, uploadErrorStatus(err), err)
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	stored, err := storeImage(db, userID, img)
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	responses.JSON(w, http.StatusCreated, stored)
+}
+
+func UploadAvatar(w http.ResponseWriter, r *http.Request) {
+	userIDInToken, err := authentication.ExtractUserIDFromToken(r)
+	if err != nil {
+		responses.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	params := mux.Vars(r)
+	userID, err := strconv.ParseUint(params["userId"], 10, 64)
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if userID != userIDInToken {
+		responses.Error(w, http.StatusForbidden, errors.New("Não é possível alterar o avatar de um usuário que não seja o seu"))
+		return
+	}
+
+	img, err := readImageUpload(w, r)
+	if err != nil {
+		responses.Error(w, uploadErrorStatus(err), err)
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	stored, err := storeImage(db, userID, img)
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	repo := repository.NewUsersRepository(db)
+	if err = repo.UpdateAvatar(userID, stored.Key); err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	responses.JSON(w, http.StatusOK, stored)
+}
+
+// ServeMedia entrega o arquivo quando a URL assinada é válida e ainda não expirou
+func ServeMedia(w http.ResponseWriter, r *http.Request) {
+	key := mux.Vars(r)["key"]
+	query := r.URL.Query()
+	if !media.VerifySignedURL(key, query.Get("expires"), query.Get("signature")) {
+		responses.Error(w, http.StatusForbidden, errors.New("URL inválida ou expirada"))
+		return
+	}
+
+	file, contentType, err := storage.Store.Get(key)
+	if err == storage.ErrNotFound {
+		responses.Error(w, http.StatusNotFound, err)
+		return
+	}
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer file.Close()
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", "private, max-age="+strconv.Itoa(int(config.MediaURLTTL.Seconds())))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	if contentType == "application/zip" {
+		w.Header().Set("Content-Disposition", "attachment; filename=\""+key+"\"")
+	}
+	w.WriteHeader(http.StatusOK)
+	io.Copy(w, file)
+}
+
+// readImageUpload lê o campo "file" do formulário multipart e processa a imagem
+func readImageUpload(w http.ResponseWriter, r *http.Request) (media.Image, error) {
+	// Margem para os demais campos do formulário
+	r.Body = http.MaxBytesReader(w, r.Body, config.MediaMaxSize+1<<20)
+	if err := r.ParseMultipartForm(config.MediaMaxSize); err != nil {
+		return media.Image{}, media.ErrTooLarge
+	}
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		return media.Image{}, errors.New("Envie a imagem no campo file")
+	}
+	defer file.Close()
+
+	data, err := ioutil.ReadAll(io.LimitReader(file, config.MediaMaxSize+1))
+	if err != nil {
+		return media.Image{}, err
+	}
+
+	return media.Process(data, media.Limits{
+		Size:      config.MediaMaxSize,
+		Dimension: config.MediaMaxDimension,
+		Pixels:    config.MediaMaxPixels,
+	})
+}
+
+func uploadErrorStatus(err error) int {
+	switch err {
+	case media.ErrTooLarge, media.ErrDimensions:
+		return http.StatusRequestEntityTooLarge
+	case media.ErrUnsupported:
+		return http.StatusUnsupportedMediaType
+	}
+	return http.StatusBadRequest
+}
+
+// storeImage grava a imagem e sua miniatura no armazenamento e registra a mídia
+func storeImage(db *sql.DB, userID uint64, img media.Image) (models.Media, error) {
+	token, err := security.RandomToken(16)
+	if err != nil {
+		return models.Media{}, err
+	}
+
+	extension := ".jpg"
+	if img.ContentType == "image/png" {
+		extension = ".png"
+	}
+
+	stored := models.Media{
+		UserID:       userID,
+		Key:          token + extension,
+		ThumbnailKey: token + "_thumb" + extension,
+		ContentType:  img.ContentType,
+		Size:         len(img.Data),
+		Width:        img.Width,
+		Height:       img.Height,
+	}
+
+	if err = storage.Store.Put(stored.Key, img.ContentType, img.Data); err != nil {
+		return models.Media{}, err
+	}
+	if err = storage.Store.Put(stored.ThumbnailKey, img.ContentType, img.Thumbnail); err != nil {
+		return models.Media{}, err
+	}
+
+	repo := repository.NewMediaRepository(db)
+	stored.ID, err = repo.Create(stored)
+	if err != nil {
+		return models.Media{}, err
+	}
+
+	signMedia(&stored)
+	return stored, nil
+}
+
+func signMedia(item *models.Media) {
+	item.URL = media.SignedURL(item.Key)
+	item.ThumbnailURL = media.SignedURL(item.ThumbnailKey)
+}
+
+// loadPublishesMedia preenche as mídias das publicações com URLs assinadas
+func loadPublishesMedia(db *sql.DB, publishes []models.Publish) error {
+	publishIDs := make([]uint64, len(publishes))
+	for i, publish := range publishes {
+		publishIDs[i] = publish.ID
+	}
+
+	repo := repository.NewMediaRepository(db)
+	mediaByPublish, err := repo.GetByPublishes(publishIDs)
+	if err != nil {
+		return err
+	}
+
+	for i := range publishes {
+		publishes[i].Media = mediaByPublish[publishes[i].ID]
+		for j := range publishes[i].Media {
+			signMedia(&publishes[i].Media[j])
+		}
+	}
+
+	return nil
+}
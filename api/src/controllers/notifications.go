@@ -0,0 +1,203 @@
+package controllers
+
+import (
+	"api/src/authentication"
+	"api/src/database"
+	"api/src/events"
+	"api/src/models"
+	"api/src/repository"
+	"api/src/responses"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+)
+
+func GetNotifications(w http.ResponseWriter, r *http.Request) {
+	userID, err := authentication.ExtractUserIDFromToken(r)
+	if err != nil {
+		responses.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	page, limit, offset := pagination(r)
+
+	db, err := database.Connect()
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewNotificationsRepository(db)
+	notifications, err := repo.Get(userID, limit, offset)
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	unread, err := repo.CountUnread(userID)
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	responses.JSON(w, http.StatusOK, models.NotificationPage{
+		Notifications: notifications,
+		Unread:        unread,
+		Page:          page,
+		Limit:         limit,
+	})
+}
+
+func MarkNotificationAsRead(w http.ResponseWriter, r *http.Request) {
+	userID, err := authentication.ExtractUserIDFromToken(r)
+	if err != nil {
+		responses.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	params := mux.Vars(r)
+	notificationID, err := strconv.ParseUint(params["notificationId"], 10, 64)
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewNotificationsRepository(db)
+	if err = repo.MarkAsRead(notificationID, userID); err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	responses.JSON(w, http.StatusNoContent, nil)
+}
+
+func MarkAllNotificationsAsRead(w http.ResponseWriter, r *http.Request) {
+	userID, err := authentication.ExtractUserIDFromToken(r)
+	if err != nil {
+		responses.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewNotificationsRepository(db)
+	if err = repo.MarkAllAsRead(userID); err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	responses.JSON(w, http.StatusNoContent, nil)
+}
+
+func GetNotificationPreferences(w http.ResponseWriter, r *http.Request) {
+	userID, err := authentication.ExtractUserIDFromToken(r)
+	if err != nil {
+		responses.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewNotificationsRepository(db)
+	preferences, err := repo.GetPreferences(userID)
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	responses.JSON(w, http.StatusOK, preferences)
+}
+
+func UpdateNotificationPreferences(w http.ResponseWriter, r *http.Request) {
+	userID, err := authentication.ExtractUserIDFromToken(r)
+	if err != nil {
+		responses.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	requestBody, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var preferences []models.NotificationPreference
+	if err = json.Unmarshal(requestBody, &preferences); err != nil {
+		responses.Error(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	for _, preference := range preferences {
+		if !models.ValidNotificationType(preference.Type) {
+			responses.Error(w, http.StatusBadRequest, errors.New("Tipo de notificação inválido: "+preference.Type))
+			return
+		}
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewNotificationsRepository(db)
+	for _, preference := range preferences {
+		if err = repo.UpdatePreference(userID, preference); err != nil {
+			responses.Error(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	responses.JSON(w, http.StatusNoContent, nil)
+}
+
+// notify grava a notificação e a entrega em tempo real ao destinatário. Nada é
+// enviado quando há bloqueio entre quem age e o destinatário.
+func notify(db *sql.DB, notification models.Notification) error {
+	if notification.ActorID != 0 {
+		blocked, err := repository.NewUsersRepository(db).IsBlocked(notification.ActorID, notification.UserID)
+		if err != nil {
+			return err
+		}
+		if blocked {
+			return nil
+		}
+	}
+
+	repo := repository.NewNotificationsRepository(db)
+	notificationID, err := repo.Create(notification)
+	if err != nil {
+		return err
+	}
+	if notificationID == 0 {
+		return nil
+	}
+
+	notification.ID = notificationID
+	events.Broker.Publish(notification.UserID, events.Event{Type: events.NotificationCreated, Data: notification})
+
+	return nil
+}
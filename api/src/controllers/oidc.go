@@ -0,0 +1,257 @@
+package controllers
+
+import (
+	"api/src/database"
+	"api/src/models"
+	"api/src/oidc"
+	"api/src/repository"
+	"api/src/responses"
+	"api/src/security"
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	// Tempo que o usuário tem para entrar no provedor e voltar
+	oauthStateTTL = 10 * time.Minute
+	// Cookie com o hash do state, conferido no retorno contra login CSRF
+	oauthStateCookie = "oidc_state"
+	// Tentativas de sufixo aleatório quando o nick sugerido já existe
+	nickAttempts = 10
+)
+
+// GetLoginProviders lista os provedores externos disponíveis para login
+func GetLoginProviders(w http.ResponseWriter, r *http.Request) {
+	names := []string{}
+	for name := range oidc.Providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	responses.JSON(w, http.StatusOK, names)
+}
+
+// StartOIDCLogin redireciona o usuário para entrar no provedor, guardando o
+// state, o nonce e o code verifier do PKCE para conferir o retorno
+func StartOIDCLogin(w http.ResponseWriter, r *http.Request) {
+	provider, found := oidc.Providers[mux.Vars(r)["provider"]]
+	if !found {
+		responses.Error(w, http.StatusNotFound, errors.New("Provedor de login não encontrado"))
+		return
+	}
+
+	state := models.OAuthState{Provider: provider.Name(), ExpiresAt: time.Now().Add(oauthStateTTL)}
+	var err error
+	if state.State, err = oidc.RandomString(32); err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	if state.Nonce, err = oidc.RandomString(32); err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	if state.Verifier, err = oidc.RandomString(48); err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	authURL, err := provider.AuthCodeURL(state.State, state.Nonce, state.Verifier)
+	if err != nil {
+		responses.Error(w, http.StatusBadGateway, err)
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewIdentitiesRepository(db)
+	if err = repo.CreateState(state); err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     oauthStateCookie,
+		Value:    oidc.StateHash(state.State),
+		Path:     "/login",
+		MaxAge:   int(oauthStateTTL / time.Second),
+		HttpOnly: true,
+		Secure:   r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https",
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// OIDCCallback recebe o retorno do provedor, troca o código pelo ID token e
+// entra com o usuário ligado à identidade. Sem ligação, a identidade é ligada
+// ao usuário com o mesmo e-mail, se o provedor o verificou, ou um usuário novo
+// é criado. Responde com o token, como o login com senha.
+func OIDCCallback(w http.ResponseWriter, r *http.Request) {
+	provider, found := oidc.Providers[mux.Vars(r)["provider"]]
+	if !found {
+		responses.Error(w, http.StatusNotFound, errors.New("Provedor de login não encontrado"))
+		return
+	}
+
+	query := r.URL.Query()
+	if providerError := query.Get("error"); providerError != "" {
+		responses.Error(w, http.StatusUnauthorized, fmt.Errorf("Login recusado pelo provedor: %s", providerError))
+		return
+	}
+
+	// O state precisa ter sido criado neste navegador; sem isso, alguém poderia
+	// completar o próprio login no navegador de outra pessoa
+	cookie, err := r.Cookie(oauthStateCookie)
+	if err != nil || subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(oidc.StateHash(query.Get("state")))) != 1 {
+		responses.Error(w, http.StatusBadRequest, errors.New("State inválido ou expirado, tente entrar novamente"))
+		return
+	}
+	http.SetCookie(w, &http.Cookie{Name: oauthStateCookie, Path: "/login", MaxAge: -1, HttpOnly: true})
+
+	db, err := database.Connect()
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	identities := repository.NewIdentitiesRepository(db)
+	state, valid, err := identities.ConsumeState(query.Get("state"), provider.Name(), time.Now())
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	if !valid {
+		responses.Error(w, http.StatusBadRequest, errors.New("State inválido ou expirado, tente entrar novamente"))
+		return
+	}
+
+	claims, err := provider.Exchange(query.Get("code"), state.Verifier, state.Nonce)
+	if err != nil {
+		responses.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	userID, status, err := resolveIdentity(db, provider.Name(), claims)
+	if err != nil {
+		if status == http.StatusConflict {
+			if auditErr := auditLoginFailure(db, r, userID, claims.Email, "e-mail não verificado pelo provedor"); auditErr != nil {
+				responses.Error(w, http.StatusInternalServerError, auditErr)
+				return
+			}
+		}
+		responses.Error(w, status, err)
+		return
+	}
+
+	storedUser, err := repository.NewUsersRepository(db).GetForLogin(userID)
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	if storedUser.ID == 0 {
+		responses.Error(w, http.StatusUnauthorized, errors.New("Usuário não encontrado"))
+		return
+	}
+
+	completeLogin(w, r, db, storedUser, claims.Email, map[string]string{"provider": provider.Name()})
+}
+
+// resolveIdentity retorna o usuário da identidade externa, ligando-a ou criando
+// o usuário quando necessário. Em caso de erro, retorna também o status HTTP.
+func resolveIdentity(db *sql.DB, provider string, claims oidc.Claims) (uint64, int, error) {
+	identities := repository.NewIdentitiesRepository(db)
+	userID, err := identities.GetUserID(provider, claims.Subject)
+	if err != nil {
+		return 0, http.StatusInternalServerError, err
+	}
+	if userID != 0 {
+		return userID, 0, nil
+	}
+
+	if claims.Email == "" {
+		return 0, http.StatusBadRequest, errors.New("O provedor não informou o e-mail")
+	}
+	identity := models.Identity{Provider: provider, Subject: claims.Subject, Email: claims.Email}
+
+	users := repository.NewUsersRepository(db)
+	existing, err := users.GetByEmail(claims.Email)
+	if err != nil {
+		return 0, http.StatusInternalServerError, err
+	}
+	if existing.ID != 0 {
+		// Sem verificação, qualquer um poderia ligar uma conta externa à conta de outra pessoa
+		if !claims.EmailVerified {
+			return existing.ID, http.StatusConflict, errors.New("Já existe uma conta com este e-mail, mas o provedor não o verificou")
+		}
+
+		identity.UserID = existing.ID
+		if err = identities.Link(identity); err != nil {
+			return 0, http.StatusInternalServerError, err
+		}
+		return existing.ID, 0, nil
+	}
+
+	user, err := newOIDCUser(users, claims)
+	if err != nil {
+		return 0, http.StatusBadRequest, err
+	}
+	if userID, err = identities.CreateUser(user, identity); err != nil {
+		return 0, http.StatusInternalServerError, err
+	}
+	return userID, 0, nil
+}
+
+// newOIDCUser prepara o usuário de uma identidade externa, com um nick livre
+// gerado a partir do provedor e uma senha aleatória que ninguém conhece
+func newOIDCUser(users *repository.Users, claims oidc.Claims) (models.User, error) {
+	password, err := security.RandomToken(32)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	base := models.NickBase(claims.PreferredUsername, claims.Email)
+	nick := base
+	for attempt := 0; ; attempt++ {
+		taken, err := users.NickTaken(nick)
+		if err != nil {
+			return models.User{}, err
+		}
+		if !taken {
+			break
+		}
+		if attempt == nickAttempts {
+			return models.User{}, errors.New("Não foi possível gerar um nick livre")
+		}
+		suffix, err := security.RandomToken(2)
+		if err != nil {
+			return models.User{}, err
+		}
+		nick = base + "_" + suffix
+	}
+
+	name := claims.Name
+	if name == "" {
+		name = nick
+	}
+	if len([]rune(name)) > 50 {
+		name = string([]rune(name)[:50])
+	}
+
+	user := models.User{Name: name, Nick: nick, Email: claims.Email, Password: password}
+	if err = user.Prepare("register"); err != nil {
+		return models.User{}, err
+	}
+	return user, nil
+}
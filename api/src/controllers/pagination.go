@@ -0,0 +1,29 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+)
+
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+// pagination lê os parâmetros page e limit da query, aplicando valores padrão
+func pagination(r *http.Request) (page, limit, offset uint64) {
+	page, err := strconv.ParseUint(r.URL.Query().Get("page"), 10, 64)
+	if err != nil || page == 0 {
+		page = 1
+	}
+
+	limit, err = strconv.ParseUint(r.URL.Query().Get("limit"), 10, 64)
+	if err != nil || limit == 0 {
+		limit = defaultPageLimit
+	}
+	if limit > maxPageLimit {
+		limit = maxPageLimit
+	}
+
+	return page, limit, (page - 1) * limit
+}
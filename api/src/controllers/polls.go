@@ -0,0 +1,136 @@
+package controllers
+
+import (
+	"api/src/authentication"
+	"api/src/database"
+	"api/src/models"
+	"api/src/repository"
+	"api/src/responses"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// VotePoll registra o voto do usuário na enquete da publicação e retorna os resultados
+func VotePoll(w http.ResponseWriter, r *http.Request) {
+	userID, err := authentication.ExtractUserIDFromToken(r)
+	if err != nil {
+		responses.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	params := mux.Vars(r)
+	publishID, err := strconv.ParseUint(params["publishId"], 10, 64)
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	requestBody, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var vote models.PollVote
+	if err = json.Unmarshal(requestBody, &vote); err != nil {
+		responses.Error(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	publishesRepo := repository.NewPublishRepository(db)
+	publish, err := publishesRepo.GetPublish(publishID)
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	visible, err := visibleAuthors(db, userID, []uint64{publish.AuthorID})
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	if publish.ID == 0 || !publish.IsPublished() || !visible[publish.AuthorID] {
+		responses.Error(w, http.StatusNotFound, errors.New("Publicação não encontrada"))
+		return
+	}
+
+	repo := repository.NewPollsRepository(db)
+	polls, err := repo.GetByPublishes([]uint64{publishID}, userID)
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	poll, ok := polls[publishID]
+	if !ok {
+		responses.Error(w, http.StatusNotFound, errors.New("A publicação não tem enquete"))
+		return
+	}
+	if !time.Now().Before(poll.ExpiresAt) {
+		responses.Error(w, http.StatusConflict, errors.New("A enquete já foi encerrada"))
+		return
+	}
+	if err = poll.ValidateVote(vote); err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	voted, err := repo.Vote(poll.ID, userID, vote.Choices)
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	if !voted {
+		responses.Error(w, http.StatusConflict, errors.New("Você já votou nesta enquete"))
+		return
+	}
+
+	polls, err = repo.GetByPublishes([]uint64{publishID}, userID)
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	poll = polls[publishID]
+	poll.Tally(time.Now())
+	responses.JSON(w, http.StatusOK, poll)
+}
+
+// loadPolls preenche as enquetes das publicações com os resultados visíveis para viewerID
+func loadPolls(db *sql.DB, viewerID uint64, publishes []models.Publish) error {
+	publishIDs := make([]uint64, len(publishes))
+	for i, publish := range publishes {
+		publishIDs[i] = publish.ID
+	}
+
+	repo := repository.NewPollsRepository(db)
+	polls, err := repo.GetByPublishes(publishIDs, viewerID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for i := range publishes {
+		poll, ok := polls[publishes[i].ID]
+		if !ok {
+			continue
+		}
+		poll.Tally(now)
+		publishes[i].Poll = &poll
+	}
+
+	return nil
+}
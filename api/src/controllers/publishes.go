@@ -3,15 +3,20 @@ package controllers
 import (
 	"api/src/authentication"
 	"api/src/database"
+	"api/src/events"
+	"api/src/filter"
 	"api/src/models"
 	"api/src/repository"
 	"api/src/responses"
+	"api/src/search"
+	"database/sql"
 	"encoding/json"
 	"errors"
 	"github.com/gorilla/mux"
 	"io/ioutil"
 	"net/http"
 	"strconv"
+	"time"
 )
 
 func CreatePublish(w http.ResponseWriter, r *http.Request) {
@@ -40,6 +45,20 @@ func CreatePublish(w http.ResponseWriter, r *http.Request) {
 
 	publish.AuthorID = userID
 
+	decision, err := filter.Default.Check(filter.Input{
+		AuthorID: userID,
+		Title:    publish.Title,
+		Content:  publish.Content,
+	})
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	if decision.Action == models.FilterReject {
+		responses.Error(w, http.StatusUnprocessableEntity, rejectPublish(userID, decision))
+		return
+	}
+
 	db, err := database.Connect()
 	if err != nil {
 		responses.Error(w, http.StatusInternalServerError, err)
@@ -47,12 +66,65 @@ func CreatePublish(w http.ResponseWriter, r *http.Request) {
 	}
 
 	repo := repository.NewPublishRepository(db)
+	if publish.ParentID != 0 {
+		parent, err := repo.GetPublish(publish.ParentID)
+		if err != nil {
+			responses.Error(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		visible, err := visibleAuthors(db, userID, []uint64{parent.AuthorID})
+		if err != nil {
+			responses.Error(w, http.StatusInternalServerError, err)
+			return
+		}
+		if parent.ID == 0 || !parent.IsPublished() || !visible[parent.AuthorID] {
+			responses.Error(w, http.StatusNotFound, errors.New("Publicação respondida não encontrada"))
+			return
+		}
+	}
+
+	mediaRepo := repository.NewMediaRepository(db)
+	owned, err := mediaRepo.Owned(userID, publish.MediaIDs)
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	if !owned {
+		responses.Error(w, http.StatusBadRequest, errors.New("Mídia não encontrada"))
+		return
+	}
+
 	publish.ID, err = repo.Create(publish)
 	if err != nil {
 		responses.Error(w, http.StatusInternalServerError, err)
 		return
 	}
 
+	if decision.Action == models.FilterFlag {
+		if err = flagPublish(db, publish, decision); err != nil {
+			responses.Error(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	if publish.Poll != nil {
+		publishes := []models.Publish{publish}
+		if err = loadPolls(db, userID, publishes); err != nil {
+			responses.Error(w, http.StatusInternalServerError, err)
+			return
+		}
+		publish = publishes[0]
+	}
+
+	publish.CreatedAt = time.Now()
+	if publish.IsPublished() {
+		if err = AnnouncePublish(db, publish); err != nil {
+			responses.Error(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
 	responses.JSON(w, http.StatusCreated, publish)
 }
 
@@ -75,10 +147,21 @@ func GetPublishes(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err = decoratePublishes(db, userID, publishes); err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
 	responses.JSON(w, http.StatusOK, publishes)
 }
 
 func GetPublish(w http.ResponseWriter, r *http.Request) {
+	viewerID, err := authentication.ExtractUserIDFromToken(r)
+	if err != nil {
+		responses.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
 	params := mux.Vars(r)
 	publishId, err := strconv.ParseUint(params["publishId"], 10, 64)
 	if err != nil {
@@ -99,7 +182,39 @@ func GetPublish(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	responses.JSON(w, http.StatusOK, publish)
+	if publish.ID == 0 || publish.Status == models.PublishDeleted || (!publish.IsPublished() && publish.AuthorID != viewerID) {
+		responses.Error(w, http.StatusNotFound, errors.New("Publicação não encontrada"))
+		return
+	}
+
+	usersRepo := repository.NewUsersRepository(db)
+	blocked, err := usersRepo.IsBlocked(viewerID, publish.AuthorID)
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	if blocked {
+		responses.Error(w, http.StatusNotFound, errors.New("Publicação não encontrada"))
+		return
+	}
+
+	allowed, err := usersRepo.CanViewContent(viewerID, publish.AuthorID)
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	if !allowed {
+		responses.Error(w, http.StatusForbidden, errors.New("Esta conta é privada"))
+		return
+	}
+
+	publishes := []models.Publish{publish}
+	if err = decoratePublishes(db, viewerID, publishes); err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	responses.JSON(w, http.StatusOK, publishes[0])
 }
 
 func UpdatePublish(w http.ResponseWriter, r *http.Request) {
@@ -129,6 +244,11 @@ func UpdatePublish(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if storedPublish.ID == 0 || storedPublish.Status == models.PublishDeleted {
+		responses.Error(w, http.StatusNotFound, errors.New("Publicação não encontrada"))
+		return
+	}
+
 	if storedPublish.AuthorID != userID {
 		responses.Error(w, http.StatusForbidden, errors.New("Não é possivel alterar uma publicação que não seja a tua."))
 		return
@@ -153,12 +273,43 @@ func UpdatePublish(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	decision, err := filter.Default.Check(filter.Input{
+		AuthorID:  userID,
+		PublishID: publishId,
+		Title:     publish.Title,
+		Content:   publish.Content,
+	})
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	if decision.Action == models.FilterReject {
+		responses.Error(w, http.StatusUnprocessableEntity, rejectPublish(userID, decision))
+		return
+	}
+
+	publish.ID = publishId
+	publish.AuthorID = userID
 	err = repo.Update(publish, userID)
 	if err != nil {
 		responses.Error(w, http.StatusInternalServerError, err)
 		return
 	}
 
+	if decision.Action == models.FilterFlag {
+		if err = flagPublish(db, publish, decision); err != nil {
+			responses.Error(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+	publish.CreatedAt = storedPublish.CreatedAt
+	if storedPublish.IsPublished() {
+		if err = search.Engine.Index(publish); err != nil {
+			responses.Error(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
 	responses.JSON(w, http.StatusNoContent, nil)
 }
 
@@ -198,10 +349,21 @@ func DeletePublish(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err = search.Engine.Remove(publishID); err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
 	responses.JSON(w, http.StatusOK, nil)
 }
 
 func GetPublishesByUser(w http.ResponseWriter, r *http.Request) {
+	viewerID, err := authentication.ExtractUserIDFromToken(r)
+	if err != nil {
+		responses.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
 	params := mux.Vars(r)
 	userID, err := strconv.ParseUint(params["userId"], 10, 64)
 	if err != nil {
@@ -216,6 +378,27 @@ func GetPublishesByUser(w http.ResponseWriter, r *http.Request) {
 	}
 	defer db.Close()
 
+	usersRepo := repository.NewUsersRepository(db)
+	blocked, err := usersRepo.IsBlocked(viewerID, userID)
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	if blocked {
+		responses.Error(w, http.StatusNotFound, errors.New("Usuário não encontrado"))
+		return
+	}
+
+	allowed, err := usersRepo.CanViewContent(viewerID, userID)
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	if !allowed {
+		responses.Error(w, http.StatusForbidden, errors.New("Esta conta é privada"))
+		return
+	}
+
 	repo := repository.NewPublishRepository(db)
 	publishes, err := repo.GetPublishesByUser(userID)
 	if err != nil {
@@ -223,6 +406,363 @@ func GetPublishesByUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err = decoratePublishes(db, viewerID, publishes); err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
 	responses.JSON(w, http.StatusOK, publishes)
 	return
 }
+
+func LikePublish(w http.ResponseWriter, r *http.Request) {
+	userID, err := authentication.ExtractUserIDFromToken(r)
+	if err != nil {
+		responses.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	params := mux.Vars(r)
+	publishID, err := strconv.ParseUint(params["publishId"], 10, 64)
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewPublishRepository(db)
+	storedPublish, err := repo.GetPublish(publishID)
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	visible, err := visibleAuthors(db, userID, []uint64{storedPublish.AuthorID})
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	if storedPublish.ID == 0 || !storedPublish.IsPublished() || !visible[storedPublish.AuthorID] {
+		responses.Error(w, http.StatusNotFound, errors.New("Publicação não encontrada"))
+		return
+	}
+
+	liked, err := repo.Like(publishID, userID)
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if liked {
+		if err = notify(db, models.Notification{
+			UserID:    storedPublish.AuthorID,
+			ActorID:   userID,
+			Type:      models.NotificationLike,
+			PublishID: publishID,
+		}); err != nil {
+			responses.Error(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	responses.JSON(w, http.StatusNoContent, nil)
+}
+
+func UnlikePublish(w http.ResponseWriter, r *http.Request) {
+	userID, err := authentication.ExtractUserIDFromToken(r)
+	if err != nil {
+		responses.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	params := mux.Vars(r)
+	publishID, err := strconv.ParseUint(params["publishId"], 10, 64)
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewPublishRepository(db)
+	if err = repo.Unlike(publishID, userID); err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	responses.JSON(w, http.StatusNoContent, nil)
+}
+
+// GetDrafts lista os rascunhos e as publicações agendadas do usuário logado
+func GetDrafts(w http.ResponseWriter, r *http.Request) {
+	userID, err := authentication.ExtractUserIDFromToken(r)
+	if err != nil {
+		responses.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewPublishRepository(db)
+	publishes, err := repo.GetDrafts(userID)
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if err = decoratePublishes(db, userID, publishes); err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	responses.JSON(w, http.StatusOK, publishes)
+}
+
+// SchedulePublish agenda um rascunho para o publish_at informado
+func SchedulePublish(w http.ResponseWriter, r *http.Request) {
+	userID, err := authentication.ExtractUserIDFromToken(r)
+	if err != nil {
+		responses.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	params := mux.Vars(r)
+	publishID, err := strconv.ParseUint(params["publishId"], 10, 64)
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	requestBody, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var publish models.Publish
+	if err = json.Unmarshal(requestBody, &publish); err != nil {
+		responses.Error(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	if err = models.ValidatePublishAt(publish.PublishAt); err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewPublishRepository(db)
+	storedPublish, err := repo.GetPublish(publishID)
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if storedPublish.AuthorID != userID {
+		responses.Error(w, http.StatusForbidden, errors.New("Não é possível agendar uma publicação que não seja a sua"))
+		return
+	}
+	if !storedPublish.IsDraft() {
+		responses.Error(w, http.StatusConflict, errors.New("A publicação já foi publicada"))
+		return
+	}
+
+	if err = repo.Schedule(publishID, *publish.PublishAt); err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	responses.JSON(w, http.StatusNoContent, nil)
+}
+
+// PublishDraft publica imediatamente um rascunho ou uma publicação agendada
+func PublishDraft(w http.ResponseWriter, r *http.Request) {
+	userID, err := authentication.ExtractUserIDFromToken(r)
+	if err != nil {
+		responses.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	params := mux.Vars(r)
+	publishID, err := strconv.ParseUint(params["publishId"], 10, 64)
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewPublishRepository(db)
+	storedPublish, err := repo.GetPublish(publishID)
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if storedPublish.AuthorID != userID {
+		responses.Error(w, http.StatusForbidden, errors.New("Não é possível publicar uma publicação que não seja a sua"))
+		return
+	}
+
+	publish, published, err := repo.Publish(publishID, time.Now())
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	if !published {
+		responses.Error(w, http.StatusConflict, errors.New("A publicação já foi publicada"))
+		return
+	}
+
+	if err = AnnouncePublish(db, publish); err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	responses.JSON(w, http.StatusOK, publish)
+}
+
+// AnnouncePublish indexa a publicação recém publicada e avisa os mencionados,
+// o autor da publicação respondida e os seguidores conectados. Também é usada
+// pelo scheduler nas agendadas.
+func AnnouncePublish(db *sql.DB, publish models.Publish) error {
+	if err := search.Engine.Index(publish); err != nil {
+		return err
+	}
+
+	if err := notifyMentions(db, publish); err != nil {
+		return err
+	}
+
+	if publish.ParentID != 0 {
+		parent, err := repository.NewPublishRepository(db).GetPublish(publish.ParentID)
+		if err != nil {
+			return err
+		}
+		if parent.IsPublished() {
+			if err = notify(db, models.Notification{
+				UserID:    parent.AuthorID,
+				ActorID:   publish.AuthorID,
+				Type:      models.NotificationComment,
+				PublishID: publish.ID,
+			}); err != nil {
+				return err
+			}
+		}
+	}
+
+	return streamPublish(db, publish)
+}
+
+// notifyMentions avisa os usuários mencionados com @nick no conteúdo da publicação
+func notifyMentions(db *sql.DB, publish models.Publish) error {
+	usersRepo := repository.NewUsersRepository(db)
+
+	for _, nick := range publish.Mentions() {
+		user, err := usersRepo.GetByNick(nick)
+		if err != nil {
+			return err
+		}
+		if user.ID == 0 {
+			continue
+		}
+
+		if err = notify(db, models.Notification{
+			UserID:    user.ID,
+			ActorID:   publish.AuthorID,
+			Type:      models.NotificationMention,
+			PublishID: publish.ID,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// streamPublish envia a nova publicação para o autor e seus seguidores
+// conectados, exceto os que silenciaram ou têm bloqueio com o autor
+func streamPublish(db *sql.DB, publish models.Publish) error {
+	usersRepo := repository.NewUsersRepository(db)
+	audience, err := usersRepo.GetAudience(publish.AuthorID)
+	if err != nil {
+		return err
+	}
+
+	event := events.Event{Type: events.PublishCreated, Data: publish}
+	events.Broker.Publish(publish.AuthorID, event)
+	for _, followerID := range audience {
+		events.Broker.Publish(followerID, event)
+	}
+
+	return nil
+}
+
+// decoratePublishes completa as publicações com mídias, com o estado relativo a
+// quem consulta e com a publicação respondida, quando forem respostas
+func decoratePublishes(db *sql.DB, viewerID uint64, publishes []models.Publish) error {
+	if err := decorateThreadPublishes(db, viewerID, publishes); err != nil {
+		return err
+	}
+
+	return loadParents(db, viewerID, publishes)
+}
+
+// decorateThreadPublishes faz o mesmo que decoratePublishes, exceto carregar a
+// publicação respondida, que numa thread já aparece acima da resposta
+func decorateThreadPublishes(db *sql.DB, viewerID uint64, publishes []models.Publish) error {
+	if len(publishes) == 0 {
+		return nil
+	}
+
+	if err := loadPublishesMedia(db, publishes); err != nil {
+		return err
+	}
+
+	if err := loadPolls(db, viewerID, publishes); err != nil {
+		return err
+	}
+
+	publishIDs := make([]uint64, len(publishes))
+	for i, publish := range publishes {
+		publishIDs[i] = publish.ID
+	}
+
+	bookmarksRepo := repository.NewBookmarksRepository(db)
+	bookmarked, err := bookmarksRepo.Bookmarked(viewerID, publishIDs)
+	if err != nil {
+		return err
+	}
+
+	for i := range publishes {
+		publishes[i].Bookmarked = bookmarked[publishes[i].ID]
+	}
+
+	return nil
+}
@@ -0,0 +1,164 @@
+package controllers
+
+import (
+	"api/src/authentication"
+	"api/src/database"
+	"api/src/repository"
+	"api/src/responses"
+	"errors"
+	"github.com/gorilla/mux"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Quantidade máxima de IDs aceitos na consulta de relações em lote
+const maxRelationshipIDs = 100
+
+// GetRelationship retorna a relação do usuário com o ID informado em with
+func GetRelationship(w http.ResponseWriter, r *http.Request) {
+	userID, ok := relationshipOwner(w, r)
+	if !ok {
+		return
+	}
+
+	targetID, err := strconv.ParseUint(r.URL.Query().Get("with"), 10, 64)
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, errors.New("Informe o ID do outro usuário em with"))
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewUsersRepository(db)
+	relationships, err := repo.GetRelationships(userID, []uint64{targetID})
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	responses.JSON(w, http.StatusOK, relationships[0])
+}
+
+// GetRelationships retorna a relação do usuário com até 100 IDs separados por vírgula em ids
+func GetRelationships(w http.ResponseWriter, r *http.Request) {
+	userID, ok := relationshipOwner(w, r)
+	if !ok {
+		return
+	}
+
+	var targetIDs []uint64
+	seen := map[uint64]bool{}
+	for _, rawID := range strings.Split(r.URL.Query().Get("ids"), ",") {
+		rawID = strings.TrimSpace(rawID)
+		if rawID == "" {
+			continue
+		}
+		targetID, err := strconv.ParseUint(rawID, 10, 64)
+		if err != nil {
+			responses.Error(w, http.StatusBadRequest, errors.New("ID inválido: "+rawID))
+			return
+		}
+		if !seen[targetID] {
+			seen[targetID] = true
+			targetIDs = append(targetIDs, targetID)
+		}
+	}
+
+	if len(targetIDs) == 0 {
+		responses.Error(w, http.StatusBadRequest, errors.New("Informe ao menos um ID em ids"))
+		return
+	}
+	if len(targetIDs) > maxRelationshipIDs {
+		responses.Error(w, http.StatusBadRequest, errors.New("Informe no máximo 100 IDs"))
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewUsersRepository(db)
+	relationships, err := repo.GetRelationships(userID, targetIDs)
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	responses.JSON(w, http.StatusOK, relationships)
+}
+
+func GetMutuals(w http.ResponseWriter, r *http.Request) {
+	viewerID, err := authentication.ExtractUserIDFromToken(r)
+	if err != nil {
+		responses.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	params := mux.Vars(r)
+	userID, err := strconv.ParseUint(params["userId"], 10, 64)
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	_, limit, offset := pagination(r)
+
+	db, err := database.Connect()
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewUsersRepository(db)
+	allowed, err := repo.CanViewContent(viewerID, userID)
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	if !allowed {
+		responses.Error(w, http.StatusForbidden, errors.New("Esta conta é privada"))
+		return
+	}
+
+	mutuals, err := repo.GetMutuals(userID, limit, offset)
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	responses.JSON(w, http.StatusOK, mutuals)
+}
+
+// relationshipOwner garante que as relações consultadas sejam as do próprio usuário,
+// já que incluem bloqueios e silenciamentos
+func relationshipOwner(w http.ResponseWriter, r *http.Request) (uint64, bool) {
+	userIDInToken, err := authentication.ExtractUserIDFromToken(r)
+	if err != nil {
+		responses.Error(w, http.StatusUnauthorized, err)
+		return 0, false
+	}
+
+	params := mux.Vars(r)
+	userID, err := strconv.ParseUint(params["userId"], 10, 64)
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return 0, false
+	}
+
+	if userID != userIDInToken {
+		responses.Error(w, http.StatusForbidden, errors.New("Não é possível consultar relações de um usuário que não seja o seu"))
+		return 0, false
+	}
+
+	return userID, true
+}
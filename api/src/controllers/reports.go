@@ -0,0 +1,349 @@
+package controllers
+
+import (
+	"api/src/authentication"
+	"api/src/database"
+	"api/src/models"
+	"api/src/repository"
+	"api/src/responses"
+	"api/src/search"
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+)
+
+// ReportPublish denuncia uma publicação para a fila de moderação
+func ReportPublish(w http.ResponseWriter, r *http.Request) {
+	userID, err := authentication.ExtractUserIDFromToken(r)
+	if err != nil {
+		responses.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	params := mux.Vars(r)
+	publishID, err := strconv.ParseUint(params["publishId"], 10, 64)
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	report, err := readReport(r)
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	publish, err := repository.NewPublishRepository(db).GetPublish(publishID)
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	visible, err := visibleAuthors(db, userID, []uint64{publish.AuthorID})
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	if publish.ID == 0 || !publish.IsPublished() || !visible[publish.AuthorID] {
+		responses.Error(w, http.StatusNotFound, errors.New("Publicação não encontrada"))
+		return
+	}
+	if publish.AuthorID == userID {
+		responses.Error(w, http.StatusBadRequest, errors.New("Não é possível denunciar a própria publicação"))
+		return
+	}
+
+	report.ReporterID = userID
+	report.TargetType = models.ReportTargetPublish
+	report.TargetUserID = publish.AuthorID
+	report.PublishID = publishID
+
+	repo := repository.NewReportsRepository(db)
+	report.ID, err = repo.Create(report)
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	responses.JSON(w, http.StatusCreated, report)
+}
+
+// ReportUser denuncia um usuário para a fila de moderação
+func ReportUser(w http.ResponseWriter, r *http.Request) {
+	userID, err := authentication.ExtractUserIDFromToken(r)
+	if err != nil {
+		responses.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	params := mux.Vars(r)
+	reportedID, err := strconv.ParseUint(params["userId"], 10, 64)
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if reportedID == userID {
+		responses.Error(w, http.StatusBadRequest, errors.New("Não é possível denunciar a si mesmo"))
+		return
+	}
+
+	report, err := readReport(r)
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	reported, err := repository.NewUsersRepository(db).GetByID(reportedID)
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	if reported.ID == 0 {
+		responses.Error(w, http.StatusNotFound, errors.New("Usuário não encontrado"))
+		return
+	}
+
+	report.ReporterID = userID
+	report.TargetType = models.ReportTargetUser
+	report.TargetUserID = reportedID
+
+	repo := repository.NewReportsRepository(db)
+	report.ID, err = repo.Create(report)
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	responses.JSON(w, http.StatusCreated, report)
+}
+
+// GetReports lista a fila de moderação, filtrando pelo estado em status (open por padrão)
+func GetReports(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	if status == "" {
+		status = models.ReportOpen
+	}
+
+	_, limit, offset := pagination(r)
+
+	db, err := database.Connect()
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewReportsRepository(db)
+	reports, err := repo.Get(status, limit, offset)
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	responses.JSON(w, http.StatusOK, reports)
+}
+
+func GetReport(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	reportID, err := strconv.ParseUint(params["reportId"], 10, 64)
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewReportsRepository(db)
+	report, err := repo.GetByID(reportID)
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	if report.ID == 0 {
+		responses.Error(w, http.StatusNotFound, errors.New("Denúncia não encontrada"))
+		return
+	}
+
+	responses.JSON(w, http.StatusOK, report)
+}
+
+// ClaimReport atribui a denúncia ao moderador, evitando que outro a analise ao mesmo tempo
+func ClaimReport(w http.ResponseWriter, r *http.Request) {
+	moderate(w, r, models.ModerationClaim)
+}
+
+// ResolveReport encerra a denúncia como procedente
+func ResolveReport(w http.ResponseWriter, r *http.Request) {
+	moderate(w, r, models.ModerationResolve)
+}
+
+// DismissReport encerra a denúncia como improcedente
+func DismissReport(w http.ResponseWriter, r *http.Request) {
+	moderate(w, r, models.ModerationDismiss)
+}
+
+// RemoveReportedContent apaga a publicação denunciada e encerra a denúncia como procedente
+func RemoveReportedContent(w http.ResponseWriter, r *http.Request) {
+	moderate(w, r, models.ModerationRemoveContent)
+}
+
+// moderate aplica a ação do moderador na denúncia e avisa quem denunciou quando ela é encerrada
+func moderate(w http.ResponseWriter, r *http.Request, actionType string) {
+	moderatorID, err := authentication.ExtractUserIDFromToken(r)
+	if err != nil {
+		responses.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	params := mux.Vars(r)
+	reportID, err := strconv.ParseUint(params["reportId"], 10, 64)
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var action models.ModerationAction
+	requestBody, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+	if len(requestBody) > 0 {
+		if err = json.Unmarshal(requestBody, &action); err != nil {
+			responses.Error(w, http.StatusUnprocessableEntity, err)
+			return
+		}
+	}
+
+	action.ReportID = reportID
+	action.ModeratorID = moderatorID
+	action.Action = actionType
+	if err = action.Prepare(); err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewReportsRepository(db)
+	report, err := repo.GetByID(reportID)
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	if report.ID == 0 {
+		responses.Error(w, http.StatusNotFound, errors.New("Denúncia não encontrada"))
+		return
+	}
+	if actionType == models.ModerationRemoveContent && report.TargetType != models.ReportTargetPublish {
+		responses.Error(w, http.StatusBadRequest, errors.New("Apenas denúncias de publicações têm conteúdo a remover"))
+		return
+	}
+
+	applied, others, err := repo.Act(reportID, action)
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	if !applied {
+		responses.Error(w, http.StatusConflict, errors.New("A denúncia já foi encerrada ou está com outro moderador"))
+		return
+	}
+
+	if err = audit(db, r, models.AuditEntry{
+		ActorID:  moderatorID,
+		TargetID: report.TargetUserID,
+		Action:   models.AuditModeration,
+		Details: map[string]string{
+			"report_id": strconv.FormatUint(reportID, 10),
+			"action":    actionType,
+			"reason":    action.Reason,
+		},
+	}); err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if actionType == models.ModerationRemoveContent && report.PublishID != 0 {
+		if err = search.Engine.Remove(report.PublishID); err != nil {
+			responses.Error(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	if actionType != models.ModerationClaim {
+		notificationType := models.NotificationReportActioned
+		if actionType == models.ModerationDismiss {
+			notificationType = models.NotificationReportDismissed
+		}
+
+		// Sem ActorID, para que o denunciante não saiba qual moderador decidiu
+		for _, closed := range append([]models.Report{report}, others...) {
+			if closed.ReporterID == 0 {
+				continue
+			}
+			if err = notify(db, models.Notification{
+				UserID:   closed.ReporterID,
+				Type:     notificationType,
+				ReportID: closed.ID,
+			}); err != nil {
+				responses.Error(w, http.StatusInternalServerError, err)
+				return
+			}
+		}
+	}
+
+	report, err = repo.GetByID(reportID)
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	responses.JSON(w, http.StatusOK, report)
+}
+
+func readReport(r *http.Request) (models.Report, error) {
+	requestBody, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return models.Report{}, err
+	}
+
+	var report models.Report
+	if err = json.Unmarshal(requestBody, &report); err != nil {
+		return models.Report{}, err
+	}
+
+	if err = report.Prepare(); err != nil {
+		return models.Report{}, err
+	}
+
+	return report, nil
+}
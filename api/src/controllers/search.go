@@ -0,0 +1,106 @@
+package controllers
+
+import (
+	"api/src/authentication"
+	"api/src/database"
+	"api/src/models"
+	"api/src/repository"
+	"api/src/responses"
+	"api/src/search"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const searchDateLayout = "2006-01-02"
+
+// SearchPublishes busca publicações por título e conteúdo, ordenadas por relevância.
+// Aceita os filtros author (nick), from e to (AAAA-MM-DD).
+func SearchPublishes(w http.ResponseWriter, r *http.Request) {
+	viewerID, err := authentication.ExtractUserIDFromToken(r)
+	if err != nil {
+		responses.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	params := r.URL.Query()
+	query := search.Query{Text: strings.TrimSpace(params.Get("q"))}
+	if query.Text == "" {
+		responses.Error(w, http.StatusBadRequest, errors.New("Informe o termo da busca"))
+		return
+	}
+
+	if from := params.Get("from"); from != "" {
+		if query.From, err = time.ParseInLocation(searchDateLayout, from, time.Local); err != nil {
+			responses.Error(w, http.StatusBadRequest, errors.New("Data inicial inválida"))
+			return
+		}
+	}
+	if to := params.Get("to"); to != "" {
+		day, err := time.ParseInLocation(searchDateLayout, to, time.Local)
+		if err != nil {
+			responses.Error(w, http.StatusBadRequest, errors.New("Data final inválida"))
+			return
+		}
+		query.To = day.Add(24*time.Hour - time.Nanosecond)
+	}
+
+	_, query.Limit, query.Offset = pagination(r)
+
+	db, err := database.Connect()
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	usersRepo := repository.NewUsersRepository(db)
+	if nick := params.Get("author"); nick != "" {
+		author, err := usersRepo.GetByNick(strings.TrimPrefix(nick, "@"))
+		if err != nil {
+			responses.Error(w, http.StatusInternalServerError, err)
+			return
+		}
+		if author.ID == 0 {
+			responses.JSON(w, http.StatusOK, []models.Publish{})
+			return
+		}
+		query.AuthorID = author.ID
+	}
+
+	// Autores bloqueados e contas privadas que o usuário não segue ficam de
+	// fora antes da paginação
+	query.Visible = func(authorID uint64) (bool, error) {
+		visible, err := visibleAuthors(db, viewerID, []uint64{authorID})
+		if err != nil {
+			return false, err
+		}
+		return visible[authorID], nil
+	}
+
+	results, err := search.Engine.Search(query)
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	publishIDs := make([]uint64, len(results))
+	for i, result := range results {
+		publishIDs[i] = result.PublishID
+	}
+
+	publishesRepo := repository.NewPublishRepository(db)
+	publishes, err := publishesRepo.GetByIDs(publishIDs)
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if err = decoratePublishes(db, viewerID, publishes); err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	responses.JSON(w, http.StatusOK, publishes)
+}
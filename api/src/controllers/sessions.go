@@ -0,0 +1,154 @@
+package controllers
+
+import (
+	"api/src/authentication"
+	"api/src/database"
+	"api/src/models"
+	"api/src/repository"
+	"api/src/responses"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// GetSessions lista as sessões ativas do usuário, indicando a atual
+func GetSessions(w http.ResponseWriter, r *http.Request) {
+	userID, err := authentication.ExtractUserIDFromToken(r)
+	if err != nil {
+		responses.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+	currentID, err := authentication.ExtractSessionIDFromToken(r)
+	if err != nil {
+		responses.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewSessionsRepository(db)
+	sessions, err := repo.GetActive(userID)
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == currentID
+	}
+
+	responses.JSON(w, http.StatusOK, sessions)
+}
+
+// RevokeSession encerra uma sessão do usuário, que pode ser a atual
+func RevokeSession(w http.ResponseWriter, r *http.Request) {
+	userID, err := authentication.ExtractUserIDFromToken(r)
+	if err != nil {
+		responses.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	params := mux.Vars(r)
+	sessionID, err := strconv.ParseUint(params["sessionId"], 10, 64)
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewSessionsRepository(db)
+	revoked, err := repo.Revoke(sessionID, userID)
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	if !revoked {
+		responses.Error(w, http.StatusNotFound, errors.New("Sessão não encontrada"))
+		return
+	}
+
+	if err = audit(db, r, models.AuditEntry{
+		ActorID:  userID,
+		TargetID: userID,
+		Action:   models.AuditSessionRevoked,
+		Details:  map[string]string{"session_id": strconv.FormatUint(sessionID, 10)},
+	}); err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	responses.JSON(w, http.StatusNoContent, nil)
+}
+
+// RevokeOtherSessions encerra todas as sessões do usuário exceto a atual
+func RevokeOtherSessions(w http.ResponseWriter, r *http.Request) {
+	userID, err := authentication.ExtractUserIDFromToken(r)
+	if err != nil {
+		responses.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+	currentID, err := authentication.ExtractSessionIDFromToken(r)
+	if err != nil {
+		responses.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewSessionsRepository(db)
+	revoked, err := repo.RevokeAll(userID, currentID)
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if err = audit(db, r, models.AuditEntry{
+		ActorID:  userID,
+		TargetID: userID,
+		Action:   models.AuditSessionRevoked,
+		Details:  map[string]string{"sessions": strconv.FormatInt(revoked, 10), "except": strconv.FormatUint(currentID, 10)},
+	}); err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	responses.JSON(w, http.StatusNoContent, nil)
+}
+
+// startSession registra o login do usuário no dispositivo da requisição e
+// retorna o token da nova sessão
+func startSession(db *sql.DB, r *http.Request, userID uint64) (string, error) {
+	repo := repository.NewSessionsRepository(db)
+	sessionID, err := repo.Create(models.Session{
+		UserID:    userID,
+		Device:    models.DeviceName(r.UserAgent()),
+		UserAgent: r.UserAgent(),
+		IP:        clientIP(r),
+		ExpiresAt: time.Now().Add(authentication.TokenTTL),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return authentication.CreateToken(userID, sessionID)
+}
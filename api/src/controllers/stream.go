@@ -0,0 +1,62 @@
+package controllers
+
+import (
+	"api/src/authentication"
+	"api/src/events"
+	"api/src/responses"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const streamHeartbeat = 30 * time.Second
+
+// Stream mantém uma conexão Server-Sent Events aberta com as novas publicações
+// de quem o usuário segue e as suas notificações
+func Stream(w http.ResponseWriter, r *http.Request) {
+	userID, err := authentication.ExtractUserIDFromToken(r)
+	if err != nil {
+		responses.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		responses.Error(w, http.StatusInternalServerError, errors.New("Streaming não suportado"))
+		return
+	}
+
+	subscription := events.Broker.Subscribe(userID)
+	defer events.Broker.Unsubscribe(subscription)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": ping\n\n")
+			flusher.Flush()
+		case event, ok := <-subscription.Events:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(event.Data)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+			flusher.Flush()
+		}
+	}
+}
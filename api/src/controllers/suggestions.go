@@ -0,0 +1,104 @@
+package controllers
+
+import (
+	"api/src/authentication"
+	"api/src/database"
+	"api/src/models"
+	"api/src/repository"
+	"api/src/responses"
+	"api/src/suggestions"
+	"errors"
+	"github.com/gorilla/mux"
+	"net/http"
+	"strconv"
+)
+
+// GetSuggestions sugere quem seguir com base em amigos de amigos, hashtags em comum e popularidade
+func GetSuggestions(w http.ResponseWriter, r *http.Request) {
+	userID, err := authentication.ExtractUserIDFromToken(r)
+	if err != nil {
+		responses.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	_, limit, _ := pagination(r)
+
+	db, err := database.Connect()
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewSuggestionsRepository(db)
+	graph, err := repo.LoadGraph(userID)
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	ranked := suggestions.Rank(graph, suggestions.DefaultWeights, int(limit))
+
+	userIDs := make([]uint64, len(ranked))
+	for i, suggestion := range ranked {
+		userIDs[i] = suggestion.UserID
+	}
+
+	usersRepo := repository.NewUsersRepository(db)
+	users, err := usersRepo.GetByIDs(userIDs)
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	result := []models.Suggestion{}
+	for _, suggestion := range ranked {
+		user, ok := users[suggestion.UserID]
+		if !ok {
+			continue
+		}
+		result = append(result, models.Suggestion{
+			User:           user,
+			Score:          suggestion.Score,
+			MutualCount:    len(suggestion.Mutuals),
+			SharedHashtags: suggestion.SharedHashtags,
+		})
+	}
+
+	responses.JSON(w, http.StatusOK, result)
+}
+
+func DismissSuggestion(w http.ResponseWriter, r *http.Request) {
+	userIDInToken, err := authentication.ExtractUserIDFromToken(r)
+	if err != nil {
+		responses.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	params := mux.Vars(r)
+	userID, err := strconv.ParseUint(params["userId"], 10, 64)
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if userID == userIDInToken {
+		responses.Error(w, http.StatusForbidden, errors.New("Não é possível dispensar você mesmo"))
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewSuggestionsRepository(db)
+	if err = repo.Dismiss(userIDInToken, userID); err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	responses.JSON(w, http.StatusNoContent, nil)
+}
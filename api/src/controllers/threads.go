@@ -0,0 +1,179 @@
+package controllers
+
+import (
+	"api/src/authentication"
+	"api/src/database"
+	"api/src/models"
+	"api/src/repository"
+	"api/src/responses"
+	"database/sql"
+	"errors"
+	"github.com/gorilla/mux"
+	"net/http"
+	"strconv"
+)
+
+const (
+	defaultThreadDepth = 3
+	maxThreadDepth     = 10
+)
+
+// GetThread retorna a publicação com as que ela responde e uma página das
+// respostas em árvore, limitada pelo parâmetro depth
+func GetThread(w http.ResponseWriter, r *http.Request) {
+	viewerID, err := authentication.ExtractUserIDFromToken(r)
+	if err != nil {
+		responses.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	params := mux.Vars(r)
+	publishID, err := strconv.ParseUint(params["publishId"], 10, 64)
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	depth, err := strconv.ParseUint(r.URL.Query().Get("depth"), 10, 64)
+	if err != nil || depth == 0 {
+		depth = defaultThreadDepth
+	}
+	if depth > maxThreadDepth {
+		depth = maxThreadDepth
+	}
+
+	page, limit, offset := pagination(r)
+
+	db, err := database.Connect()
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewPublishRepository(db)
+	publish, err := repo.GetPublish(publishID)
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	if publish.ID == 0 || publish.IsDraft() {
+		responses.Error(w, http.StatusNotFound, errors.New("Publicação não encontrada"))
+		return
+	}
+
+	ancestors, err := repo.GetAncestors(publishID)
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	descendants, err := repo.GetDescendants(publishID, depth, limit, offset)
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	publishes := append(append(ancestors, publish), descendants...)
+	if err = decorateThreadPublishes(db, viewerID, publishes); err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	if err = hideUnavailable(db, viewerID, publishes); err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	responses.JSON(w, http.StatusOK, models.Thread{
+		Ancestors: publishes[:len(ancestors)],
+		Publish:   publishes[len(ancestors)],
+		Replies:   models.BuildThreadNodes(publishes[len(ancestors)+1:], publishID),
+		Page:      page,
+		Limit:     limit,
+		Depth:     depth,
+	})
+}
+
+// loadParents preenche Parent nas respostas com a publicação respondida
+func loadParents(db *sql.DB, viewerID uint64, publishes []models.Publish) error {
+	var parentIDs []uint64
+	for _, publish := range publishes {
+		if publish.ParentID != 0 {
+			parentIDs = append(parentIDs, publish.ParentID)
+		}
+	}
+	if len(parentIDs) == 0 {
+		return nil
+	}
+
+	repo := repository.NewPublishRepository(db)
+	parents, err := repo.GetParents(parentIDs)
+	if err != nil {
+		return err
+	}
+
+	var loaded []models.Publish
+	for _, parent := range parents {
+		loaded = append(loaded, parent)
+	}
+	if err = hideUnavailable(db, viewerID, loaded); err != nil {
+		return err
+	}
+
+	for _, parent := range loaded {
+		parents[parent.ID] = parent
+	}
+	for i := range publishes {
+		if parent, ok := parents[publishes[i].ParentID]; ok {
+			publishes[i].Parent = &parent
+		}
+	}
+
+	return nil
+}
+
+// hideUnavailable troca por um tombstone as publicações apagadas e as de
+// autores que quem consulta não pode ver
+func hideUnavailable(db *sql.DB, viewerID uint64, publishes []models.Publish) error {
+	authorIDs := make([]uint64, len(publishes))
+	for i, publish := range publishes {
+		authorIDs[i] = publish.AuthorID
+	}
+
+	visible, err := visibleAuthors(db, viewerID, authorIDs)
+	if err != nil {
+		return err
+	}
+
+	for i := range publishes {
+		if publishes[i].Status == models.PublishDeleted || !visible[publishes[i].AuthorID] {
+			publishes[i] = publishes[i].Tombstone()
+		}
+	}
+
+	return nil
+}
+
+// visibleAuthors indica, para cada autor, se viewerID pode ver as publicações dele
+func visibleAuthors(db *sql.DB, viewerID uint64, authorIDs []uint64) (map[uint64]bool, error) {
+	usersRepo := repository.NewUsersRepository(db)
+
+	visible := map[uint64]bool{}
+	for _, authorID := range authorIDs {
+		if _, checked := visible[authorID]; checked {
+			continue
+		}
+
+		blocked, err := usersRepo.IsBlocked(viewerID, authorID)
+		if err != nil {
+			return nil, err
+		}
+		allowed, err := usersRepo.CanViewContent(viewerID, authorID)
+		if err != nil {
+			return nil, err
+		}
+		visible[authorID] = !blocked && allowed
+	}
+
+	return visible, nil
+}
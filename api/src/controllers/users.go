@@ -2,7 +2,9 @@ package controllers
 
 import (
 	"api/src/authentication"
+	"api/src/config"
 	"api/src/database"
+	"api/src/media"
 	"api/src/models"
 	"api/src/repository"
 	"api/src/responses"
@@ -15,6 +17,7 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 )
 
 func CreateUser(w http.ResponseWriter, r *http.Request) {
@@ -54,6 +57,12 @@ func CreateUser(w http.ResponseWriter, r *http.Request) {
 }
 
 func GetUsers(w http.ResponseWriter, r *http.Request) {
+	viewerID, err := authentication.ExtractUserIDFromToken(r)
+	if err != nil {
+		responses.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
 	nameOrNick := strings.ToLower(r.URL.Query().Get("user"))
 
 	db, err := database.Connect()
@@ -65,7 +74,7 @@ func GetUsers(w http.ResponseWriter, r *http.Request) {
 
 	repo := repository.NewUsersRepository(db)
 
-	users, err := repo.Get(nameOrNick)
+	users, err := repo.Get(nameOrNick, viewerID)
 
 	if err != nil {
 		responses.Error(w, http.StatusInternalServerError, err)
@@ -76,6 +85,12 @@ func GetUsers(w http.ResponseWriter, r *http.Request) {
 }
 
 func GetUser(w http.ResponseWriter, r *http.Request) {
+	viewerID, err := authentication.ExtractUserIDFromToken(r)
+	if err != nil {
+		responses.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
 	params := mux.Vars(r)
 
 	userID, err := strconv.ParseUint(params["userId"], 10, 64)
@@ -92,12 +107,29 @@ func GetUser(w http.ResponseWriter, r *http.Request) {
 
 	repo := repository.NewUsersRepository(db)
 
-	user, err := repo.GetByID(userID)
+	blocked, err := repo.IsBlocked(viewerID, userID)
 	if err != nil {
 		responses.Error(w, http.StatusInternalServerError, err)
 		return
 	}
-	responses.JSON(w, http.StatusOK, user)
+	if blocked {
+		responses.Error(w, http.StatusNotFound, errors.New("Usuário não encontrado"))
+		return
+	}
+
+	profile, err := repo.GetProfile(userID, viewerID)
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	if profile.ID == 0 {
+		responses.Error(w, http.StatusNotFound, errors.New("Usuário não encontrado"))
+		return
+	}
+	if profile.AvatarKey != "" {
+		profile.AvatarURL = media.SignedURL(profile.AvatarKey)
+	}
+	responses.JSON(w, http.StatusOK, profile)
 }
 
 func UpdateUser(w http.ResponseWriter, r *http.Request) {
@@ -125,30 +157,61 @@ func UpdateUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var user models.User
-	if err = json.Unmarshal(bodyRequest, &user); err != nil {
+	db, err := database.Connect()
+	if err != nil {
 		responses.Error(w, http.StatusInternalServerError, err)
 		return
 	}
+	defer db.Close()
 
-	if err = user.Prepare("update"); err != nil {
+	repo := repository.NewUsersRepository(db)
+	storedUser, err := repo.GetByID(userID)
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	// O corpo é aplicado sobre o usuário gravado, então os campos que não
+	// foram enviados mantêm o valor atual
+	user := storedUser
+	if err = json.Unmarshal(bodyRequest, &user); err != nil {
 		responses.Error(w, http.StatusBadRequest, err)
 		return
 	}
 
-	db, err := database.Connect()
-	if err != nil {
-		responses.Error(w, http.StatusInternalServerError, err)
+	if err = user.Prepare("update"); err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
 		return
 	}
 
-	repo := repository.NewUsersRepository(db)
 	err = repo.Update(userID, user)
 	if err != nil {
 		responses.Error(w, http.StatusInternalServerError, err)
 		return
 	}
 
+	changes := []struct {
+		action   string
+		from, to string
+	}{
+		{models.AuditEmailChanged, storedUser.Email, user.Email},
+		{models.AuditNickChanged, storedUser.Nick, user.Nick},
+	}
+	for _, change := range changes {
+		if change.from == change.to {
+			continue
+		}
+		if err = audit(db, r, models.AuditEntry{
+			ActorID:  userID,
+			TargetID: userID,
+			Action:   change.action,
+			Details:  map[string]string{"from": change.from, "to": change.to},
+		}); err != nil {
+			responses.Error(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
 	responses.JSON(w, http.StatusNoContent, nil)
 }
 
@@ -178,7 +241,23 @@ func DeleteUser(w http.ResponseWriter, r *http.Request) {
 	defer db.Close()
 
 	repo := repository.NewUsersRepository(db)
-	if err = repo.Delete(userID); err != nil {
+	deleteAfter := time.Now().Add(config.AccountGracePeriod)
+	if err = repo.SoftDelete(userID, deleteAfter); err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if err = audit(db, r, models.AuditEntry{
+		ActorID:  userID,
+		TargetID: userID,
+		Action:   models.AuditUserDeleted,
+		Details:  map[string]string{"delete_after": deleteAfter.Format(time.RFC3339)},
+	}); err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if _, err = repository.NewSessionsRepository(db).RevokeAll(userID, 0); err != nil {
 		responses.Error(w, http.StatusInternalServerError, err)
 		return
 	}
@@ -186,6 +265,56 @@ func DeleteUser(w http.ResponseWriter, r *http.Request) {
 	responses.JSON(w, http.StatusNoContent, err)
 }
 
+// DeactivateUser oculta a conta do usuário até o próximo login
+func DeactivateUser(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	userID, err := strconv.ParseUint(params["userId"], 10, 64)
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	userIDInToken, err := authentication.ExtractUserIDFromToken(r)
+	if err != nil {
+		responses.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	if userID != userIDInToken {
+		responses.Error(w, http.StatusForbidden, errors.New("Não é possível desativar um usuário que não seja o seu"))
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewUsersRepository(db)
+	if err = repo.Deactivate(userID); err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if err = audit(db, r, models.AuditEntry{
+		ActorID:  userID,
+		TargetID: userID,
+		Action:   models.AuditUserDeactivated,
+	}); err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if _, err = repository.NewSessionsRepository(db).RevokeAll(userID, 0); err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	responses.JSON(w, http.StatusNoContent, nil)
+}
+
 func FollowUser(w http.ResponseWriter, r *http.Request) {
 	followerID, err := authentication.ExtractUserIDFromToken(r)
 	if err != nil {
@@ -213,12 +342,71 @@ func FollowUser(w http.ResponseWriter, r *http.Request) {
 	defer db.Close()
 	repo := repository.NewUsersRepository(db)
 
-	err = repo.FollowUser(userID, followerID)
+	active, err := repo.IsActive(userID)
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	if !active {
+		responses.Error(w, http.StatusNotFound, errors.New("Usuário não encontrado"))
+		return
+	}
+
+	blocked, err := repo.IsBlocked(userID, followerID)
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	if blocked {
+		responses.Error(w, http.StatusForbidden, errors.New("Não é possível seguir este usuário"))
+		return
+	}
+
+	// Contas privadas recebem um pedido que precisa ser aprovado
+	alreadyAllowed, err := repo.CanViewContent(followerID, userID)
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	if !alreadyAllowed {
+		created, err := repo.CreateFollowRequest(userID, followerID)
+		if err != nil {
+			responses.Error(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if created {
+			if err = notify(db, models.Notification{
+				UserID:  userID,
+				ActorID: followerID,
+				Type:    models.NotificationFollowRequest,
+			}); err != nil {
+				responses.Error(w, http.StatusInternalServerError, err)
+				return
+			}
+		}
+
+		responses.JSON(w, http.StatusAccepted, nil)
+		return
+	}
+
+	created, err := repo.FollowUser(userID, followerID)
 	if err != nil {
 		responses.Error(w, http.StatusInternalServerError, err)
 		return
 	}
 
+	if created {
+		if err = notify(db, models.Notification{
+			UserID:  userID,
+			ActorID: followerID,
+			Type:    models.NotificationFollow,
+		}); err != nil {
+			responses.Error(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
 	responses.JSON(w, http.StatusNoContent, nil)
 }
 
@@ -259,6 +447,12 @@ func StopFollowUser(w http.ResponseWriter, r *http.Request) {
 }
 
 func GetFollowers(w http.ResponseWriter, r *http.Request) {
+	viewerID, err := authentication.ExtractUserIDFromToken(r)
+	if err != nil {
+		responses.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
 	params := mux.Vars(r)
 	userID, err := strconv.ParseUint(params["userId"], 10, 64)
 	if err != nil {
@@ -274,6 +468,16 @@ func GetFollowers(w http.ResponseWriter, r *http.Request) {
 	defer db.Close()
 
 	repo := repository.NewUsersRepository(db)
+	allowed, err := repo.CanViewContent(viewerID, userID)
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	if !allowed {
+		responses.Error(w, http.StatusForbidden, errors.New("Esta conta é privada"))
+		return
+	}
+
 	followers, err := repo.GetFollowers(userID)
 	if err != nil {
 		responses.Error(w, http.StatusInternalServerError, err)
@@ -284,6 +488,12 @@ func GetFollowers(w http.ResponseWriter, r *http.Request) {
 }
 
 func GetFollowing(w http.ResponseWriter, r *http.Request) {
+	viewerID, err := authentication.ExtractUserIDFromToken(r)
+	if err != nil {
+		responses.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
 	params := mux.Vars(r)
 	userID, err := strconv.ParseUint(params["userId"], 10, 64)
 	if err != nil {
@@ -299,6 +509,16 @@ func GetFollowing(w http.ResponseWriter, r *http.Request) {
 	defer db.Close()
 
 	repo := repository.NewUsersRepository(db)
+	allowed, err := repo.CanViewContent(viewerID, userID)
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	if !allowed {
+		responses.Error(w, http.StatusForbidden, errors.New("Esta conta é privada"))
+		return
+	}
+
 	following, err := repo.GetFollowing(userID)
 	if err != nil {
 		responses.Error(w, http.StatusInternalServerError, err)
@@ -378,5 +598,393 @@ func UpdatePassword(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err = audit(db, r, models.AuditEntry{
+		ActorID:  userID,
+		TargetID: userID,
+		Action:   models.AuditPasswordChanged,
+	}); err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	// Quem souber a senha antiga não continua logado nos outros dispositivos
+	sessionID, err := authentication.ExtractSessionIDFromToken(r)
+	if err != nil {
+		responses.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+	if _, err = repository.NewSessionsRepository(db).RevokeAll(userID, sessionID); err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
 	responses.JSON(w, http.StatusOK, nil)
 }
+
+func BlockUser(w http.ResponseWriter, r *http.Request) {
+	userIDInToken, err := authentication.ExtractUserIDFromToken(r)
+	if err != nil {
+		responses.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	params := mux.Vars(r)
+	userID, err := strconv.ParseUint(params["userId"], 10, 64)
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if userID == userIDInToken {
+		responses.Error(w, http.StatusForbidden, errors.New("Não é possível bloquear você mesmo"))
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewUsersRepository(db)
+	if err = repo.Block(userIDInToken, userID); err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	responses.JSON(w, http.StatusNoContent, nil)
+}
+
+func UnblockUser(w http.ResponseWriter, r *http.Request) {
+	userIDInToken, err := authentication.ExtractUserIDFromToken(r)
+	if err != nil {
+		responses.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	params := mux.Vars(r)
+	userID, err := strconv.ParseUint(params["userId"], 10, 64)
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if userID == userIDInToken {
+		responses.Error(w, http.StatusForbidden, errors.New("Não é possível desbloquear você mesmo"))
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewUsersRepository(db)
+	if err = repo.Unblock(userIDInToken, userID); err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	responses.JSON(w, http.StatusNoContent, nil)
+}
+
+func MuteUser(w http.ResponseWriter, r *http.Request) {
+	userIDInToken, err := authentication.ExtractUserIDFromToken(r)
+	if err != nil {
+		responses.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	params := mux.Vars(r)
+	userID, err := strconv.ParseUint(params["userId"], 10, 64)
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if userID == userIDInToken {
+		responses.Error(w, http.StatusForbidden, errors.New("Não é possível silenciar você mesmo"))
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewUsersRepository(db)
+	if err = repo.Mute(userIDInToken, userID); err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	responses.JSON(w, http.StatusNoContent, nil)
+}
+
+func UnmuteUser(w http.ResponseWriter, r *http.Request) {
+	userIDInToken, err := authentication.ExtractUserIDFromToken(r)
+	if err != nil {
+		responses.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	params := mux.Vars(r)
+	userID, err := strconv.ParseUint(params["userId"], 10, 64)
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if userID == userIDInToken {
+		responses.Error(w, http.StatusForbidden, errors.New("Não é possível deixar de silenciar você mesmo"))
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewUsersRepository(db)
+	if err = repo.Unmute(userIDInToken, userID); err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	responses.JSON(w, http.StatusNoContent, nil)
+}
+
+func GetBlockedUsers(w http.ResponseWriter, r *http.Request) {
+	userID, err := authentication.ExtractUserIDFromToken(r)
+	if err != nil {
+		responses.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewUsersRepository(db)
+	users, err := repo.GetBlocked(userID)
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	responses.JSON(w, http.StatusOK, users)
+}
+
+func GetMutedUsers(w http.ResponseWriter, r *http.Request) {
+	userID, err := authentication.ExtractUserIDFromToken(r)
+	if err != nil {
+		responses.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewUsersRepository(db)
+	users, err := repo.GetMuted(userID)
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	responses.JSON(w, http.StatusOK, users)
+}
+
+func GetFollowRequests(w http.ResponseWriter, r *http.Request) {
+	userID, err := authentication.ExtractUserIDFromToken(r)
+	if err != nil {
+		responses.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewUsersRepository(db)
+	requesters, err := repo.GetFollowRequests(userID)
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	responses.JSON(w, http.StatusOK, requesters)
+}
+
+func ApproveFollowRequest(w http.ResponseWriter, r *http.Request) {
+	userID, err := authentication.ExtractUserIDFromToken(r)
+	if err != nil {
+		responses.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	params := mux.Vars(r)
+	requesterID, err := strconv.ParseUint(params["userId"], 10, 64)
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewUsersRepository(db)
+	blocked, err := repo.IsBlocked(userID, requesterID)
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	if blocked {
+		if err = repo.RejectFollowRequest(userID, requesterID); err != nil {
+			responses.Error(w, http.StatusInternalServerError, err)
+			return
+		}
+		responses.Error(w, http.StatusNotFound, errors.New("Pedido para seguir não encontrado"))
+		return
+	}
+
+	approved, err := repo.ApproveFollowRequest(userID, requesterID)
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	if !approved {
+		responses.Error(w, http.StatusNotFound, errors.New("Pedido para seguir não encontrado"))
+		return
+	}
+
+	notifications := []models.Notification{
+		{UserID: userID, ActorID: requesterID, Type: models.NotificationFollow},
+		{UserID: requesterID, ActorID: userID, Type: models.NotificationFollowAccepted},
+	}
+	for _, notification := range notifications {
+		if err = notify(db, notification); err != nil {
+			responses.Error(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	responses.JSON(w, http.StatusNoContent, nil)
+}
+
+func RejectFollowRequest(w http.ResponseWriter, r *http.Request) {
+	userID, err := authentication.ExtractUserIDFromToken(r)
+	if err != nil {
+		responses.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	params := mux.Vars(r)
+	requesterID, err := strconv.ParseUint(params["userId"], 10, 64)
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewUsersRepository(db)
+	if err = repo.RejectFollowRequest(userID, requesterID); err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	responses.JSON(w, http.StatusNoContent, nil)
+}
+
+// UpdateUserRole altera o papel de um usuário, permitido apenas a administradores
+func UpdateUserRole(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	userID, err := strconv.ParseUint(params["userId"], 10, 64)
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	requestBody, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var user models.User
+	if err = json.Unmarshal(requestBody, &user); err != nil {
+		responses.Error(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	if !models.ValidRole(user.Role) {
+		responses.Error(w, http.StatusBadRequest, errors.New("Papel inválido"))
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewUsersRepository(db)
+	role, err := repo.GetRole(userID)
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	if role == "" {
+		responses.Error(w, http.StatusNotFound, errors.New("Usuário não encontrado"))
+		return
+	}
+
+	if err = repo.UpdateRole(userID, user.Role); err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	adminID, err := authentication.ExtractUserIDFromToken(r)
+	if err != nil {
+		responses.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+	if err = audit(db, r, models.AuditEntry{
+		ActorID:  adminID,
+		TargetID: userID,
+		Action:   models.AuditRoleChanged,
+		Details:  map[string]string{"from": role, "to": user.Role},
+	}); err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	responses.JSON(w, http.StatusNoContent, nil)
+}
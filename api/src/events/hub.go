@@ -0,0 +1,95 @@
+package events
+
+import "sync"
+
+// Tipos de evento enviados pelo stream
+const (
+	PublishCreated      = "publish.created"
+	NotificationCreated = "notification.created"
+)
+
+type Event struct {
+	Type string      `json:"type"`
+	Data interface{} `json:"data"`
+}
+
+// Hub distribui eventos para as conexões abertas de cada usuário.
+// A implementação em memória atende uma única instância da API; uma
+// implementação sobre Redis ou NATS pode ser plugada atrás da mesma interface.
+type Hub interface {
+	Publish(userID uint64, event Event)
+	Subscribe(userID uint64) *Subscription
+	Unsubscribe(subscription *Subscription)
+}
+
+// Subscription é uma conexão inscrita no hub. O canal Events é fechado
+// quando a inscrição é encerrada, inclusive quando o cliente não consome
+// os eventos rápido o suficiente e o buffer enche.
+type Subscription struct {
+	UserID uint64
+	Events chan Event
+	once   sync.Once
+}
+
+func (s *Subscription) close() {
+	s.once.Do(func() { close(s.Events) })
+}
+
+// Broker é o hub usado pela API, substituído em main conforme a configuração
+var Broker Hub = NewMemoryHub(64)
+
+type MemoryHub struct {
+	mu            sync.Mutex
+	bufferSize    int
+	subscriptions map[uint64]map[*Subscription]bool
+}
+
+func NewMemoryHub(bufferSize int) *MemoryHub {
+	return &MemoryHub{
+		bufferSize:    bufferSize,
+		subscriptions: map[uint64]map[*Subscription]bool{},
+	}
+}
+
+// Publish nunca bloqueia: assinantes com o buffer cheio são desconectados
+// para que um cliente lento não atrase os demais.
+func (h *MemoryHub) Publish(userID uint64, event Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for subscription := range h.subscriptions[userID] {
+		select {
+		case subscription.Events <- event:
+		default:
+			h.remove(subscription)
+		}
+	}
+}
+
+func (h *MemoryHub) Subscribe(userID uint64) *Subscription {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	subscription := &Subscription{UserID: userID, Events: make(chan Event, h.bufferSize)}
+	if h.subscriptions[userID] == nil {
+		h.subscriptions[userID] = map[*Subscription]bool{}
+	}
+	h.subscriptions[userID][subscription] = true
+
+	return subscription
+}
+
+func (h *MemoryHub) Unsubscribe(subscription *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.remove(subscription)
+}
+
+func (h *MemoryHub) remove(subscription *Subscription) {
+	delete(h.subscriptions[subscription.UserID], subscription)
+	if len(h.subscriptions[subscription.UserID]) == 0 {
+		delete(h.subscriptions, subscription.UserID)
+	}
+	subscription.close()
+}
@@ -0,0 +1,140 @@
+package events
+
+import (
+	"sync"
+	"testing"
+)
+
+// receive lê um evento sem bloquear, falhando se não houver nenhum
+func receive(t *testing.T, subscription *Subscription) Event {
+	t.Helper()
+	select {
+	case event, ok := <-subscription.Events:
+		if !ok {
+			t.Fatal("o canal foi fechado")
+		}
+		return event
+	default:
+		t.Fatal("nenhum evento recebido")
+	}
+	return Event{}
+}
+
+func assertEmpty(t *testing.T, subscription *Subscription) {
+	t.Helper()
+	select {
+	case event, ok := <-subscription.Events:
+		if ok {
+			t.Fatalf("evento inesperado: %+v", event)
+		}
+		t.Fatal("o canal foi fechado")
+	default:
+	}
+}
+
+func assertClosed(t *testing.T, subscription *Subscription) {
+	t.Helper()
+	for {
+		select {
+		case _, ok := <-subscription.Events:
+			if !ok {
+				return
+			}
+		default:
+			t.Fatal("o canal deveria estar fechado")
+		}
+	}
+}
+
+func TestPublishReachesUserSubscriptions(t *testing.T) {
+	hub := NewMemoryHub(4)
+	first := hub.Subscribe(1)
+	second := hub.Subscribe(1)
+	other := hub.Subscribe(2)
+
+	event := Event{Type: PublishCreated, Data: "publicação"}
+	hub.Publish(1, event)
+
+	if got := receive(t, first); got != event {
+		t.Errorf("primeira conexão recebeu %+v", got)
+	}
+	if got := receive(t, second); got != event {
+		t.Errorf("segunda conexão recebeu %+v", got)
+	}
+	assertEmpty(t, other)
+}
+
+func TestPublishWithoutSubscribers(t *testing.T) {
+	hub := NewMemoryHub(4)
+	hub.Publish(1, Event{Type: NotificationCreated})
+
+	if len(hub.subscriptions) != 0 {
+		t.Errorf("nenhuma inscrição deveria existir, há %d", len(hub.subscriptions))
+	}
+}
+
+func TestUnsubscribeClosesChannel(t *testing.T) {
+	hub := NewMemoryHub(4)
+	subscription := hub.Subscribe(1)
+	remaining := hub.Subscribe(1)
+
+	hub.Unsubscribe(subscription)
+	assertClosed(t, subscription)
+
+	// Encerrar de novo não pode fechar o canal duas vezes
+	hub.Unsubscribe(subscription)
+
+	hub.Publish(1, Event{Type: PublishCreated})
+	receive(t, remaining)
+
+	hub.Unsubscribe(remaining)
+	if _, found := hub.subscriptions[1]; found {
+		t.Error("o usuário sem conexões deveria sair do hub")
+	}
+}
+
+func TestSlowSubscriberIsDisconnected(t *testing.T) {
+	hub := NewMemoryHub(2)
+	slow := hub.Subscribe(1)
+	fast := hub.Subscribe(1)
+
+	for i := 0; i < 3; i++ {
+		hub.Publish(1, Event{Type: PublishCreated, Data: i})
+		receive(t, fast)
+	}
+
+	// Os eventos que couberam no buffer ainda são entregues antes do fechamento
+	for i := 0; i < 2; i++ {
+		if got := receive(t, slow); got.Data != i {
+			t.Errorf("evento %d = %+v", i, got)
+		}
+	}
+	assertClosed(t, slow)
+
+	hub.Publish(1, Event{Type: PublishCreated})
+	receive(t, fast)
+}
+
+func TestConcurrentUse(t *testing.T) {
+	hub := NewMemoryHub(1)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func(userID uint64) {
+			defer wg.Done()
+			subscription := hub.Subscribe(userID % 3)
+			hub.Publish(userID%3, Event{Type: PublishCreated})
+			hub.Unsubscribe(subscription)
+		}(uint64(i))
+		go func(userID uint64) {
+			defer wg.Done()
+			hub.Publish(userID%3, Event{Type: NotificationCreated})
+		}(uint64(i))
+	}
+	wg.Wait()
+
+	if len(hub.subscriptions) != 0 {
+		t.Errorf("todas as inscrições deveriam ter sido removidas, há %d", len(hub.subscriptions))
+	}
+}
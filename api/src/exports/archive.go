@@ -0,0 +1,196 @@
+package exports
+
+import (
+	"api/src/jobs"
+	"api/src/models"
+	"api/src/repository"
+	"api/src/security"
+	"api/src/storage"
+	"archive/zip"
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Quantidade de notificações lidas por consulta ao montar o arquivo
+const notificationsPage = 500
+
+// expirePayload identifica o arquivo a ser apagado pelo job de expiração
+type expirePayload struct {
+	Key string `json:"key"`
+}
+
+// NewHandler monta o arquivo ZIP com os dados do dono do job, grava no
+// armazenamento e agenda a remoção do arquivo depois de retention. O resultado
+// do job é a chave do arquivo.
+func NewHandler(db *sql.DB, retention time.Duration) jobs.Handler {
+	return func(job models.Job) (string, error) {
+		if job.UserID == 0 {
+			return "", errors.New("Exportação sem usuário")
+		}
+
+		data, err := Build(db, job.UserID)
+		if err != nil {
+			return "", err
+		}
+
+		token, err := security.RandomToken(16)
+		if err != nil {
+			return "", err
+		}
+		key := "export_" + token + ".zip"
+		if err = storage.Store.Put(key, "application/zip", data); err != nil {
+			return "", err
+		}
+
+		payload, err := json.Marshal(expirePayload{Key: key})
+		if err != nil {
+			return "", err
+		}
+		if _, err = repository.NewJobsRepository(db).Create(models.Job{
+			Type:     models.JobExportExpire,
+			Payload:  string(payload),
+			RunAfter: time.Now().Add(retention),
+		}); err != nil {
+			return "", err
+		}
+
+		return key, nil
+	}
+}
+
+// ExpireHandler apaga do armazenamento um arquivo de exportação vencido
+func ExpireHandler(job models.Job) (string, error) {
+	var payload expirePayload
+	if err := json.Unmarshal([]byte(job.Payload), &payload); err != nil {
+		return "", err
+	}
+
+	if err := storage.Store.Delete(payload.Key); err != nil && err != storage.ErrNotFound {
+		return "", err
+	}
+	return "", nil
+}
+
+// Build monta o arquivo ZIP com tudo o que guardamos sobre o usuário
+func Build(db *sql.DB, userID uint64) ([]byte, error) {
+	users := repository.NewUsersRepository(db)
+	publishes := repository.NewPublishRepository(db)
+
+	profile, err := users.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if profile.ID == 0 {
+		return nil, errors.New("Usuário não encontrado")
+	}
+
+	authored, err := publishes.GetAllByAuthor(userID)
+	if err != nil {
+		return nil, err
+	}
+	posts, comments := []models.Publish{}, []models.Publish{}
+	for _, publish := range authored {
+		if publish.ParentID == 0 {
+			posts = append(posts, publish)
+		} else {
+			comments = append(comments, publish)
+		}
+	}
+
+	liked, err := publishes.GetLikedBy(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	followers, err := users.GetFollowers(userID)
+	if err != nil {
+		return nil, err
+	}
+	following, err := users.GetFollowing(userID)
+	if err != nil {
+		return nil, err
+	}
+	blocked, err := users.GetBlocked(userID)
+	if err != nil {
+		return nil, err
+	}
+	muted, err := users.GetMuted(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	bookmarks, err := repository.NewBookmarksRepository(db).Get(userID, 0, ^uint64(0)>>1, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	notifications, err := allNotifications(repository.NewNotificationsRepository(db), userID)
+	if err != nil {
+		return nil, err
+	}
+
+	files := []struct {
+		name string
+		data interface{}
+	}{
+		{"profile.json", profile},
+		{"publishes.json", posts},
+		{"comments.json", comments},
+		{"likes.json", liked},
+		{"followers.json", contacts(followers)},
+		{"following.json", contacts(following)},
+		{"blocked.json", contacts(blocked)},
+		{"muted.json", contacts(muted)},
+		{"bookmarks.json", bookmarks},
+		{"notifications.json", notifications},
+	}
+
+	var buffer bytes.Buffer
+	archive := zip.NewWriter(&buffer)
+	for _, file := range files {
+		content, err := json.MarshalIndent(file.data, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+
+		writer, err := archive.Create(file.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err = writer.Write(content); err != nil {
+			return nil, err
+		}
+	}
+	if err = archive.Close(); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+func allNotifications(repo *repository.Notifications, userID uint64) ([]models.Notification, error) {
+	notifications := []models.Notification{}
+	for offset := uint64(0); ; offset += notificationsPage {
+		page, err := repo.Get(userID, notificationsPage, offset)
+		if err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, page...)
+		if len(page) < notificationsPage {
+			return notifications, nil
+		}
+	}
+}
+
+// contacts remove o e-mail dos outros usuários, que não faz parte dos dados do dono
+func contacts(users []models.User) []models.User {
+	list := make([]models.User, 0, len(users))
+	for _, user := range users {
+		user.Email = ""
+		list = append(list, user)
+	}
+	return list
+}
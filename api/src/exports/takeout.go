@@ -0,0 +1,126 @@
+package exports
+
+import (
+	"api/src/models"
+	"api/src/security"
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"time"
+)
+
+// Tamanho máximo de cada arquivo descompactado, para recusar arquivos forjados
+const maxTakeoutFile = 64 << 20
+
+// Read lê um arquivo no formato gerado por Build
+func Read(data []byte) (models.Takeout, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return models.Takeout{}, errors.New("O arquivo não é um ZIP válido")
+	}
+
+	var takeout models.Takeout
+	targets := map[string]interface{}{
+		"profile.json":   &takeout.Profile,
+		"publishes.json": &takeout.Publishes,
+		"comments.json":  &takeout.Comments,
+		"followers.json": &takeout.Followers,
+		"following.json": &takeout.Following,
+	}
+
+	for _, file := range archive.File {
+		target, found := targets[file.Name]
+		if !found {
+			continue
+		}
+
+		content, err := readFile(file)
+		if err != nil {
+			return models.Takeout{}, err
+		}
+		if err = json.Unmarshal(content, target); err != nil {
+			return models.Takeout{}, fmt.Errorf("%s inválido: %v", file.Name, err)
+		}
+		delete(targets, file.Name)
+	}
+
+	if _, missing := targets["profile.json"]; missing {
+		return models.Takeout{}, errors.New("O arquivo não contém profile.json")
+	}
+
+	now := time.Now()
+	if takeout.Profile.CreatedAt.IsZero() {
+		takeout.Profile.CreatedAt = now
+	}
+	for _, publishes := range [][]models.Publish{takeout.Publishes, takeout.Comments} {
+		for i := range publishes {
+			if err = normalizePublish(&publishes[i], now); err != nil {
+				return models.Takeout{}, err
+			}
+		}
+	}
+
+	return takeout, nil
+}
+
+func readFile(file *zip.File) ([]byte, error) {
+	reader, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	content, err := ioutil.ReadAll(io.LimitReader(reader, maxTakeoutFile+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(content) > maxTakeoutFile {
+		return nil, fmt.Errorf("%s excede o tamanho máximo", file.Name)
+	}
+	return content, nil
+}
+
+func normalizePublish(publish *models.Publish, now time.Time) error {
+	if publish.ID == 0 {
+		return errors.New("Publicação sem ID no arquivo")
+	}
+
+	switch publish.Status {
+	case "":
+		publish.Status = models.PublishPublished
+	case models.PublishDraft, models.PublishScheduled, models.PublishPublished:
+	default:
+		return fmt.Errorf("Situação de publicação inválida: %s", publish.Status)
+	}
+
+	if publish.CreatedAt.IsZero() {
+		publish.CreatedAt = now
+	}
+	if publish.Status == models.PublishScheduled && publish.PublishAt == nil {
+		publish.Status = models.PublishDraft
+	}
+	return nil
+}
+
+// PrepareProfile valida o perfil do arquivo e define a senha da conta. Sem
+// password, gera e retorna uma senha temporária.
+func PrepareProfile(takeout *models.Takeout, password string) (string, error) {
+	temporary := ""
+	if password == "" {
+		var err error
+		if temporary, err = security.RandomToken(12); err != nil {
+			return "", err
+		}
+		password = temporary
+	}
+
+	takeout.Profile.Password = password
+	if err := takeout.Profile.Prepare("register"); err != nil {
+		return "", err
+	}
+	return temporary, nil
+}
@@ -0,0 +1,85 @@
+package feed
+
+import (
+	"api/src/models"
+	"math"
+	"sort"
+	"time"
+)
+
+// Weights controla a importância de cada sinal na pontuação do feed
+type Weights struct {
+	Recency  float64
+	Likes    float64
+	Comments float64
+	Affinity float64
+	// Tempo para a pontuação de uma publicação cair pela metade
+	HalfLife time.Duration
+	// Multiplicador aplicado a publicações de conexões de segundo grau
+	SecondDegree float64
+}
+
+// Default é o ranker usado pela API, definido em main a partir da configuração
+var Default = NewRanker(Weights{
+	Recency:      1,
+	Likes:        0.5,
+	Comments:     0.8,
+	Affinity:     1,
+	HalfLife:     12 * time.Hour,
+	SecondDegree: 0.5,
+})
+
+// Ranker ordena os candidatos do feed. O relógio é injetável para que o
+// resultado seja determinístico nos testes.
+type Ranker struct {
+	Weights Weights
+	Now     func() time.Time
+}
+
+func NewRanker(weights Weights) *Ranker {
+	return &Ranker{Weights: weights, Now: time.Now}
+}
+
+// Rank pontua os candidatos e os devolve do maior para o menor score.
+// Empates são resolvidos pela publicação mais recente.
+func (r *Ranker) Rank(candidates []models.FeedCandidate) []models.FeedCandidate {
+	now := r.Now()
+	for i := range candidates {
+		candidates[i].Score = r.score(candidates[i], now)
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].Score != candidates[j].Score {
+			return candidates[i].Score > candidates[j].Score
+		}
+		return candidates[i].Publish.ID > candidates[j].Publish.ID
+	})
+
+	return candidates
+}
+
+// score combina engajamento e afinidade com o autor, multiplicados pelo
+// decaimento exponencial da idade da publicação
+func (r *Ranker) score(candidate models.FeedCandidate, now time.Time) float64 {
+	age := now.Sub(candidate.Publish.CreatedAt)
+	if age < 0 {
+		age = 0
+	}
+
+	decay := 1.0
+	if r.Weights.HalfLife > 0 {
+		decay = math.Pow(0.5, float64(age)/float64(r.Weights.HalfLife))
+	}
+
+	engagement := r.Weights.Recency +
+		r.Weights.Likes*math.Log1p(float64(candidate.Publish.Likes)) +
+		r.Weights.Comments*math.Log1p(float64(candidate.Comments)) +
+		r.Weights.Affinity*math.Log1p(float64(candidate.Affinity))
+
+	score := engagement * decay
+	if candidate.SecondDegree {
+		score *= r.Weights.SecondDegree
+	}
+
+	return score
+}
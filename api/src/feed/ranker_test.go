@@ -0,0 +1,135 @@
+package feed
+
+import (
+	"api/src/models"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var now = time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+
+// Pesos em que só a recência conta, para que o score seja apenas o decaimento
+var recencyOnly = Weights{Recency: 1, HalfLife: time.Hour, SecondDegree: 0.5}
+
+func newFixedRanker(weights Weights) *Ranker {
+	ranker := NewRanker(weights)
+	ranker.Now = func() time.Time { return now }
+	return ranker
+}
+
+func candidate(id uint64, age time.Duration) models.FeedCandidate {
+	return models.FeedCandidate{Publish: models.Publish{ID: id, CreatedAt: now.Add(-age)}}
+}
+
+func rankedIDs(candidates []models.FeedCandidate) []uint64 {
+	ids := []uint64{}
+	for _, candidate := range candidates {
+		ids = append(ids, candidate.Publish.ID)
+	}
+	return ids
+}
+
+func TestScore(t *testing.T) {
+	tests := []struct {
+		name      string
+		weights   Weights
+		candidate models.FeedCandidate
+		want      float64
+	}{
+		{
+			name:      "publicação recém criada",
+			weights:   recencyOnly,
+			candidate: candidate(1, 0),
+			want:      1,
+		},
+		{
+			name:      "cai pela metade a cada meia-vida",
+			weights:   recencyOnly,
+			candidate: candidate(1, 2*time.Hour),
+			want:      0.25,
+		},
+		{
+			name:      "publicação no futuro não ganha bônus",
+			weights:   recencyOnly,
+			candidate: candidate(1, -time.Hour),
+			want:      1,
+		},
+		{
+			name:    "conexão de segundo grau",
+			weights: recencyOnly,
+			candidate: models.FeedCandidate{
+				Publish:      models.Publish{ID: 1, CreatedAt: now},
+				SecondDegree: true,
+			},
+			want: 0.5,
+		},
+		{
+			name:    "engajamento e afinidade",
+			weights: Weights{Likes: 1, Comments: 2, Affinity: 3},
+			candidate: models.FeedCandidate{
+				Publish:  models.Publish{ID: 1, CreatedAt: now.Add(-24 * time.Hour), Likes: 1},
+				Comments: 1,
+				Affinity: 1,
+			},
+			want: 6 * math.Log(2),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ranker := newFixedRanker(test.weights)
+			if got := ranker.score(test.candidate, now); math.Abs(got-test.want) > 1e-9 {
+				t.Errorf("score = %v, esperado %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestRankOrder(t *testing.T) {
+	ranker := newFixedRanker(Weights{Recency: 1, Likes: 1, HalfLife: time.Hour, SecondDegree: 0.5})
+
+	popular := candidate(1, 2*time.Hour)
+	popular.Publish.Likes = 100
+	secondDegree := candidate(4, 0)
+	secondDegree.SecondDegree = true
+
+	ranked := ranker.Rank([]models.FeedCandidate{
+		candidate(2, 3*time.Hour),
+		popular,
+		secondDegree,
+		candidate(3, 0),
+	})
+
+	// popular: (1 + ln 101) / 4 ≈ 1.40; 3: 1; 4: 0.5; 2: 0.125
+	if got, want := rankedIDs(ranked), []uint64{1, 3, 4, 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ordem = %v, esperado %v", got, want)
+	}
+}
+
+func TestRankIsDeterministic(t *testing.T) {
+	ranker := newFixedRanker(recencyOnly)
+
+	// Mesmo score: o empate vai para a publicação mais recente, pelo ID
+	candidates := []models.FeedCandidate{
+		candidate(3, time.Hour),
+		candidate(7, time.Hour),
+		candidate(5, time.Hour),
+		candidate(9, 0),
+	}
+	want := []uint64{9, 7, 5, 3}
+
+	for i := 0; i < len(candidates); i++ {
+		shuffled := append(append([]models.FeedCandidate{}, candidates[i:]...), candidates[:i]...)
+		if got := rankedIDs(ranker.Rank(shuffled)); !reflect.DeepEqual(got, want) {
+			t.Errorf("rotação %d: ordem = %v, esperado %v", i, got, want)
+		}
+	}
+
+	first := ranker.Rank(append([]models.FeedCandidate{}, candidates...))
+	second := ranker.Rank(append([]models.FeedCandidate{}, candidates...))
+	if !reflect.DeepEqual(first, second) {
+		t.Error("o mesmo relógio deveria produzir o mesmo resultado")
+	}
+}
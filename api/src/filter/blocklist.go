@@ -0,0 +1,201 @@
+package filter
+
+import (
+	"api/src/models"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var linkRegexp = regexp.MustCompile(`(?i)\b(?:https?://|www\.)[^\s<>"]+`)
+
+// patternRule casa uma expressão regular com o título e o conteúdo. Nas
+// entradas do tipo palavra, o segundo grupo é a palavra e os outros dois são
+// os separadores.
+type patternRule struct {
+	filter  models.ContentFilter
+	pattern *regexp.Regexp
+}
+
+func (r patternRule) Check(input Input) (*Decision, error) {
+	if !r.pattern.MatchString(input.Title) && !r.pattern.MatchString(input.Content) {
+		return nil, nil
+	}
+	return &Decision{
+		Action: r.filter.Action,
+		Rule:   fmt.Sprintf("%s:%d", r.filter.Kind, r.filter.ID),
+		Reason: "A publicação contém termos não permitidos",
+		Detail: fmt.Sprintf("Conteúdo contém %q", r.filter.Pattern),
+	}, nil
+}
+
+// mask troca por espaços o trecho que a exceção cobre. Os separadores das
+// palavras são mantidos, então ocorrências vizinhas, que a busca pula por
+// dividirem o separador, são cobertas nas passadas seguintes.
+func (r patternRule) mask(text string) string {
+	for {
+		var spans [][2]int
+		for _, loc := range r.pattern.FindAllStringSubmatchIndex(text, -1) {
+			if r.filter.Kind == models.FilterWord {
+				spans = append(spans, [2]int{loc[4], loc[5]})
+			} else {
+				spans = append(spans, [2]int{loc[0], loc[1]})
+			}
+		}
+
+		masked := maskSpans(text, spans)
+		if masked == text {
+			return text
+		}
+		text = masked
+	}
+}
+
+// domainRule casa os links do conteúdo com um domínio e seus subdomínios
+type domainRule struct {
+	filter models.ContentFilter
+}
+
+func (r domainRule) matches(host string) bool {
+	return host == r.filter.Pattern || strings.HasSuffix(host, "."+r.filter.Pattern)
+}
+
+func (r domainRule) Check(input Input) (*Decision, error) {
+	for _, host := range linkHosts(input.Title + " " + input.Content) {
+		if r.matches(host) {
+			return &Decision{
+				Action: r.filter.Action,
+				Rule:   fmt.Sprintf("%s:%d", r.filter.Kind, r.filter.ID),
+				Reason: "A publicação contém um link não permitido",
+				Detail: fmt.Sprintf("Link para o domínio bloqueado %s", r.filter.Pattern),
+			}, nil
+		}
+	}
+	return nil, nil
+}
+
+// mask troca por espaços os links para o domínio liberado
+func (r domainRule) mask(text string) string {
+	var spans [][2]int
+	for _, loc := range linkRegexp.FindAllStringIndex(text, -1) {
+		if host := linkHost(text[loc[0]:loc[1]]); host != "" && r.matches(host) {
+			spans = append(spans, [2]int{loc[0], loc[1]})
+		}
+	}
+	return maskSpans(text, spans)
+}
+
+// exception é uma entrada que libera o trecho do texto com que casa
+type exception interface {
+	mask(text string) string
+}
+
+// Blocklist reúne as entradas das listas de bloqueio. As que permitem o
+// conteúdo são exceções: o trecho com que casam é retirado do texto antes das
+// demais entradas serem avaliadas, sem liberar o resto da publicação.
+type Blocklist struct {
+	exceptions []exception
+	rules      []Rule
+}
+
+// NewBlocklist compila as entradas das listas de bloqueio
+func NewBlocklist(filters []models.ContentFilter) (Blocklist, error) {
+	var blocklist Blocklist
+	for _, filter := range filters {
+		var rule interface {
+			Rule
+			exception
+		}
+		switch filter.Kind {
+		case models.FilterWord:
+			rule = patternRule{
+				filter:  filter,
+				pattern: regexp.MustCompile(`(?i)(^|[^\pL\pN_])(` + regexp.QuoteMeta(filter.Pattern) + `)($|[^\pL\pN_])`),
+			}
+		case models.FilterRegex:
+			pattern, err := regexp.Compile("(?i)" + filter.Pattern)
+			if err != nil {
+				return Blocklist{}, fmt.Errorf("filtro %d: %v", filter.ID, err)
+			}
+			rule = patternRule{filter: filter, pattern: pattern}
+		case models.FilterDomain:
+			rule = domainRule{filter: filter}
+		default:
+			continue
+		}
+
+		if filter.Action == models.FilterAllow {
+			blocklist.exceptions = append(blocklist.exceptions, rule)
+		} else {
+			blocklist.rules = append(blocklist.rules, rule)
+		}
+	}
+
+	return blocklist, nil
+}
+
+// Check avalia as entradas sobre o texto sem os trechos liberados pelas
+// exceções. Retorna a primeira rejeição ou, se não houver, a primeira marcação.
+func (b Blocklist) Check(input Input) (*Decision, error) {
+	for _, exception := range b.exceptions {
+		input.Title = exception.mask(input.Title)
+		input.Content = exception.mask(input.Content)
+	}
+
+	var flagged *Decision
+	for _, rule := range b.rules {
+		decision, err := rule.Check(input)
+		if err != nil {
+			return nil, err
+		}
+		if decision == nil {
+			continue
+		}
+		if decision.Action == models.FilterReject {
+			return decision, nil
+		}
+		if flagged == nil {
+			flagged = decision
+		}
+	}
+	return flagged, nil
+}
+
+// maskSpans troca por espaços os trechos informados, mantendo as posições
+func maskSpans(text string, spans [][2]int) string {
+	if len(spans) == 0 {
+		return text
+	}
+
+	masked := []byte(text)
+	for _, span := range spans {
+		for i := span[0]; i < span[1]; i++ {
+			masked[i] = ' '
+		}
+	}
+	return string(masked)
+}
+
+// linkHosts extrai os domínios dos links do texto, em minúsculas e sem www.
+func linkHosts(text string) []string {
+	var hosts []string
+	for _, link := range linkRegexp.FindAllString(text, -1) {
+		if host := linkHost(link); host != "" {
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts
+}
+
+// linkHost retorna o domínio do link, vazio quando não é possível extraí-lo
+func linkHost(link string) string {
+	if !strings.Contains(strings.ToLower(link), "://") {
+		link = "http://" + link
+	}
+	parsed, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(parsed.Hostname()), "www.")
+}
@@ -0,0 +1,64 @@
+package filter
+
+import (
+	"fmt"
+	"time"
+)
+
+// RepeatedChars se aplica quando um mesmo caractere aparece max vezes seguidas
+type RepeatedChars struct {
+	max    int
+	action string
+}
+
+func NewRepeatedChars(max int, action string) RepeatedChars {
+	return RepeatedChars{max: max, action: action}
+}
+
+func (r RepeatedChars) Check(input Input) (*Decision, error) {
+	for _, text := range []string{input.Title, input.Content} {
+		var previous rune
+		count := 0
+		for _, char := range text {
+			if char == previous {
+				count++
+			} else {
+				previous, count = char, 1
+			}
+			if count >= r.max && char != ' ' {
+				return &Decision{
+					Action: r.action,
+					Rule:   "repeated_chars",
+					Reason: fmt.Sprintf("Caractere repetido %d vezes seguidas", r.max),
+				}, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// DuplicateFinder indica se o autor já tem, desde since, outra publicação com o mesmo título e conteúdo
+type DuplicateFinder func(authorID, excludeID uint64, title, content string, since time.Time) (bool, error)
+
+// Duplicate se aplica quando o autor repete uma publicação dentro da janela
+type Duplicate struct {
+	find   DuplicateFinder
+	window time.Duration
+	action string
+}
+
+func NewDuplicate(find DuplicateFinder, window time.Duration, action string) Duplicate {
+	return Duplicate{find: find, window: window, action: action}
+}
+
+func (d Duplicate) Check(input Input) (*Decision, error) {
+	duplicated, err := d.find(input.AuthorID, input.PublishID, input.Title, input.Content, time.Now().Add(-d.window))
+	if err != nil || !duplicated {
+		return nil, err
+	}
+	return &Decision{
+		Action: d.action,
+		Rule:   "duplicate",
+		Reason: "Publicação repetida",
+	}, nil
+}
@@ -0,0 +1,92 @@
+package filter
+
+import (
+	"api/src/models"
+	"sync"
+)
+
+// Input é o conteúdo avaliado pelo filtro. PublishID é 0 na criação.
+type Input struct {
+	AuthorID  uint64
+	PublishID uint64
+	Title     string
+	Content   string
+}
+
+// Decision é o resultado do filtro. Action é uma das ações models.Filter*.
+// Reason é mostrado ao autor; Detail fica só para os moderadores, já que pode
+// revelar a entrada da lista de bloqueio que casou.
+type Decision struct {
+	Action string `json:"action"`
+	Rule   string `json:"rule,omitempty"`
+	Reason string `json:"reason,omitempty"`
+	Detail string `json:"-"`
+}
+
+// Rule é uma regra do filtro. Retorna nil quando a regra não se aplica ao conteúdo.
+type Rule interface {
+	Check(input Input) (*Decision, error)
+}
+
+// Pipeline avalia as regras em ordem. A primeira que rejeitar encerra a
+// avaliação; marcações para revisão são guardadas até o fim. Nenhuma regra
+// libera a publicação inteira: as exceções das listas de bloqueio valem só
+// para o trecho com que casam. As listas ficam antes das demais regras e podem
+// ser trocadas em tempo de execução com Reload.
+type Pipeline struct {
+	mutex     sync.RWMutex
+	blocklist Blocklist
+	rules     []Rule
+}
+
+func NewPipeline(rules ...Rule) *Pipeline {
+	return &Pipeline{rules: rules}
+}
+
+// Default é o filtro usado pela API, montado em main a partir da configuração
+var Default = NewPipeline()
+
+// Check avalia o conteúdo e retorna a decisão final, allow quando nenhuma regra se aplica
+func (p *Pipeline) Check(input Input) (Decision, error) {
+	p.mutex.RLock()
+	rules := append([]Rule{p.blocklist}, p.rules...)
+	p.mutex.RUnlock()
+
+	var flagged *Decision
+	for _, rule := range rules {
+		decision, err := rule.Check(input)
+		if err != nil {
+			return Decision{}, err
+		}
+		if decision == nil {
+			continue
+		}
+
+		switch decision.Action {
+		case models.FilterReject:
+			return *decision, nil
+		case models.FilterFlag:
+			if flagged == nil {
+				flagged = decision
+			}
+		}
+	}
+
+	if flagged != nil {
+		return *flagged, nil
+	}
+	return Decision{Action: models.FilterAllow}, nil
+}
+
+// Reload troca as listas de bloqueio pelas entradas informadas
+func (p *Pipeline) Reload(filters []models.ContentFilter) error {
+	blocklist, err := NewBlocklist(filters)
+	if err != nil {
+		return err
+	}
+
+	p.mutex.Lock()
+	p.blocklist = blocklist
+	p.mutex.Unlock()
+	return nil
+}
@@ -0,0 +1,197 @@
+package filter
+
+import (
+	"api/src/models"
+	"errors"
+	"testing"
+	"time"
+)
+
+var testFilters = []models.ContentFilter{
+	{ID: 1, Kind: models.FilterWord, Pattern: "scunthorpe", Action: models.FilterAllow},
+	{ID: 2, Kind: models.FilterRegex, Pattern: `c[u*]nt`, Action: models.FilterReject},
+	{ID: 3, Kind: models.FilterWord, Pattern: "spam", Action: models.FilterFlag},
+	{ID: 4, Kind: models.FilterDomain, Pattern: "bad.example", Action: models.FilterReject},
+	{ID: 5, Kind: models.FilterDomain, Pattern: "docs.bad.example", Action: models.FilterAllow},
+	{ID: 6, Kind: models.FilterWord, Pattern: "golpe", Action: models.FilterReject},
+	{ID: 7, Kind: models.FilterWord, Pattern: "golpe de mestre", Action: models.FilterAllow},
+	{ID: 8, Kind: models.FilterWord, Pattern: "oferta", Action: models.FilterFlag},
+}
+
+func newTestPipeline(t *testing.T, rules ...Rule) *Pipeline {
+	t.Helper()
+	pipeline := NewPipeline(rules...)
+	if err := pipeline.Reload(testFilters); err != nil {
+		t.Fatal(err)
+	}
+	return pipeline
+}
+
+func TestPipelineCheck(t *testing.T) {
+	pipeline := newTestPipeline(t, NewRepeatedChars(5, models.FilterFlag))
+
+	tests := []struct {
+		name    string
+		title   string
+		content string
+		action  string
+		rule    string
+	}{
+		{"nada casa", "Olá", "Bom dia a todos", models.FilterAllow, ""},
+		{"rejeição", "Olá", "que cunt", models.FilterReject, "regex:2"},
+		{"marcação", "Promoção", "isto não é spam", models.FilterFlag, "word:3"},
+		{"palavra só casa inteira", "Olá", "spammer e despam", models.FilterAllow, ""},
+		{"rejeição vence marcação anterior", "spam", "c*nt", models.FilterReject, "regex:2"},
+		{"primeira marcação é mantida", "spam", "oferta", models.FilterFlag, "word:3"},
+		{"exceção libera só o trecho", "Viagem", "Fui a Scunthorpe", models.FilterAllow, ""},
+		{"exceção em todas as ocorrências", "Scunthorpe", "scunthorpe Scunthorpe SCUNTHORPE", models.FilterAllow, ""},
+		{"exceção não libera o resto", "Viagem", "Scunthorpe, sua cunt", models.FilterReject, "regex:2"},
+		{"exceção não libera marcação", "Scunthorpe", "spam", models.FilterFlag, "word:3"},
+		{"exceção não libera heurística", "Scunthorpe", "aaaaaaah", models.FilterFlag, "repeated_chars"},
+		{"exceção de expressão", "Golpe de mestre", "que golpe de mestre", models.FilterAllow, ""},
+		{"palavra fora da exceção", "Golpe de mestre", "isso é golpe", models.FilterReject, "word:6"},
+		{"domínio bloqueado", "Link", "veja https://bad.example/x", models.FilterReject, "domain:4"},
+		{"subdomínio bloqueado", "Link", "veja www.promo.bad.example", models.FilterReject, "domain:4"},
+		{"subdomínio liberado", "Link", "veja https://docs.bad.example/guia", models.FilterAllow, ""},
+		{"link liberado não libera outro", "Link", "https://docs.bad.example e https://bad.example", models.FilterReject, "domain:4"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			decision, err := pipeline.Check(Input{Title: test.title, Content: test.content})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if decision.Action != test.action || decision.Rule != test.rule {
+				t.Errorf("decisão = %s %q, esperado %s %q", decision.Action, decision.Rule, test.action, test.rule)
+			}
+		})
+	}
+}
+
+func TestPipelineOrder(t *testing.T) {
+	var calls []string
+	rule := func(name, action string) Rule {
+		return ruleFunc(func(Input) (*Decision, error) {
+			calls = append(calls, name)
+			if action == "" {
+				return nil, nil
+			}
+			return &Decision{Action: action, Rule: name}, nil
+		})
+	}
+
+	tests := []struct {
+		name  string
+		rules []Rule
+		rule  string
+		calls []string
+	}{
+		{
+			name:  "rejeição encerra a avaliação",
+			rules: []Rule{rule("a", ""), rule("b", models.FilterReject), rule("c", models.FilterReject)},
+			rule:  "b",
+			calls: []string{"a", "b"},
+		},
+		{
+			name:  "marcação não encerra a avaliação",
+			rules: []Rule{rule("a", models.FilterFlag), rule("b", models.FilterFlag), rule("c", "")},
+			rule:  "a",
+			calls: []string{"a", "b", "c"},
+		},
+		{
+			name:  "allow de uma regra não libera as seguintes",
+			rules: []Rule{rule("a", models.FilterAllow), rule("b", models.FilterReject)},
+			rule:  "b",
+			calls: []string{"a", "b"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			calls = nil
+			decision, err := NewPipeline(test.rules...).Check(Input{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if decision.Rule != test.rule {
+				t.Errorf("regra = %q, esperado %q", decision.Rule, test.rule)
+			}
+			if len(calls) != len(test.calls) {
+				t.Fatalf("chamadas = %v, esperado %v", calls, test.calls)
+			}
+			for i := range calls {
+				if calls[i] != test.calls[i] {
+					t.Errorf("chamadas = %v, esperado %v", calls, test.calls)
+				}
+			}
+		})
+	}
+}
+
+func TestPipelineDuplicate(t *testing.T) {
+	var received Input
+	find := func(authorID, excludeID uint64, title, content string, since time.Time) (bool, error) {
+		received = Input{AuthorID: authorID, PublishID: excludeID, Title: title, Content: content}
+		return true, nil
+	}
+	pipeline := newTestPipeline(t, NewDuplicate(find, time.Hour, models.FilterReject))
+
+	// A exceção vale só para a lista de bloqueio: a busca por duplicadas
+	// recebe o texto original
+	input := Input{AuthorID: 1, PublishID: 2, Title: "Scunthorpe", Content: "de novo"}
+	decision, err := pipeline.Check(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decision.Action != models.FilterReject || decision.Rule != "duplicate" {
+		t.Errorf("decisão = %+v", decision)
+	}
+	if received != input {
+		t.Errorf("busca recebeu %+v, esperado %+v", received, input)
+	}
+
+	failure := errors.New("banco indisponível")
+	pipeline = NewPipeline(NewDuplicate(func(uint64, uint64, string, string, time.Time) (bool, error) {
+		return false, failure
+	}, time.Hour, models.FilterReject))
+	if _, err = pipeline.Check(input); err != failure {
+		t.Errorf("erro = %v, esperado %v", err, failure)
+	}
+}
+
+func TestBlocklistHidesPattern(t *testing.T) {
+	pipeline := newTestPipeline(t)
+
+	decision, err := pipeline.Check(Input{Title: "Olá", Content: "que golpe"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decision.Reason != "A publicação contém termos não permitidos" || decision.Detail != `Conteúdo contém "golpe"` {
+		t.Errorf("decisão = %+v", decision)
+	}
+}
+
+func TestReloadInvalidRegex(t *testing.T) {
+	pipeline := newTestPipeline(t)
+	err := pipeline.Reload([]models.ContentFilter{{ID: 9, Kind: models.FilterRegex, Pattern: "(", Action: models.FilterReject}})
+	if err == nil {
+		t.Fatal("a expressão inválida deveria ser recusada")
+	}
+
+	// A lista anterior continua valendo
+	decision, err := pipeline.Check(Input{Content: "cunt"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decision.Action != models.FilterReject {
+		t.Errorf("decisão = %+v", decision)
+	}
+}
+
+// ruleFunc adapta uma função à interface Rule
+type ruleFunc func(input Input) (*Decision, error)
+
+func (f ruleFunc) Check(input Input) (*Decision, error) {
+	return f(input)
+}
@@ -0,0 +1,133 @@
+package jobs
+
+import (
+	"api/src/models"
+	"api/src/scheduler"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+const (
+	batchSize   = 10
+	maxAttempts = 3
+	baseBackoff = time.Minute
+	lease       = 10 * time.Minute
+)
+
+// Handler executa um job e retorna o resultado a ser guardado nele
+type Handler func(job models.Job) (string, error)
+
+// Repository reserva os jobs a executar e grava o resultado de cada execução
+type Repository interface {
+	Claim(types []string, now time.Time, lease time.Duration, limit int) ([]models.Job, error)
+	Save(job models.Job) error
+}
+
+// Runner executa periodicamente os jobs enfileirados dos tipos registrados.
+// Um job que falha é tentado de novo com backoff exponencial até maxAttempts.
+type Runner struct {
+	repo     Repository
+	clock    scheduler.Clock
+	interval time.Duration
+
+	mutex    sync.RWMutex
+	handlers map[string]Handler
+}
+
+func NewRunner(repo Repository, clock scheduler.Clock, interval time.Duration) *Runner {
+	return &Runner{repo: repo, clock: clock, interval: interval, handlers: map[string]Handler{}}
+}
+
+// Register define o handler dos jobs do tipo informado
+func (r *Runner) Register(jobType string, handler Handler) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.handlers[jobType] = handler
+}
+
+// Run executa o runner indefinidamente, devendo ser chamado em uma goroutine
+func (r *Runner) Run() {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := r.Tick(); err != nil {
+			log.Println("jobs:", err)
+		}
+	}
+}
+
+// Tick executa, em lotes, todos os jobs que devem rodar até a hora atual do Clock
+func (r *Runner) Tick() error {
+	for {
+		claimed, err := r.repo.Claim(r.types(), r.clock.Now(), lease, batchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, job := range claimed {
+			r.execute(&job)
+			if err = r.repo.Save(job); err != nil {
+				log.Println("jobs:", err)
+			}
+		}
+
+		if len(claimed) < batchSize {
+			return nil
+		}
+	}
+}
+
+func (r *Runner) types() []string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	types := make([]string, 0, len(r.handlers))
+	for jobType := range r.handlers {
+		types = append(types, jobType)
+	}
+	return types
+}
+
+// execute roda o handler do job e atualiza a situação conforme o resultado
+func (r *Runner) execute(job *models.Job) {
+	r.mutex.RLock()
+	handler := r.handlers[job.Type]
+	r.mutex.RUnlock()
+
+	result, err := run(handler, *job)
+	now := r.clock.Now()
+	if err == nil {
+		job.Status = models.JobDone
+		job.Result = result
+		job.Error = ""
+		job.RunAfter = now
+		job.FinishedAt = &now
+		return
+	}
+
+	log.Printf("jobs: job %d (%s): %v", job.ID, job.Type, err)
+	job.Error = err.Error()
+
+	if job.Attempts >= maxAttempts {
+		job.Status = models.JobFailed
+		job.RunAfter = now
+		job.FinishedAt = &now
+		return
+	}
+
+	job.Status = models.JobPending
+	job.RunAfter = now.Add(baseBackoff << (job.Attempts - 1))
+}
+
+// run protege o runner de um panic no handler, tratando-o como falha
+func run(handler Handler, job models.Job) (result string, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("panic: %v", recovered)
+		}
+	}()
+	return handler(job)
+}
@@ -0,0 +1,150 @@
+package media
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+)
+
+const thumbnailSize = 320
+
+var (
+	ErrTooLarge    = errors.New("Arquivo excede o tamanho máximo permitido")
+	ErrUnsupported = errors.New("Formato de imagem não suportado, envie JPEG ou PNG")
+	ErrDimensions  = errors.New("Imagem excede as dimensões máximas permitidas")
+)
+
+// Limits são os limites aceitos para um upload. As dimensões são conferidas
+// pelo cabeçalho antes da decodificação, que aloca memória para todos os pixels.
+type Limits struct {
+	Size      int64
+	Dimension int
+	Pixels    int
+}
+
+// Image é o resultado do processamento de um upload
+type Image struct {
+	ContentType string
+	Data        []byte
+	Thumbnail   []byte
+	Width       int
+	Height      int
+}
+
+// Process identifica o tipo pelo conteúdo e recodifica a imagem, o que descarta
+// metadados como EXIF. Também gera uma miniatura de no máximo thumbnailSize pixels.
+func Process(data []byte, limits Limits) (Image, error) {
+	if int64(len(data)) > limits.Size {
+		return Image{}, ErrTooLarge
+	}
+
+	contentType := http.DetectContentType(data)
+	if contentType != "image/jpeg" && contentType != "image/png" {
+		return Image{}, ErrUnsupported
+	}
+
+	header, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return Image{}, ErrUnsupported
+	}
+	if header.Width <= 0 || header.Height <= 0 {
+		return Image{}, ErrUnsupported
+	}
+	if header.Width > limits.Dimension || header.Height > limits.Dimension ||
+		int64(header.Width)*int64(header.Height) > int64(limits.Pixels) {
+		return Image{}, ErrDimensions
+	}
+
+	decoded, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return Image{}, ErrUnsupported
+	}
+
+	encoded, err := encode(decoded, contentType)
+	if err != nil {
+		return Image{}, err
+	}
+
+	thumbnail, err := encode(resize(decoded, thumbnailSize), contentType)
+	if err != nil {
+		return Image{}, err
+	}
+
+	bounds := decoded.Bounds()
+	return Image{
+		ContentType: contentType,
+		Data:        encoded,
+		Thumbnail:   thumbnail,
+		Width:       bounds.Dx(),
+		Height:      bounds.Dy(),
+	}, nil
+}
+
+func encode(img image.Image, contentType string) ([]byte, error) {
+	var buffer bytes.Buffer
+	var err error
+	if contentType == "image/png" {
+		err = png.Encode(&buffer, img)
+	} else {
+		err = jpeg.Encode(&buffer, img, &jpeg.Options{Quality: 90})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// resize reduz a imagem para caber em um quadrado de size pixels, fazendo a
+// média dos pixels de origem que caem em cada pixel de destino
+func resize(src image.Image, size int) image.Image {
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= size && height <= size {
+		return src
+	}
+
+	newWidth, newHeight := size, size
+	if width > height {
+		newHeight = height * size / width
+	} else {
+		newWidth = width * size / height
+	}
+	if newWidth == 0 {
+		newWidth = 1
+	}
+	if newHeight == 0 {
+		newHeight = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, newWidth, newHeight))
+	for y := 0; y < newHeight; y++ {
+		y0 := bounds.Min.Y + y*height/newHeight
+		y1 := bounds.Min.Y + (y+1)*height/newHeight
+		for x := 0; x < newWidth; x++ {
+			x0 := bounds.Min.X + x*width/newWidth
+			x1 := bounds.Min.X + (x+1)*width/newWidth
+
+			var r, g, b, a, count uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					pr, pg, pb, pa := src.At(sx, sy).RGBA()
+					r, g, b, a = r+uint64(pr), g+uint64(pg), b+uint64(pb), a+uint64(pa)
+					count++
+				}
+			}
+			dst.Set(x, y, color.RGBA64{
+				R: uint16(r / count),
+				G: uint16(g / count),
+				B: uint16(b / count),
+				A: uint16(a / count),
+			})
+		}
+	}
+
+	return dst
+}
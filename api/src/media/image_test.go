@@ -0,0 +1,96 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+var testLimits = Limits{Size: 1 << 20, Dimension: 8192, Pixels: 40000000}
+
+func encodePNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+
+	var buffer bytes.Buffer
+	if err := png.Encode(&buffer, img); err != nil {
+		t.Fatal(err)
+	}
+	return buffer.Bytes()
+}
+
+// withPNGHeader reescreve as dimensões declaradas no IHDR, recalculando o CRC.
+// Os dados comprimidos continuam sendo os de uma imagem pequena.
+func withPNGHeader(data []byte, width, height uint32) []byte {
+	forged := append([]byte{}, data...)
+	// Assinatura (8) + tamanho do chunk (4) + "IHDR" (4)
+	binary.BigEndian.PutUint32(forged[16:], width)
+	binary.BigEndian.PutUint32(forged[20:], height)
+	binary.BigEndian.PutUint32(forged[29:], crc32.ChecksumIEEE(forged[12:29]))
+	return forged
+}
+
+func TestProcess(t *testing.T) {
+	img, err := Process(encodePNG(t, 640, 480), testLimits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.ContentType != "image/png" || img.Width != 640 || img.Height != 480 {
+		t.Errorf("imagem = %s %dx%d", img.ContentType, img.Width, img.Height)
+	}
+
+	thumbnail, err := png.DecodeConfig(bytes.NewReader(img.Thumbnail))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if thumbnail.Width != thumbnailSize || thumbnail.Height != 240 {
+		t.Errorf("miniatura = %dx%d", thumbnail.Width, thumbnail.Height)
+	}
+}
+
+func TestProcessRejects(t *testing.T) {
+	small := encodePNG(t, 4, 4)
+
+	var jpegBuffer bytes.Buffer
+	if err := jpeg.Encode(&jpegBuffer, image.NewGray(image.Rect(0, 0, 4, 4)), nil); err != nil {
+		t.Fatal(err)
+	}
+	// Troca as dimensões do SOF0 de um JPEG de 4x4
+	hugeJPEG := append([]byte{}, jpegBuffer.Bytes()...)
+	sof := bytes.Index(hugeJPEG, []byte{0xff, 0xc0})
+	binary.BigEndian.PutUint16(hugeJPEG[sof+5:], 60000)
+	binary.BigEndian.PutUint16(hugeJPEG[sof+7:], 60000)
+
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{"arquivo grande demais", make([]byte, testLimits.Size+1), ErrTooLarge},
+		{"não é imagem", []byte("texto qualquer"), ErrUnsupported},
+		{"largura acima do limite", withPNGHeader(small, 8193, 1), ErrDimensions},
+		{"altura acima do limite", withPNGHeader(small, 1, 8193), ErrDimensions},
+		{"pixels acima do limite", withPNGHeader(small, 8000, 8000), ErrDimensions},
+		{"cabeçalho gigante", withPNGHeader(small, 100000, 100000), ErrDimensions},
+		{"JPEG com cabeçalho gigante", hugeJPEG, ErrDimensions},
+		{"dimensões dentro do limite mas dados truncados", withPNGHeader(small, 100, 100), ErrUnsupported},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := Process(test.data, testLimits); err != test.want {
+				t.Errorf("erro = %v, esperado %v", err, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package media
+
+import (
+	"api/src/config"
+	"api/src/security"
+	"crypto/hmac"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// SignedURL monta o caminho para servir a chave, válido até expirar o TTL configurado
+func SignedURL(key string) string {
+	expires := time.Now().Add(config.MediaURLTTL).Unix()
+	return fmt.Sprintf("/media/%s?expires=%d&signature=%s", url.PathEscape(key), expires, signature(key, expires))
+}
+
+// VerifySignedURL confere a assinatura e a validade dos parâmetros de SignedURL
+func VerifySignedURL(key, expires, sig string) bool {
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > expiresAt {
+		return false
+	}
+
+	return hmac.Equal([]byte(sig), []byte(signature(key, expiresAt)))
+}
+
+func signature(key string, expires int64) string {
+	return security.Sign(string(config.SecretKey), []byte(fmt.Sprintf("%s:%d", key, expires)))
+}
@@ -0,0 +1,79 @@
+package media
+
+import (
+	"api/src/config"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignedURL(t *testing.T) {
+	config.SecretKey = []byte("segredo-de-teste")
+	config.MediaURLTTL = time.Hour
+
+	key := "publishes/1/foto original.png"
+	signed, err := url.Parse(SignedURL(key))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, _ := url.PathUnescape(strings.TrimPrefix(signed.EscapedPath(), "/media/")); got != key {
+		t.Errorf("chave = %q, esperado %q", got, key)
+	}
+
+	expires := signed.Query().Get("expires")
+	signature := signed.Query().Get("signature")
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ttl := time.Until(time.Unix(expiresAt, 0)); ttl <= 59*time.Minute || ttl > time.Hour {
+		t.Errorf("validade de %v, esperado o TTL configurado", ttl)
+	}
+
+	tests := []struct {
+		name      string
+		key       string
+		expires   string
+		signature string
+		want      bool
+	}{
+		{"URL assinada", key, expires, signature, true},
+		{"outra chave", "publishes/2/foto original.png", expires, signature, false},
+		{"validade alterada", key, strconv.FormatInt(expiresAt+3600, 10), signature, false},
+		{"assinatura alterada", key, expires, signature[:len(signature)-1] + "x", false},
+		{"sem assinatura", key, expires, "", false},
+		{"validade inválida", key, "amanhã", signature, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := VerifySignedURL(test.key, test.expires, test.signature); got != test.want {
+				t.Errorf("VerifySignedURL = %v, esperado %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestSignedURLExpired(t *testing.T) {
+	config.SecretKey = []byte("segredo-de-teste")
+
+	key := "exports/export_abc.zip"
+	expiresAt := time.Now().Add(-time.Second).Unix()
+	if VerifySignedURL(key, strconv.FormatInt(expiresAt, 10), signature(key, expiresAt)) {
+		t.Error("uma URL vencida não deveria ser aceita")
+	}
+}
+
+func TestSignedURLDependsOnSecret(t *testing.T) {
+	config.SecretKey = []byte("segredo-de-teste")
+	expiresAt := time.Now().Add(time.Hour).Unix()
+	sig := signature("chave", expiresAt)
+
+	config.SecretKey = []byte("outro-segredo")
+	if VerifySignedURL("chave", strconv.FormatInt(expiresAt, 10), sig) {
+		t.Error("a assinatura de outra chave secreta não deveria ser aceita")
+	}
+}
@@ -2,9 +2,14 @@ package middlewares
 
 import (
 	"api/src/authentication"
+	"api/src/database"
+	"api/src/repository"
 	"api/src/responses"
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
+	"time"
 )
 
 func Logger(next http.HandlerFunc) http.HandlerFunc {
@@ -16,10 +21,122 @@ func Logger(next http.HandlerFunc) http.HandlerFunc {
 
 func Authenticate(next http.HandlerFunc) http.HandlerFunc{
 	return func(w http.ResponseWriter, r *http.Request) {
+		if rawKey, ok := authentication.ExtractAPIKey(r); ok {
+			authenticateAPIKey(w, r, rawKey, next)
+			return
+		}
+
 		if err := authentication.ValidateToken(r); err != nil {
 			responses.Error(w, http.StatusUnauthorized, err)
 			return
 		}
+
+		// O token só vale enquanto a sessão em que foi emitido não for revogada
+		userID, err := authentication.ExtractUserIDFromToken(r)
+		if err != nil {
+			responses.Error(w, http.StatusUnauthorized, err)
+			return
+		}
+		sessionID, err := authentication.ExtractSessionIDFromToken(r)
+		if err != nil {
+			responses.Error(w, http.StatusUnauthorized, err)
+			return
+		}
+
+		db, err := database.Connect()
+		if err != nil {
+			responses.Error(w, http.StatusInternalServerError, err)
+			return
+		}
+		active, err := repository.NewSessionsRepository(db).Touch(sessionID, userID, time.Now())
+		db.Close()
+		if err != nil {
+			responses.Error(w, http.StatusInternalServerError, err)
+			return
+		}
+		if !active {
+			responses.Error(w, http.StatusUnauthorized, errors.New("Sessão encerrada, faça login novamente"))
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// authenticateAPIKey aceita a requisição feita com uma chave de API válida,
+// guardando a chave para a verificação de escopos
+func authenticateAPIKey(w http.ResponseWriter, r *http.Request, rawKey string, next http.HandlerFunc) {
+	db, err := database.Connect()
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	key, valid, err := repository.NewAPIKeysRepository(db).Authenticate(rawKey, time.Now())
+	db.Close()
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	if !valid {
+		responses.Error(w, http.StatusUnauthorized, errors.New("Chave de API inválida ou expirada"))
+		return
+	}
+
+	next(w, authentication.WithAPIKey(r, key))
+}
+
+// RequireScopes exige que a chave de API da requisição tenha os escopos
+// informados. Rotas sem escopos não aceitam chaves de API, e requisições com
+// JWT têm acesso a todos os escopos.
+func RequireScopes(scopes []string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key, ok := authentication.APIKeyFromRequest(r)
+		if !ok {
+			next(w, r)
+			return
+		}
+
+		if len(scopes) == 0 {
+			responses.Error(w, http.StatusForbidden, errors.New("Este recurso não pode ser acessado com chave de API"))
+			return
+		}
+		if !key.HasScopes(scopes) {
+			responses.Error(w, http.StatusForbidden, fmt.Errorf("A chave de API precisa dos escopos %s", strings.Join(scopes, ", ")))
+			return
+		}
+
 		next(w, r)
 	}
 }
+
+// Authorize permite a requisição apenas se o usuário autenticado tiver um dos papéis informados
+func Authorize(roles []string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, err := authentication.ExtractUserIDFromToken(r)
+		if err != nil {
+			responses.Error(w, http.StatusUnauthorized, err)
+			return
+		}
+
+		db, err := database.Connect()
+		if err != nil {
+			responses.Error(w, http.StatusInternalServerError, err)
+			return
+		}
+		role, err := repository.NewUsersRepository(db).GetRole(userID)
+		db.Close()
+		if err != nil {
+			responses.Error(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		for _, allowed := range roles {
+			if role == allowed {
+				next(w, r)
+				return
+			}
+		}
+
+		responses.Error(w, http.StatusForbidden, errors.New("Você não tem permissão para acessar este recurso"))
+	}
+}
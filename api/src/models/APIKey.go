@@ -0,0 +1,158 @@
+package models
+
+import (
+	"api/src/security"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// APIKeyPrefix identifica no cabeçalho Authorization uma chave de API em vez de um JWT
+const APIKeyPrefix = "dbk_"
+
+// Escopos que podem ser concedidos a uma chave de API
+const (
+	ScopeUsersRead          = "users:read"
+	ScopeUsersWrite         = "users:write"
+	ScopePublishesRead      = "publishes:read"
+	ScopePublishesWrite     = "publishes:write"
+	ScopeFollowsRead        = "follows:read"
+	ScopeFollowsWrite       = "follows:write"
+	ScopeNotificationsRead  = "notifications:read"
+	ScopeNotificationsWrite = "notifications:write"
+	ScopeBookmarksRead      = "bookmarks:read"
+	ScopeBookmarksWrite     = "bookmarks:write"
+	ScopeReportsWrite       = "reports:write"
+	ScopeWebhooksRead       = "webhooks:read"
+	ScopeWebhooksWrite      = "webhooks:write"
+	ScopeModeration         = "moderation"
+	ScopeAdmin              = "admin"
+)
+
+var Scopes = []string{
+	ScopeUsersRead,
+	ScopeUsersWrite,
+	ScopePublishesRead,
+	ScopePublishesWrite,
+	ScopeFollowsRead,
+	ScopeFollowsWrite,
+	ScopeNotificationsRead,
+	ScopeNotificationsWrite,
+	ScopeBookmarksRead,
+	ScopeBookmarksWrite,
+	ScopeReportsWrite,
+	ScopeWebhooksRead,
+	ScopeWebhooksWrite,
+	ScopeModeration,
+	ScopeAdmin,
+}
+
+// APIKey é uma credencial de bots e integrações, limitada aos escopos concedidos.
+// Apenas o hash da chave é guardado; Key só é preenchida na criação.
+type APIKey struct {
+	ID         uint64     `json:"id"`
+	UserID     uint64     `json:"-"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Key        string     `json:"key,omitempty"`
+	Hash       string     `json:"-"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func (k *APIKey) Prepare() error {
+	k.Name = strings.TrimSpace(k.Name)
+	if k.Name == "" {
+		return errors.New("O nome da chave é obrigatório")
+	}
+	if utf8.RuneCountInString(k.Name) > 50 {
+		return errors.New("O nome da chave deve ter no máximo 50 caracteres")
+	}
+
+	if len(k.Scopes) == 0 {
+		return errors.New("Informe ao menos um escopo")
+	}
+	seen := map[string]bool{}
+	scopes := make([]string, 0, len(k.Scopes))
+	for _, scope := range k.Scopes {
+		scope = strings.TrimSpace(scope)
+		if !validScope(scope) {
+			return errors.New("Escopo inválido: " + scope)
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+	k.Scopes = scopes
+
+	if k.ExpiresAt != nil && !k.ExpiresAt.After(time.Now()) {
+		return errors.New("A data de expiração deve estar no futuro")
+	}
+	return nil
+}
+
+// HasScopes indica se a chave tem todos os escopos exigidos
+func (k APIKey) HasScopes(required []string) bool {
+	for _, scope := range required {
+		found := false
+		for _, granted := range k.Scopes {
+			if granted == scope {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Generate sorteia a chave, no formato dbk_<prefixo>_<segredo>, e preenche o
+// prefixo usado na busca e o hash guardado no banco
+func (k *APIKey) Generate() error {
+	prefix, err := security.RandomToken(6)
+	if err != nil {
+		return err
+	}
+	secret, err := security.RandomToken(32)
+	if err != nil {
+		return err
+	}
+
+	k.Prefix = prefix
+	k.Key = APIKeyPrefix + prefix + "_" + secret
+	k.Hash = HashAPIKey(k.Key)
+	return nil
+}
+
+// HashAPIKey calcula o hash guardado da chave. As chaves são aleatórias e
+// longas, então um hash rápido é suficiente.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// ParseAPIKeyPrefix extrai o prefixo de busca de uma chave dbk_<prefixo>_<segredo>
+func ParseAPIKeyPrefix(key string) (string, bool) {
+	parts := strings.Split(strings.TrimPrefix(key, APIKeyPrefix), "_")
+	if !strings.HasPrefix(key, APIKeyPrefix) || len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", false
+	}
+	return parts[0], true
+}
+
+func validScope(scope string) bool {
+	for _, valid := range Scopes {
+		if scope == valid {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Ações registradas no log de auditoria
+const (
+	AuditLoginSucceeded  = "login.succeeded"
+	AuditLoginFailed     = "login.failed"
+	AuditSessionRevoked  = "session.revoked"
+	AuditAPIKeyCreated   = "api_key.created"
+	AuditAPIKeyRevoked   = "api_key.revoked"
+	AuditPasswordChanged = "password.changed"
+	AuditEmailChanged    = "user.email_changed"
+	AuditNickChanged     = "user.nick_changed"
+	AuditUserDeleted     = "user.deleted"
+	AuditUserDeactivated = "user.deactivated"
+	AuditUserImported    = "user.imported"
+	AuditRoleChanged     = "user.role_changed"
+	AuditModeration      = "moderation.action"
+)
+
+// AuditEntry é um registro do log de auditoria. Cada registro guarda o hash do
+// anterior, formando uma cadeia em que qualquer alteração fica evidente.
+type AuditEntry struct {
+	ID        uint64            `json:"id"`
+	ActorID   uint64            `json:"actor_id,omitempty"`
+	TargetID  uint64            `json:"target_id,omitempty"`
+	Action    string            `json:"action"`
+	IP        string            `json:"ip,omitempty"`
+	UserAgent string            `json:"user_agent,omitempty"`
+	Details   map[string]string `json:"details,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+	PrevHash  string            `json:"prev_hash"`
+	Hash      string            `json:"hash"`
+}
+
+// AuditFilter restringe a consulta do log; campos vazios não filtram
+type AuditFilter struct {
+	ActorID  uint64
+	TargetID uint64
+	Action   string
+	From     time.Time
+	To       time.Time
+}
+
+// AuditVerification é o resultado da conferência da cadeia de hashes
+type AuditVerification struct {
+	Checked uint64 `json:"checked"`
+	Valid   bool   `json:"valid"`
+	// Primeiro registro cujo hash não confere, quando a cadeia foi adulterada
+	BrokenID uint64 `json:"broken_id,omitempty"`
+}
+
+// ComputeHash calcula o hash do registro encadeado ao hash anterior. details
+// é o JSON dos detalhes exatamente como gravado.
+func (e AuditEntry) ComputeHash(details string) string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		e.PrevHash,
+		strconv.FormatUint(e.ActorID, 10),
+		strconv.FormatUint(e.TargetID, 10),
+		e.Action,
+		e.IP,
+		e.UserAgent,
+		details,
+		strconv.FormatInt(e.CreatedAt.Unix(), 10),
+	}, "\n")))
+	return hex.EncodeToString(sum[:])
+}
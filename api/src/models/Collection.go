@@ -0,0 +1,33 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Collection agrupa os itens salvos de um usuário
+type Collection struct {
+	ID        uint64    `json:"id,omitempty"`
+	UserID    uint64    `json:"user_id,omitempty"`
+	Name      string    `json:"name,omitempty"`
+	Bookmarks uint64    `json:"bookmarks"`
+	CreatedAt time.Time `json:"created_at,omitempty"`
+}
+
+// Bookmark é o corpo opcional ao salvar uma publicação
+type Bookmark struct {
+	CollectionID uint64 `json:"collection_id,omitempty"`
+}
+
+func (c *Collection) Prepare() error {
+	c.Name = strings.TrimSpace(c.Name)
+	if c.Name == "" {
+		return errors.New("O campo nome é obrigatório")
+	}
+	if utf8.RuneCountInString(c.Name) > 50 {
+		return errors.New("O nome deve ter no máximo 50 caracteres")
+	}
+	return nil
+}
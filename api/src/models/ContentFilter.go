@@ -0,0 +1,64 @@
+package models
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Tipos de entrada das listas de bloqueio do filtro de conteúdo
+const (
+	FilterWord   = "word"
+	FilterRegex  = "regex"
+	FilterDomain = "domain"
+)
+
+// Ações de uma regra do filtro quando ela casa com a publicação
+const (
+	FilterAllow  = "allow"
+	FilterFlag   = "flag"
+	FilterReject = "reject"
+)
+
+// ContentFilter é uma entrada das listas de bloqueio mantidas pelos administradores
+type ContentFilter struct {
+	ID        uint64    `json:"id,omitempty"`
+	Kind      string    `json:"kind,omitempty"`
+	Pattern   string    `json:"pattern,omitempty"`
+	Action    string    `json:"action,omitempty"`
+	CreatedAt time.Time `json:"created_at,omitempty"`
+}
+
+func (f *ContentFilter) Prepare() error {
+	f.Pattern = strings.TrimSpace(f.Pattern)
+	if f.Pattern == "" {
+		return errors.New("O campo pattern é obrigatório")
+	}
+	if utf8.RuneCountInString(f.Pattern) > 255 {
+		return errors.New("O pattern deve ter no máximo 255 caracteres")
+	}
+
+	switch f.Kind {
+	case FilterWord:
+	case FilterRegex:
+		if _, err := regexp.Compile(f.Pattern); err != nil {
+			return errors.New("Expressão regular inválida")
+		}
+	case FilterDomain:
+		f.Pattern = strings.TrimPrefix(strings.ToLower(f.Pattern), "www.")
+	default:
+		return errors.New("Tipo de filtro inválido")
+	}
+
+	if !ValidFilterAction(f.Action) {
+		return errors.New("Ação do filtro inválida")
+	}
+	return nil
+}
+
+// ValidFilterAction verifica se a ação informada é conhecida
+func ValidFilterAction(action string) bool {
+	return action == FilterAllow || action == FilterFlag || action == FilterReject
+}
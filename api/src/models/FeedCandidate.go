@@ -0,0 +1,10 @@
+package models
+
+// FeedCandidate é uma publicação elegível para o feed ranqueado, com os sinais usados na pontuação
+type FeedCandidate struct {
+	Publish      Publish
+	SecondDegree bool
+	Comments     uint64
+	Affinity     uint64
+	Score        float64
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Identity liga uma conta externa, de um provedor OpenID Connect, a um usuário
+type Identity struct {
+	Provider  string    `json:"provider"`
+	Subject   string    `json:"-"`
+	UserID    uint64    `json:"-"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// OAuthState guarda, entre o redirecionamento e o retorno do provedor, os
+// valores que só o servidor pode conhecer
+type OAuthState struct {
+	State     string
+	Provider  string
+	Verifier  string
+	Nonce     string
+	ExpiresAt time.Time
+}
+
+var nickInvalidChars = regexp.MustCompile(`[^a-z0-9_]+`)
+
+// NickBase sugere um nick a partir do nome de usuário do provedor ou, sem ele,
+// da parte local do e-mail
+func NickBase(preferredUsername, email string) string {
+	base := preferredUsername
+	if base == "" {
+		base = strings.SplitN(email, "@", 2)[0]
+	}
+
+	base = nickInvalidChars.ReplaceAllString(strings.ToLower(base), "_")
+	base = strings.Trim(base, "_")
+	if len(base) > 40 {
+		base = base[:40]
+	}
+	if base == "" {
+		base = "usuario"
+	}
+	return base
+}
@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// Situações de um job em segundo plano
+const (
+	JobPending = "pending"
+	JobRunning = "running"
+	JobDone    = "done"
+	JobFailed  = "failed"
+)
+
+// Tipos de job conhecidos
+const (
+	JobExport       = "export"
+	JobExportExpire = "export.expire"
+)
+
+// Job é uma tarefa executada em segundo plano pelo runner de jobs
+type Job struct {
+	ID         uint64     `json:"id"`
+	Type       string     `json:"type"`
+	UserID     uint64     `json:"user_id,omitempty"`
+	Status     string     `json:"status"`
+	Payload    string     `json:"-"`
+	Result     string     `json:"-"`
+	Error      string     `json:"error,omitempty"`
+	Attempts   uint64     `json:"attempts"`
+	RunAfter   time.Time  `json:"run_after"`
+	CreatedAt  time.Time  `json:"created_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+
+	// Preenchidos apenas na resposta de exportações concluídas
+	DownloadURL string     `json:"download_url,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+}
+
+// IsFinished indica se o job não será mais executado
+func (j Job) IsFinished() bool {
+	return j.Status == JobDone || j.Status == JobFailed
+}
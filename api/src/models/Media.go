@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+type Media struct {
+	ID           uint64    `json:"id,omitempty"`
+	UserID       uint64    `json:"user_id,omitempty"`
+	Key          string    `json:"-"`
+	ThumbnailKey string    `json:"-"`
+	ContentType  string    `json:"content_type,omitempty"`
+	Size         int       `json:"size,omitempty"`
+	Width        int       `json:"width,omitempty"`
+	Height       int       `json:"height,omitempty"`
+	URL          string    `json:"url,omitempty"`
+	ThumbnailURL string    `json:"thumbnail_url,omitempty"`
+	CreatedAt    time.Time `json:"created_at,omitempty"`
+}
@@ -0,0 +1,68 @@
+package models
+
+import "time"
+
+// Tipos de notificação suportados
+const (
+	NotificationFollow = "follow"
+	NotificationLike   = "like"
+	// Resposta a uma publicação do usuário
+	NotificationComment = "comment"
+	NotificationMention = "mention"
+	// Pedido para seguir uma conta privada
+	NotificationFollowRequest = "follow_request"
+	// Pedido para seguir aprovado pela conta privada
+	NotificationFollowAccepted = "follow_accepted"
+	// Resultado de uma denúncia feita pelo usuário
+	NotificationReportActioned  = "report_actioned"
+	NotificationReportDismissed = "report_dismissed"
+)
+
+// NotificationTypes lista todos os tipos que podem ser configurados nas preferências
+var NotificationTypes = []string{
+	NotificationFollow,
+	NotificationLike,
+	NotificationComment,
+	NotificationMention,
+	NotificationFollowRequest,
+	NotificationFollowAccepted,
+	NotificationReportActioned,
+	NotificationReportDismissed,
+}
+
+// Notification sem ActorID é do sistema, como o resultado de uma denúncia,
+// em que o moderador não é revelado
+type Notification struct {
+	ID        uint64    `json:"id,omitempty"`
+	UserID    uint64    `json:"user_id,omitempty"`
+	ActorID   uint64    `json:"actor_id,omitempty"`
+	ActorNick string    `json:"actor_nick,omitempty"`
+	Type      string    `json:"type,omitempty"`
+	PublishID uint64    `json:"publish_id,omitempty"`
+	ReportID  uint64    `json:"report_id,omitempty"`
+	Read      bool      `json:"read"`
+	CreatedAt time.Time `json:"created_at,omitempty"`
+}
+
+// NotificationPage é a resposta paginada da listagem de notificações
+type NotificationPage struct {
+	Notifications []Notification `json:"notifications"`
+	Unread        uint64         `json:"unread"`
+	Page          uint64         `json:"page"`
+	Limit         uint64         `json:"limit"`
+}
+
+type NotificationPreference struct {
+	Type    string `json:"type"`
+	Enabled bool   `json:"enabled"`
+}
+
+// ValidNotificationType verifica se o tipo informado é conhecido
+func ValidNotificationType(notificationType string) bool {
+	for _, t := range NotificationTypes {
+		if t == notificationType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,120 @@
+package models
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	minPollOptions  = 2
+	maxPollOptions  = 4
+	maxPollDuration = 7 * 24 * time.Hour
+)
+
+// Poll é a enquete opcional de uma publicação. Votes, Percentage e Voters só
+// são preenchidos depois que quem consulta vota ou a enquete encerra.
+type Poll struct {
+	ID        uint64       `json:"id,omitempty"`
+	Options   []PollOption `json:"options"`
+	Multiple  bool         `json:"multiple"`
+	ExpiresAt time.Time    `json:"expires_at"`
+	Closed    bool         `json:"closed"`
+	Voted     bool         `json:"voted"`
+	Choices   []uint64     `json:"choices,omitempty"`
+	Voters    *uint64      `json:"voters,omitempty"`
+}
+
+type PollOption struct {
+	ID         uint64   `json:"id,omitempty"`
+	Text       string   `json:"text"`
+	Votes      *uint64  `json:"votes,omitempty"`
+	Percentage *float64 `json:"percentage,omitempty"`
+}
+
+// PollVote é o corpo do voto, com os IDs das opções escolhidas
+type PollVote struct {
+	Choices []uint64 `json:"choices"`
+}
+
+func (p *Poll) Prepare() error {
+	if len(p.Options) < minPollOptions || len(p.Options) > maxPollOptions {
+		return errors.New("A enquete deve ter de 2 a 4 opções")
+	}
+
+	for i := range p.Options {
+		p.Options[i].Text = strings.TrimSpace(p.Options[i].Text)
+		if p.Options[i].Text == "" {
+			return errors.New("As opções da enquete não podem ser vazias")
+		}
+		if utf8.RuneCountInString(p.Options[i].Text) > 50 {
+			return errors.New("As opções da enquete devem ter no máximo 50 caracteres")
+		}
+	}
+
+	if !p.ExpiresAt.After(time.Now()) {
+		return errors.New("O encerramento da enquete precisa estar no futuro")
+	}
+	if p.ExpiresAt.After(time.Now().Add(maxPollDuration)) {
+		return errors.New("A enquete pode durar no máximo 7 dias")
+	}
+
+	return nil
+}
+
+// ValidateVote verifica se as opções escolhidas pertencem à enquete e
+// respeitam o tipo de escolha, única ou múltipla
+func (p *Poll) ValidateVote(vote PollVote) error {
+	if len(vote.Choices) == 0 {
+		return errors.New("Escolha ao menos uma opção")
+	}
+	if !p.Multiple && len(vote.Choices) > 1 {
+		return errors.New("Esta enquete aceita apenas uma opção")
+	}
+
+	options := map[uint64]bool{}
+	for _, option := range p.Options {
+		options[option.ID] = true
+	}
+
+	chosen := map[uint64]bool{}
+	for _, choice := range vote.Choices {
+		if !options[choice] {
+			return errors.New("Opção inválida para esta enquete")
+		}
+		if chosen[choice] {
+			return errors.New("Uma opção não pode ser escolhida mais de uma vez")
+		}
+		chosen[choice] = true
+	}
+
+	return nil
+}
+
+// Tally define Closed e calcula os percentuais sobre o número de votantes,
+// escondendo os resultados enquanto quem consulta não votou e a enquete não encerrou
+func (p *Poll) Tally(now time.Time) {
+	p.Closed = !now.Before(p.ExpiresAt)
+	if !p.Voted && !p.Closed {
+		p.Voters = nil
+		for i := range p.Options {
+			p.Options[i].Votes = nil
+			p.Options[i].Percentage = nil
+		}
+		return
+	}
+
+	var voters uint64
+	if p.Voters != nil {
+		voters = *p.Voters
+	}
+	for i := range p.Options {
+		percentage := 0.0
+		if p.Options[i].Votes != nil && voters > 0 {
+			percentage = math.Round(float64(*p.Options[i].Votes)*1000/float64(voters)) / 10
+		}
+		p.Options[i].Percentage = &percentage
+	}
+}
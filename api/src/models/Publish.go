@@ -2,20 +2,47 @@ package models
 
 import (
 	"errors"
+	"regexp"
 	"strings"
 	"time"
 )
 
+var (
+	mentionRegexp = regexp.MustCompile(`@(\w+)`)
+	hashtagRegexp = regexp.MustCompile(`#(\pL[\pL\pN_]*)`)
+)
+
 type Publish struct {
-	ID         uint64    `json:"id,omitempty"`
-	Title      string    `json:"title,omitempty"`
-	Content    string    `json:"content,omitempty"`
-	AuthorID   uint64    `json:"author_id,omitempty"`
-	AuthorNick string    `json:"author_nick,omitempty"`
-	Likes      uint64    `json:"likes"`
-	CreatedAt  time.Time `json:"created_at,omitempty"`
+	ID         uint64     `json:"id,omitempty"`
+	Title      string     `json:"title,omitempty"`
+	Content    string     `json:"content,omitempty"`
+	AuthorID   uint64     `json:"author_id,omitempty"`
+	AuthorNick string     `json:"author_nick,omitempty"`
+	Likes      uint64     `json:"likes"`
+	CreatedAt  time.Time  `json:"created_at,omitempty"`
+	MediaIDs   []uint64   `json:"media_ids,omitempty"`
+	Media      []Media    `json:"media,omitempty"`
+	Bookmarked bool       `json:"bookmarked"`
+	Status     string     `json:"status,omitempty"`
+	PublishAt  *time.Time `json:"publish_at,omitempty"`
+	ParentID   uint64     `json:"parent_id,omitempty"`
+	Parent     *Publish   `json:"parent,omitempty"`
+	ReplyCount uint64     `json:"reply_count"`
+	Poll       *Poll      `json:"poll,omitempty"`
 }
 
+// Estados de uma publicação. Rascunhos e agendadas só são vistas pelo autor.
+// Publicações apagadas que têm respostas viram deleted, mantendo a thread.
+const (
+	PublishDraft     = "draft"
+	PublishScheduled = "scheduled"
+	PublishPublished = "published"
+	PublishDeleted   = "deleted"
+)
+
+// Quantidade máxima de mídias por publicação
+const maxPublishMedia = 4
+
 func (p *Publish) Prepare() error {
 	p.format()
 	if err := p.validate(); err != nil {
@@ -27,6 +54,12 @@ func (p *Publish) Prepare() error {
 func (p *Publish) format() {
 	p.Title = strings.TrimSpace(p.Title)
 	p.Content = strings.TrimSpace(p.Content)
+	if p.Status == "" {
+		p.Status = PublishPublished
+		if p.PublishAt != nil {
+			p.Status = PublishScheduled
+		}
+	}
 }
 
 func (p *Publish) validate() error {
@@ -36,5 +69,92 @@ func (p *Publish) validate() error {
 	if p.Content == "" {
 		return errors.New("Campo conteúdo é obrigatório")
 	}
+	if len(p.MediaIDs) > maxPublishMedia {
+		return errors.New("Uma publicação pode ter no máximo 4 mídias")
+	}
+	for i, mediaID := range p.MediaIDs {
+		for _, other := range p.MediaIDs[:i] {
+			if mediaID == other {
+				return errors.New("A mesma mídia foi informada mais de uma vez")
+			}
+		}
+	}
+	switch p.Status {
+	case PublishDraft, PublishPublished:
+		p.PublishAt = nil
+	case PublishScheduled:
+		if err := ValidatePublishAt(p.PublishAt); err != nil {
+			return err
+		}
+	default:
+		return errors.New("Estado da publicação inválido")
+	}
+	if p.Poll != nil {
+		if err := p.Poll.Prepare(); err != nil {
+			return err
+		}
+		if p.PublishAt != nil && !p.Poll.ExpiresAt.After(*p.PublishAt) {
+			return errors.New("A enquete precisa encerrar depois da data de publicação")
+		}
+	}
+	return nil
+}
+
+// ValidatePublishAt exige uma data de publicação no futuro
+func ValidatePublishAt(publishAt *time.Time) error {
+	if publishAt == nil {
+		return errors.New("Campo publish_at é obrigatório para agendar")
+	}
+	if !publishAt.After(time.Now()) {
+		return errors.New("A data de publicação precisa estar no futuro")
+	}
 	return nil
 }
+
+// IsDraft indica se a publicação ainda pode ser agendada ou publicada
+func (p *Publish) IsDraft() bool {
+	return p.Status == PublishDraft || p.Status == PublishScheduled
+}
+
+// Tombstone é o que aparece no lugar de uma publicação apagada ou que quem
+// consulta não pode ver, mantendo apenas a posição na thread
+func (p *Publish) Tombstone() Publish {
+	return Publish{
+		ID:         p.ID,
+		ParentID:   p.ParentID,
+		Status:     PublishDeleted,
+		CreatedAt:  p.CreatedAt,
+		ReplyCount: p.ReplyCount,
+	}
+}
+
+// IsPublished indica se a publicação já está visível para os outros usuários
+func (p *Publish) IsPublished() bool {
+	return p.Status == PublishPublished
+}
+
+// Mentions retorna os nicks mencionados com @ no conteúdo, sem repetição
+func (p *Publish) Mentions() []string {
+	return uniqueMatches(mentionRegexp, p.Content, false)
+}
+
+// Hashtags retorna as hashtags do título e do conteúdo em minúsculas, sem repetição
+func (p *Publish) Hashtags() []string {
+	return uniqueMatches(hashtagRegexp, p.Title+" "+p.Content, true)
+}
+
+func uniqueMatches(expression *regexp.Regexp, text string, lower bool) []string {
+	var values []string
+	seen := map[string]bool{}
+	for _, match := range expression.FindAllStringSubmatch(text, -1) {
+		value := match[1]
+		if lower {
+			value = strings.ToLower(value)
+		}
+		if !seen[value] {
+			seen[value] = true
+			values = append(values, value)
+		}
+	}
+	return values
+}
@@ -0,0 +1,12 @@
+package models
+
+// Relationship descreve a relação de um usuário com outro
+type Relationship struct {
+	UserID     uint64 `json:"user_id"`
+	TargetID   uint64 `json:"target_id"`
+	Following  bool   `json:"following"`
+	FollowedBy bool   `json:"followed_by"`
+	Requested  bool   `json:"requested"`
+	Blocked    bool   `json:"blocked"`
+	Muted      bool   `json:"muted"`
+}
@@ -0,0 +1,119 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Motivos aceitos nas denúncias
+const (
+	ReportSpam           = "spam"
+	ReportHarassment     = "harassment"
+	ReportHate           = "hate"
+	ReportViolence       = "violence"
+	ReportNudity         = "nudity"
+	ReportMisinformation = "misinformation"
+	ReportImpersonation  = "impersonation"
+	ReportOther          = "other"
+	// Denúncias abertas pelo filtro de conteúdo, sem um usuário denunciante
+	ReportAutomated = "automated"
+)
+
+var ReportReasons = []string{
+	ReportSpam,
+	ReportHarassment,
+	ReportHate,
+	ReportViolence,
+	ReportNudity,
+	ReportMisinformation,
+	ReportImpersonation,
+	ReportOther,
+}
+
+// Alvos de uma denúncia
+const (
+	ReportTargetPublish = "publish"
+	ReportTargetUser    = "user"
+)
+
+// Estados de uma denúncia na fila de moderação
+const (
+	ReportOpen      = "open"
+	ReportClaimed   = "claimed"
+	ReportResolved  = "resolved"
+	ReportDismissed = "dismissed"
+)
+
+// Ações de moderação sobre uma denúncia
+const (
+	ModerationClaim         = "claim"
+	ModerationResolve       = "resolve"
+	ModerationDismiss       = "dismiss"
+	ModerationRemoveContent = "remove_content"
+)
+
+type Report struct {
+	ID           uint64             `json:"id,omitempty"`
+	ReporterID   uint64             `json:"reporter_id,omitempty"`
+	ReporterNick string             `json:"reporter_nick,omitempty"`
+	TargetType   string             `json:"target_type,omitempty"`
+	TargetUserID uint64             `json:"target_user_id,omitempty"`
+	PublishID    uint64             `json:"publish_id,omitempty"`
+	Reason       string             `json:"reason,omitempty"`
+	Details      string             `json:"details,omitempty"`
+	Status       string             `json:"status,omitempty"`
+	ModeratorID  uint64             `json:"moderator_id,omitempty"`
+	CreatedAt    time.Time          `json:"created_at,omitempty"`
+	UpdatedAt    time.Time          `json:"updated_at,omitempty"`
+	Actions      []ModerationAction `json:"actions,omitempty"`
+}
+
+// ModerationAction registra o que um moderador fez em uma denúncia e por quê
+type ModerationAction struct {
+	ID            uint64    `json:"id,omitempty"`
+	ReportID      uint64    `json:"report_id,omitempty"`
+	ModeratorID   uint64    `json:"moderator_id,omitempty"`
+	ModeratorNick string    `json:"moderator_nick,omitempty"`
+	Action        string    `json:"action,omitempty"`
+	Reason        string    `json:"reason,omitempty"`
+	CreatedAt     time.Time `json:"created_at,omitempty"`
+}
+
+func (r *Report) Prepare() error {
+	r.Reason = strings.TrimSpace(r.Reason)
+	r.Details = strings.TrimSpace(r.Details)
+
+	valid := false
+	for _, reason := range ReportReasons {
+		if reason == r.Reason {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return errors.New("Motivo da denúncia inválido")
+	}
+	if utf8.RuneCountInString(r.Details) > 500 {
+		return errors.New("Os detalhes devem ter no máximo 500 caracteres")
+	}
+	return nil
+}
+
+// Prepare exige o motivo em toda ação que encerra uma denúncia
+func (a *ModerationAction) Prepare() error {
+	a.Reason = strings.TrimSpace(a.Reason)
+	if a.Reason == "" && a.Action != ModerationClaim {
+		return errors.New("Informe o motivo da ação")
+	}
+	if utf8.RuneCountInString(a.Reason) > 500 {
+		return errors.New("O motivo deve ter no máximo 500 caracteres")
+	}
+	return nil
+}
+
+// IsOpen indica se a denúncia ainda aguarda uma decisão
+func (r *Report) IsOpen() bool {
+	return r.Status == ReportOpen || r.Status == ReportClaimed
+}
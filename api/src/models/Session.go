@@ -0,0 +1,68 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Session é um login do usuário em um dispositivo
+type Session struct {
+	ID         uint64     `json:"id"`
+	UserID     uint64     `json:"-"`
+	Device     string     `json:"device"`
+	UserAgent  string     `json:"user_agent"`
+	IP         string     `json:"ip"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastSeenAt time.Time  `json:"last_seen_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	RevokedAt  *time.Time `json:"-"`
+	// Current indica a sessão usada na própria requisição
+	Current bool `json:"current"`
+}
+
+// Navegadores e sistemas reconhecidos, na ordem em que são procurados no user agent
+var (
+	sessionBrowsers = []struct{ token, name string }{
+		{"Edg/", "Edge"},
+		{"OPR/", "Opera"},
+		{"Firefox/", "Firefox"},
+		{"Chrome/", "Chrome"},
+		{"Safari/", "Safari"},
+		{"curl/", "curl"},
+	}
+	sessionSystems = []struct{ token, name string }{
+		{"Android", "Android"},
+		{"iPhone", "iOS"},
+		{"iPad", "iPadOS"},
+		{"Windows", "Windows"},
+		{"Mac OS X", "macOS"},
+		{"Linux", "Linux"},
+	}
+)
+
+// DeviceName descreve o dispositivo a partir do user agent, como "Firefox no Linux"
+func DeviceName(userAgent string) string {
+	browser, system := "", ""
+	for _, candidate := range sessionBrowsers {
+		if strings.Contains(userAgent, candidate.token) {
+			browser = candidate.name
+			break
+		}
+	}
+	for _, candidate := range sessionSystems {
+		if strings.Contains(userAgent, candidate.token) {
+			system = candidate.name
+			break
+		}
+	}
+
+	switch {
+	case browser != "" && system != "":
+		return browser + " no " + system
+	case browser != "":
+		return browser
+	case system != "":
+		return system
+	}
+	return "Dispositivo desconhecido"
+}
@@ -0,0 +1,9 @@
+package models
+
+// Suggestion é um usuário sugerido para seguir, com os motivos da sugestão
+type Suggestion struct {
+	User           User     `json:"user"`
+	Score          float64  `json:"score"`
+	MutualCount    int      `json:"mutual_count"`
+	SharedHashtags []string `json:"shared_hashtags,omitempty"`
+}
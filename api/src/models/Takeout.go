@@ -0,0 +1,30 @@
+package models
+
+// Takeout é o conteúdo de um arquivo de exportação de dados usado na importação
+type Takeout struct {
+	Profile   User
+	Publishes []Publish
+	Comments  []Publish
+	Followers []User
+	Following []User
+}
+
+// ImportReport descreve o resultado, ou em dry-run o plano, de uma importação
+type ImportReport struct {
+	DryRun    bool     `json:"dry_run"`
+	UserID    uint64   `json:"user_id,omitempty"`
+	Nick      string   `json:"nick"`
+	Conflicts []string `json:"conflicts"`
+	Publishes int      `json:"publishes"`
+	Comments  int      `json:"comments"`
+	// Respostas a publicações que não estão no arquivo não são importadas
+	SkippedComments int      `json:"skipped_comments"`
+	Followers       int      `json:"followers"`
+	Following       int      `json:"following"`
+	MissingUsers    []string `json:"missing_users"`
+	// Senha gerada quando nenhuma é informada na importação
+	TemporaryPassword string `json:"temporary_password,omitempty"`
+
+	// Publicações criadas, para serem indexadas na busca
+	Imported []Publish `json:"-"`
+}
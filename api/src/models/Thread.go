@@ -0,0 +1,38 @@
+package models
+
+// Thread é a conversa em torno de uma publicação: as publicações respondidas
+// até a raiz, da mais antiga para a mais recente, e as respostas em árvore
+type Thread struct {
+	Ancestors []Publish    `json:"ancestors"`
+	Publish   Publish      `json:"publish"`
+	Replies   []ThreadNode `json:"replies"`
+	Page      uint64       `json:"page"`
+	Limit     uint64       `json:"limit"`
+	Depth     uint64       `json:"depth"`
+}
+
+type ThreadNode struct {
+	Publish Publish      `json:"publish"`
+	Replies []ThreadNode `json:"replies,omitempty"`
+}
+
+// BuildThreadNodes monta a árvore de respostas de parentID a partir da lista
+// plana de descendentes, mantendo a ordem em que eles aparecem
+func BuildThreadNodes(descendants []Publish, parentID uint64) []ThreadNode {
+	children := map[uint64][]Publish{}
+	for _, publish := range descendants {
+		children[publish.ParentID] = append(children[publish.ParentID], publish)
+	}
+	return buildThreadNodes(children, parentID)
+}
+
+func buildThreadNodes(children map[uint64][]Publish, parentID uint64) []ThreadNode {
+	nodes := []ThreadNode{}
+	for _, publish := range children[parentID] {
+		nodes = append(nodes, ThreadNode{
+			Publish: publish,
+			Replies: buildThreadNodes(children, publish.ID),
+		})
+	}
+	return nodes
+}
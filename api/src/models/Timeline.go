@@ -0,0 +1,8 @@
+package models
+
+// TimelineCheck é o resultado da verificação de consistência de uma timeline
+type TimelineCheck struct {
+	UserID  uint64 `json:"user_id"`
+	Missing uint64 `json:"missing"`
+	Extra   uint64 `json:"extra"`
+}
@@ -6,15 +6,56 @@ import (
 	"github.com/badoux/checkmail"
 	"strings"
 	"time"
+	"unicode/utf8"
 )
 
+// Papéis de usuário
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
+// Estados da conta. Contas desativadas ou excluídas ficam ocultas; as
+// excluídas são apagadas de vez depois do período de carência.
+const (
+	UserActive      = "active"
+	UserDeactivated = "deactivated"
+	UserDeleted     = "deleted"
+)
+
+// ValidRole verifica se o papel informado é conhecido
+func ValidRole(role string) bool {
+	return role == RoleUser || role == RoleModerator || role == RoleAdmin
+}
+
 type User struct {
 	ID        uint64    `json:"id,omitempty"`
 	Name      string    `json:"name,omitempty"`
 	Nick      string    `json:"nick,omitempty"`
 	Email     string    `json:"email,omitempty"`
 	Password  string    `json:"password,omitempty"`
+	Role      string    `json:"role,omitempty"`
+	IsPrivate bool      `json:"is_private"`
+	Bio       string    `json:"bio,omitempty"`
+	Location  string    `json:"location,omitempty"`
+	Website   string    `json:"website,omitempty"`
+	AvatarURL string    `json:"avatar_url,omitempty"`
+	BannerURL string    `json:"banner_url,omitempty"`
+	AvatarKey string    `json:"-"`
 	CreatedAt time.Time `json:"created_at,omitempty"`
+	// Status e DeleteAfter só são usados internamente, no login e na exclusão
+	Status      string     `json:"-"`
+	DeleteAfter *time.Time `json:"-"`
+}
+
+// Profile é o usuário acrescido dos contadores e da relação com quem consulta
+type Profile struct {
+	User
+	FollowersCount uint64 `json:"followers_count"`
+	FollowingCount uint64 `json:"following_count"`
+	PublishesCount uint64 `json:"publishes_count"`
+	Following      bool   `json:"following"`
 }
 
 func (u *User) Prepare(stage string) error {
@@ -49,6 +90,26 @@ func (u *User) validate(stage string) error {
 		return errors.New("O campo Senha é obrigatório")
 	}
 
+	if utf8.RuneCountInString(u.Bio) > 160 {
+		return errors.New("A bio deve ter no máximo 160 caracteres")
+	}
+
+	if utf8.RuneCountInString(u.Location) > 50 {
+		return errors.New("A localização deve ter no máximo 50 caracteres")
+	}
+
+	if u.Website != "" && (len(u.Website) > 100 || !validURL(u.Website)) {
+		return errors.New("O site informado é inválido")
+	}
+
+	if u.AvatarURL != "" && (len(u.AvatarURL) > 255 || !validURL(u.AvatarURL)) {
+		return errors.New("A URL do avatar é inválida")
+	}
+
+	if u.BannerURL != "" && (len(u.BannerURL) > 255 || !validURL(u.BannerURL)) {
+		return errors.New("A URL do banner é inválida")
+	}
+
 	return nil
 }
 
@@ -56,6 +117,11 @@ func (u *User) format(stage string) error {
 	u.Name = strings.TrimSpace(u.Name)
 	u.Nick = strings.TrimSpace(u.Nick)
 	u.Email = strings.TrimSpace(u.Email)
+	u.Bio = strings.TrimSpace(u.Bio)
+	u.Location = strings.TrimSpace(u.Location)
+	u.Website = strings.TrimSpace(u.Website)
+	u.AvatarURL = strings.TrimSpace(u.AvatarURL)
+	u.BannerURL = strings.TrimSpace(u.BannerURL)
 
 	if stage == "register" {
 		passwordHash, err := security.Hash(u.Password)
@@ -0,0 +1,108 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Eventos que podem ser assinados por um webhook
+const (
+	EventPublishCreated = "publish.created"
+	EventPublishDeleted = "publish.deleted"
+	EventUserFollowed   = "user.followed"
+	EventUserDeleted    = "user.deleted"
+)
+
+var WebhookEvents = []string{
+	EventPublishCreated,
+	EventPublishDeleted,
+	EventUserFollowed,
+	EventUserDeleted,
+}
+
+// Situações de uma entrega de webhook
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+type Webhook struct {
+	ID        uint64    `json:"id,omitempty"`
+	UserID    uint64    `json:"user_id,omitempty"`
+	URL       string    `json:"url,omitempty"`
+	Secret    string    `json:"secret,omitempty"`
+	Events    []string  `json:"events,omitempty"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at,omitempty"`
+}
+
+// OutboxEvent é um evento de domínio gravado junto com a alteração que o originou
+type OutboxEvent struct {
+	ID        uint64    `json:"id"`
+	Type      string    `json:"type"`
+	UserID    uint64    `json:"user_id"`
+	Payload   string    `json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type WebhookDelivery struct {
+	ID            uint64    `json:"id"`
+	WebhookID     uint64    `json:"webhook_id"`
+	OutboxID      uint64    `json:"event_id"`
+	EventType     string    `json:"event_type"`
+	Status        string    `json:"status"`
+	Attempts      uint64    `json:"attempts"`
+	StatusCode    int       `json:"status_code"`
+	Error         string    `json:"error,omitempty"`
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+	CreatedAt     time.Time `json:"created_at"`
+
+	// Preenchidos apenas para o envio
+	Webhook Webhook     `json:"-"`
+	Event   OutboxEvent `json:"-"`
+}
+
+func (w *Webhook) Prepare() error {
+	w.format()
+	if err := w.validate(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (w *Webhook) format() {
+	w.URL = strings.TrimSpace(w.URL)
+	for i, event := range w.Events {
+		w.Events[i] = strings.TrimSpace(event)
+	}
+}
+
+func (w *Webhook) validate() error {
+	if !validURL(w.URL) {
+		return errors.New("URL do webhook é inválida")
+	}
+	if len(w.URL) > 255 {
+		return errors.New("URL do webhook é muito longa")
+	}
+
+	if len(w.Events) == 0 {
+		return errors.New("Informe ao menos um evento")
+	}
+	for _, event := range w.Events {
+		if !validWebhookEvent(event) {
+			return errors.New("Evento inválido: " + event)
+		}
+	}
+	return nil
+}
+
+func validWebhookEvent(event string) bool {
+	for _, e := range WebhookEvents {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
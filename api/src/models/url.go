@@ -0,0 +1,13 @@
+package models
+
+import "net/url"
+
+// validURL aceita apenas URLs absolutas http ou https
+func validURL(rawURL string) bool {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+
+	return (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
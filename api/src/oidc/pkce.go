@@ -0,0 +1,30 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+)
+
+// RandomString gera um valor aleatório com size bytes em base64 para URLs,
+// usado no state, no nonce e no code verifier do PKCE
+func RandomString(size int) (string, error) {
+	buffer := make([]byte, size)
+	if _, err := rand.Read(buffer); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buffer), nil
+}
+
+// StateHash calcula o valor do cookie que liga o state ao navegador que
+// iniciou o login, para que o retorno não possa ser completado em outro
+func StateHash(state string) string {
+	sum := sha256.Sum256([]byte("state:" + state))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// Challenge calcula o code challenge S256 do PKCE para o verifier
+func Challenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,347 @@
+package oidc
+
+import (
+	"api/src/config"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	jwt "github.com/dgrijalva/jwt-go"
+)
+
+// Limite de leitura das respostas do provedor
+const maxResponseSize = 1 << 20
+
+// Claims são os dados da identidade externa usados no login
+type Claims struct {
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	Name              string
+	PreferredUsername string
+}
+
+// discovery é o documento .well-known/openid-configuration do provedor
+type discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserinfoEndpoint      string `json:"userinfo_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// Provider é um cliente do fluxo authorization code com PKCE de um provedor
+// OpenID Connect. A configuração do provedor e suas chaves são buscadas na
+// primeira utilização e guardadas.
+type Provider struct {
+	config config.OIDCProvider
+	client *http.Client
+
+	mutex     sync.Mutex
+	discovery *discovery
+	keys      map[string]interface{}
+}
+
+// Providers são os provedores configurados, pelo nome, definidos em main
+var Providers = map[string]*Provider{}
+
+func NewProvider(cfg config.OIDCProvider, client *http.Client) *Provider {
+	return &Provider{config: cfg, client: client}
+}
+
+func (p *Provider) Name() string {
+	return p.config.Name
+}
+
+// AuthCodeURL monta a URL do provedor para onde o usuário é enviado para entrar
+func (p *Provider) AuthCodeURL(state, nonce, verifier string) (string, error) {
+	doc, err := p.getDiscovery()
+	if err != nil {
+		return "", err
+	}
+
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.config.ClientID},
+		"redirect_uri":          {p.config.RedirectURL},
+		"scope":                 {strings.Join(p.config.Scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {Challenge(verifier)},
+		"code_challenge_method": {"S256"},
+	}
+
+	separator := "?"
+	if strings.Contains(doc.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return doc.AuthorizationEndpoint + separator + query.Encode(), nil
+}
+
+// Exchange troca o código de autorização pelos tokens e retorna as claims do
+// ID token, depois de conferir assinatura, emissor, audiência e nonce
+func (p *Provider) Exchange(code, verifier, nonce string) (Claims, error) {
+	doc, err := p.getDiscovery()
+	if err != nil {
+		return Claims{}, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.config.RedirectURL},
+		"client_id":     {p.config.ClientID},
+		"client_secret": {p.config.ClientSecret},
+		"code_verifier": {verifier},
+	}
+	response, err := p.client.PostForm(doc.TokenEndpoint, form)
+	if err != nil {
+		return Claims{}, err
+	}
+	defer response.Body.Close()
+
+	var tokens struct {
+		IDToken          string `json:"id_token"`
+		AccessToken      string `json:"access_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err = decodeJSON(response.Body, &tokens); err != nil {
+		return Claims{}, err
+	}
+	if response.StatusCode != http.StatusOK || tokens.Error != "" {
+		return Claims{}, fmt.Errorf("O provedor recusou o código: %s %s", tokens.Error, tokens.ErrorDescription)
+	}
+	if tokens.IDToken == "" {
+		return Claims{}, errors.New("O provedor não retornou o ID token")
+	}
+
+	claims, err := p.verify(doc, tokens.IDToken, nonce)
+	if err != nil {
+		return Claims{}, err
+	}
+
+	if claims.Email == "" && doc.UserinfoEndpoint != "" && tokens.AccessToken != "" {
+		if err = p.loadUserinfo(doc, tokens.AccessToken, &claims); err != nil {
+			return Claims{}, err
+		}
+	}
+	return claims, nil
+}
+
+// verify valida o ID token com as chaves do provedor
+func (p *Provider) verify(doc *discovery, idToken, nonce string) (Claims, error) {
+	token, err := jwt.Parse(idToken, func(token *jwt.Token) (interface{}, error) {
+		// Apenas algoritmos assimétricos; HS256 com uma chave pública seria forjável
+		switch token.Method.(type) {
+		case *jwt.SigningMethodRSA, *jwt.SigningMethodECDSA:
+		default:
+			return nil, fmt.Errorf("Método de assinatura inesperado! %v", token.Header["alg"])
+		}
+
+		kid, _ := token.Header["kid"].(string)
+		return p.key(doc, kid)
+	})
+	if err != nil {
+		return Claims{}, err
+	}
+
+	raw, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return Claims{}, errors.New("ID token inválido")
+	}
+
+	if issuer, _ := raw["iss"].(string); issuer != doc.Issuer {
+		return Claims{}, errors.New("ID token de outro emissor")
+	}
+	if !hasAudience(raw["aud"], p.config.ClientID) {
+		return Claims{}, errors.New("ID token emitido para outro cliente")
+	}
+	if tokenNonce, _ := raw["nonce"].(string); tokenNonce != nonce {
+		return Claims{}, errors.New("Nonce do ID token não confere")
+	}
+
+	claims := claimsFrom(raw)
+	if claims.Subject == "" {
+		return Claims{}, errors.New("ID token sem sub")
+	}
+	return claims, nil
+}
+
+// loadUserinfo completa as claims com o endpoint userinfo, para provedores que
+// não colocam o e-mail no ID token
+func (p *Provider) loadUserinfo(doc *discovery, accessToken string, claims *Claims) error {
+	request, err := http.NewRequest(http.MethodGet, doc.UserinfoEndpoint, nil)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Authorization", "Bearer "+accessToken)
+
+	response, err := p.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("userinfo respondeu %s", response.Status)
+	}
+
+	var raw map[string]interface{}
+	if err = decodeJSON(response.Body, &raw); err != nil {
+		return err
+	}
+
+	info := claimsFrom(raw)
+	if info.Subject != claims.Subject {
+		return errors.New("userinfo de outro usuário")
+	}
+	claims.Email, claims.EmailVerified = info.Email, info.EmailVerified
+	if claims.Name == "" {
+		claims.Name = info.Name
+	}
+	if claims.PreferredUsername == "" {
+		claims.PreferredUsername = info.PreferredUsername
+	}
+	return nil
+}
+
+func (p *Provider) getDiscovery() (*discovery, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	response, err := p.client.Get(p.config.Issuer + "/.well-known/openid-configuration")
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("discovery respondeu %s", response.Status)
+	}
+
+	var doc discovery
+	if err = decodeJSON(response.Body, &doc); err != nil {
+		return nil, err
+	}
+	if strings.TrimSuffix(doc.Issuer, "/") != p.config.Issuer {
+		return nil, errors.New("O emissor do discovery não é o configurado")
+	}
+	if doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" || doc.JWKSURI == "" {
+		return nil, errors.New("Discovery incompleto")
+	}
+
+	p.discovery = &doc
+	return p.discovery, nil
+}
+
+// key retorna a chave pública do kid, buscando o JWKS de novo quando o kid é
+// desconhecido, já que o provedor pode ter trocado as chaves
+func (p *Provider) key(doc *discovery, kid string) (interface{}, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if key, found := p.lookupKey(kid); found {
+		return key, nil
+	}
+
+	keys, err := p.fetchKeys(doc.JWKSURI)
+	if err != nil {
+		return nil, err
+	}
+	p.keys = keys
+
+	if key, found := p.lookupKey(kid); found {
+		return key, nil
+	}
+	return nil, errors.New("Chave do ID token desconhecida")
+}
+
+// lookupKey busca pelo kid; tokens sem kid só são aceitos se houver uma única chave
+func (p *Provider) lookupKey(kid string) (interface{}, bool) {
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+	key, found := p.keys[kid]
+	return key, found
+}
+
+func (p *Provider) fetchKeys(jwksURI string) (map[string]interface{}, error) {
+	response, err := p.client.Get(jwksURI)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("jwks respondeu %s", response.Status)
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err = decodeJSON(response.Body, &set); err != nil {
+		return nil, err
+	}
+
+	keys := map[string]interface{}{}
+	for _, item := range set.Keys {
+		if item.Use != "" && item.Use != "sig" {
+			continue
+		}
+		key, err := item.publicKey()
+		if err != nil {
+			continue
+		}
+		keys[item.Kid] = key
+	}
+	return keys, nil
+}
+
+func decodeJSON(body io.Reader, target interface{}) error {
+	data, err := ioutil.ReadAll(io.LimitReader(body, maxResponseSize))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, target)
+}
+
+// hasAudience aceita aud como texto ou como lista
+func hasAudience(aud interface{}, clientID string) bool {
+	switch value := aud.(type) {
+	case string:
+		return value == clientID
+	case []interface{}:
+		for _, item := range value {
+			if item == clientID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// claimsFrom lê as claims padrão; alguns provedores enviam email_verified como texto
+func claimsFrom(raw map[string]interface{}) Claims {
+	claims := Claims{}
+	claims.Subject, _ = raw["sub"].(string)
+	claims.Email, _ = raw["email"].(string)
+	claims.Name, _ = raw["name"].(string)
+	claims.PreferredUsername, _ = raw["preferred_username"].(string)
+
+	switch verified := raw["email_verified"].(type) {
+	case bool:
+		claims.EmailVerified = verified
+	case string:
+		claims.EmailVerified = verified == "true"
+	}
+	return claims
+}
@@ -0,0 +1,329 @@
+package oidc
+
+import (
+	"api/src/config"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+)
+
+// mockProvider é um provedor OpenID Connect local. Ele guarda o code challenge
+// e o nonce recebidos na autorização e os usa na troca do código, como um
+// provedor real.
+type mockProvider struct {
+	server    *httptest.Server
+	key       *rsa.PrivateKey
+	challenge string
+	nonce     string
+	// issuer, quando definido, substitui o emissor anunciado no discovery
+	issuer string
+	// claims permite alterar as claims do ID token emitido
+	claims func(claims jwt.MapClaims)
+}
+
+func newMockProvider(t *testing.T) *mockProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mock := &mockProvider{key: key}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", mock.discovery)
+	mux.HandleFunc("/jwks", mock.jwks)
+	mux.HandleFunc("/token", mock.token)
+	mux.HandleFunc("/userinfo", mock.userinfo)
+	mock.server = httptest.NewServer(mux)
+
+	return mock
+}
+
+func (m *mockProvider) discovery(w http.ResponseWriter, r *http.Request) {
+	issuer := m.issuer
+	if issuer == "" {
+		issuer = m.server.URL
+	}
+	json.NewEncoder(w).Encode(map[string]string{
+		"issuer":                 issuer,
+		"authorization_endpoint": m.server.URL + "/authorize",
+		"token_endpoint":         m.server.URL + "/token",
+		"userinfo_endpoint":      m.server.URL + "/userinfo",
+		"jwks_uri":               m.server.URL + "/jwks",
+	})
+}
+
+func (m *mockProvider) jwks(w http.ResponseWriter, r *http.Request) {
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"keys": []map[string]string{{
+			"kid": "mock",
+			"kty": "RSA",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(m.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(m.key.E)).Bytes()),
+		}},
+	})
+}
+
+func (m *mockProvider) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if r.Form.Get("code") != "valid-code" || Challenge(r.Form.Get("code_verifier")) != m.challenge {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	claims := jwt.MapClaims{
+		"iss":            m.server.URL,
+		"aud":            "client",
+		"sub":            "user-1",
+		"email":          "maria@example.com",
+		"email_verified": true,
+		"name":           "Maria",
+		"nonce":          m.nonce,
+		"exp":            time.Now().Add(time.Minute).Unix(),
+	}
+	if m.claims != nil {
+		m.claims(claims)
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = "mock"
+	idToken, err := token.SignedString(m.key)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{"id_token": idToken, "access_token": "access"})
+}
+
+func (m *mockProvider) userinfo(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer access" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"sub":            "user-1",
+		"email":          "info@example.com",
+		"email_verified": "true",
+	})
+}
+
+// login faz o fluxo completo: monta a URL de autorização, guarda o challenge e
+// o nonce como o provedor faria e troca o código
+func (m *mockProvider) login(t *testing.T, provider *Provider) (Claims, error) {
+	verifier, err := RandomString(48)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonce, err := RandomString(32)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	authURL, err := provider.AuthCodeURL("state", nonce, verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.challenge = parsed.Query().Get("code_challenge")
+	m.nonce = parsed.Query().Get("nonce")
+
+	return provider.Exchange("valid-code", verifier, nonce)
+}
+
+func newTestProvider(issuer string) *Provider {
+	return NewProvider(config.OIDCProvider{
+		Name:         "mock",
+		Issuer:       issuer,
+		ClientID:     "client",
+		ClientSecret: "secret",
+		RedirectURL:  "http://localhost:5000/login/mock/callback",
+		Scopes:       []string{"openid", "email"},
+	}, http.DefaultClient)
+}
+
+func TestAuthCodeURL(t *testing.T) {
+	mock := newMockProvider(t)
+	defer mock.server.Close()
+	provider := newTestProvider(mock.server.URL)
+
+	authURL, err := provider.AuthCodeURL("state", "nonce", "verifier")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if parsed.Path != "/authorize" {
+		t.Errorf("endpoint = %s", parsed.Path)
+	}
+	want := map[string]string{
+		"response_type":         "code",
+		"client_id":             "client",
+		"scope":                 "openid email",
+		"state":                 "state",
+		"nonce":                 "nonce",
+		"code_challenge":        Challenge("verifier"),
+		"code_challenge_method": "S256",
+	}
+	for name, value := range want {
+		if got := parsed.Query().Get(name); got != value {
+			t.Errorf("%s = %q, esperado %q", name, got, value)
+		}
+	}
+}
+
+func TestExchange(t *testing.T) {
+	mock := newMockProvider(t)
+	defer mock.server.Close()
+	provider := newTestProvider(mock.server.URL)
+
+	claims, err := mock.login(t, provider)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := Claims{Subject: "user-1", Email: "maria@example.com", EmailVerified: true, Name: "Maria"}
+	if claims != want {
+		t.Errorf("claims = %+v, esperado %+v", claims, want)
+	}
+}
+
+func TestExchangeUserinfo(t *testing.T) {
+	mock := newMockProvider(t)
+	defer mock.server.Close()
+	mock.claims = func(claims jwt.MapClaims) {
+		delete(claims, "email")
+		delete(claims, "email_verified")
+	}
+	provider := newTestProvider(mock.server.URL)
+
+	claims, err := mock.login(t, provider)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.Email != "info@example.com" || !claims.EmailVerified {
+		t.Errorf("claims = %+v, esperado o e-mail do userinfo", claims)
+	}
+}
+
+func TestExchangeRejects(t *testing.T) {
+	tests := []struct {
+		name   string
+		claims func(claims jwt.MapClaims)
+	}{
+		{
+			name: "nonce diferente",
+			claims: func(claims jwt.MapClaims) {
+				claims["nonce"] = "outro"
+			},
+		},
+		{
+			name: "audiência de outro cliente",
+			claims: func(claims jwt.MapClaims) {
+				claims["aud"] = []string{"outro-cliente"}
+			},
+		},
+		{
+			name: "outro emissor",
+			claims: func(claims jwt.MapClaims) {
+				claims["iss"] = "https://evil.example.com"
+			},
+		},
+		{
+			name: "token expirado",
+			claims: func(claims jwt.MapClaims) {
+				claims["exp"] = time.Now().Add(-time.Minute).Unix()
+			},
+		},
+		{
+			name: "sem sub",
+			claims: func(claims jwt.MapClaims) {
+				delete(claims, "sub")
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mock := newMockProvider(t)
+			defer mock.server.Close()
+			mock.claims = test.claims
+			provider := newTestProvider(mock.server.URL)
+
+			if _, err := mock.login(t, provider); err == nil {
+				t.Error("o ID token deveria ter sido recusado")
+			}
+		})
+	}
+}
+
+func TestExchangeWrongVerifier(t *testing.T) {
+	mock := newMockProvider(t)
+	defer mock.server.Close()
+	provider := newTestProvider(mock.server.URL)
+
+	mock.challenge = Challenge("verifier")
+	mock.nonce = "nonce"
+
+	if _, err := provider.Exchange("valid-code", "outro-verifier", "nonce"); err == nil {
+		t.Error("o código deveria ter sido recusado sem o verifier certo")
+	}
+}
+
+func TestExchangeRejectsHMAC(t *testing.T) {
+	mock := newMockProvider(t)
+	defer mock.server.Close()
+	provider := newTestProvider(mock.server.URL)
+
+	// Um token assinado com HS256 usando a chave pública como segredo
+	publicKey := mock.key.PublicKey.N.Bytes()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"iss":   mock.server.URL,
+		"aud":   "client",
+		"sub":   "user-1",
+		"nonce": "nonce",
+		"exp":   time.Now().Add(time.Minute).Unix(),
+	})
+	idToken, err := token.SignedString(publicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	doc, err := provider.getDiscovery()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = provider.verify(doc, idToken, "nonce"); err == nil {
+		t.Error("tokens HS256 deveriam ser recusados")
+	}
+}
+
+func TestDiscoveryIssuerMismatch(t *testing.T) {
+	mock := newMockProvider(t)
+	defer mock.server.Close()
+	mock.issuer = "https://evil.example.com"
+	provider := newTestProvider(mock.server.URL)
+
+	if _, err := provider.AuthCodeURL("state", "nonce", "verifier"); err == nil {
+		t.Error("o discovery de outro emissor deveria ter sido recusado")
+	}
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"api/src/models"
+	"crypto/subtle"
+	"database/sql"
+	"strings"
+	"time"
+)
+
+// Intervalo mínimo entre as atualizações de last_used_at de uma chave
+const apiKeyTouchInterval = time.Minute
+
+type APIKeys struct {
+	db *sql.DB
+}
+
+func NewAPIKeysRepository(db *sql.DB) *APIKeys {
+	return &APIKeys{db: db}
+}
+
+func (a *APIKeys) Create(key models.APIKey) (uint64, error) {
+	result, err := a.db.Exec(
+		"insert into api_keys (user_id, name, prefix, key_hash, scopes, expires_at) values (?, ?, ?, ?, ?, ?)",
+		key.UserID, key.Name, key.Prefix, key.Hash, strings.Join(key.Scopes, ","), key.ExpiresAt,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	ID, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	return uint64(ID), nil
+}
+
+// Get lista as chaves do usuário, inclusive as expiradas
+func (a *APIKeys) Get(userID uint64) ([]models.APIKey, error) {
+	rows, err := a.db.Query(
+		`select id, user_id, name, prefix, key_hash, scopes, expires_at, last_used_at, created_at
+		from api_keys where user_id = ? order by id desc`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []models.APIKey{}
+	for rows.Next() {
+		var key models.APIKey
+		if err = scanAPIKey(rows, &key); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+// Delete revoga a chave do usuário e retorna false se ela não existe
+func (a *APIKeys) Delete(keyID, userID uint64) (bool, error) {
+	result, err := a.db.Exec("delete from api_keys where id = ? and user_id = ?", keyID, userID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// Authenticate busca a chave válida e não expirada de um usuário ativo e
+// registra o uso. Retorna false se a chave não for aceita.
+func (a *APIKeys) Authenticate(rawKey string, now time.Time) (models.APIKey, bool, error) {
+	prefix, ok := models.ParseAPIKeyPrefix(rawKey)
+	if !ok {
+		return models.APIKey{}, false, nil
+	}
+
+	var key models.APIKey
+	err := scanAPIKey(a.db.QueryRow(
+		`select k.id, k.user_id, k.name, k.prefix, k.key_hash, k.scopes, k.expires_at, k.last_used_at, k.created_at
+		from api_keys k inner join users u on k.user_id = u.id
+		where k.prefix = ? and u.status = 'active' and (k.expires_at is null or k.expires_at > ?)`,
+		prefix, now,
+	), &key)
+	if err == sql.ErrNoRows {
+		return models.APIKey{}, false, nil
+	}
+	if err != nil {
+		return models.APIKey{}, false, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(key.Hash), []byte(models.HashAPIKey(rawKey))) != 1 {
+		return models.APIKey{}, false, nil
+	}
+
+	if _, err = a.db.Exec(
+		"update api_keys set last_used_at = ? where id = ? and (last_used_at is null or last_used_at < ?)",
+		now, key.ID, now.Add(-apiKeyTouchInterval),
+	); err != nil {
+		return models.APIKey{}, false, err
+	}
+	return key, true, nil
+}
+
+func scanAPIKey(row scanner, key *models.APIKey) error {
+	var scopes string
+	var expiresAt, lastUsedAt sql.NullTime
+	if err := row.Scan(
+		&key.ID,
+		&key.UserID,
+		&key.Name,
+		&key.Prefix,
+		&key.Hash,
+		&scopes,
+		&expiresAt,
+		&lastUsedAt,
+		&key.CreatedAt,
+	); err != nil {
+		return err
+	}
+
+	key.Scopes = strings.Split(scopes, ",")
+	if expiresAt.Valid {
+		key.ExpiresAt = &expiresAt.Time
+	}
+	if lastUsedAt.Valid {
+		key.LastUsedAt = &lastUsedAt.Time
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"api/src/models"
+	"database/sql"
+	"encoding/json"
+	"time"
+	"unicode/utf8"
+)
+
+// auditColumns são as colunas lidas por scanAudit
+const auditColumns = `id, actor_id, target_id, action, ip, user_agent, details, created_at, prev_hash, hash`
+
+// Audit é o log de auditoria. Os registros só podem ser acrescentados; não há
+// como alterá-los ou apagá-los pela API.
+type Audit struct {
+	db *sql.DB
+}
+
+func NewAuditRepository(db *sql.DB) *Audit {
+	return &Audit{db: db}
+}
+
+// Append grava o registro no fim da cadeia. A linha de audit_head é travada
+// para que dois registros nunca sejam encadeados ao mesmo anterior.
+func (a *Audit) Append(entry models.AuditEntry) (uint64, error) {
+	entry.UserAgent = truncate(entry.UserAgent, 255)
+	entry.IP = truncate(entry.IP, 45)
+	entry.CreatedAt = time.Now().Truncate(time.Second)
+
+	details, err := json.Marshal(entry.Details)
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := a.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if err = tx.QueryRow("select hash from audit_head where id = 1 for update").Scan(&entry.PrevHash); err != nil {
+		return 0, err
+	}
+	entry.Hash = entry.ComputeHash(string(details))
+
+	result, err := tx.Exec(
+		`insert into audit_log (actor_id, target_id, action, ip, user_agent, details, created_at, prev_hash, hash)
+		values (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		entry.ActorID, entry.TargetID, entry.Action, entry.IP, entry.UserAgent, string(details), entry.CreatedAt,
+		entry.PrevHash, entry.Hash,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	ID, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err = tx.Exec("update audit_head set hash = ? where id = 1", entry.Hash); err != nil {
+		return 0, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, err
+	}
+	return uint64(ID), nil
+}
+
+// Get lista os registros que atendem ao filtro, dos mais recentes para os mais antigos
+func (a *Audit) Get(filter models.AuditFilter, limit, offset uint64) ([]models.AuditEntry, error) {
+	query := "select " + auditColumns + " from audit_log where 1 = 1"
+	var args []interface{}
+	if filter.ActorID != 0 {
+		query += " and actor_id = ?"
+		args = append(args, filter.ActorID)
+	}
+	if filter.TargetID != 0 {
+		query += " and target_id = ?"
+		args = append(args, filter.TargetID)
+	}
+	if filter.Action != "" {
+		query += " and action = ?"
+		args = append(args, filter.Action)
+	}
+	if !filter.From.IsZero() {
+		query += " and created_at >= ?"
+		args = append(args, filter.From)
+	}
+	if !filter.To.IsZero() {
+		query += " and created_at < ?"
+		args = append(args, filter.To)
+	}
+	args = append(args, limit, offset)
+
+	rows, err := a.db.Query(query+" order by id desc limit ? offset ?", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []models.AuditEntry{}
+	for rows.Next() {
+		var entry models.AuditEntry
+		if _, err = scanAudit(rows, &entry); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// Verify percorre a cadeia desde o primeiro registro conferindo os hashes. O
+// último hash também precisa ser o de audit_head, o que revela registros
+// removidos do fim.
+func (a *Audit) Verify() (models.AuditVerification, error) {
+	var verification models.AuditVerification
+
+	rows, err := a.db.Query("select " + auditColumns + " from audit_log order by id")
+	if err != nil {
+		return verification, err
+	}
+	defer rows.Close()
+
+	previous := ""
+	for rows.Next() {
+		var entry models.AuditEntry
+		details, err := scanAudit(rows, &entry)
+		if err != nil {
+			return verification, err
+		}
+
+		verification.Checked++
+		if entry.PrevHash != previous || entry.ComputeHash(details) != entry.Hash {
+			verification.BrokenID = entry.ID
+			return verification, nil
+		}
+		previous = entry.Hash
+	}
+	if err = rows.Err(); err != nil {
+		return verification, err
+	}
+
+	var head string
+	if err = a.db.QueryRow("select hash from audit_head where id = 1").Scan(&head); err != nil {
+		return verification, err
+	}
+	verification.Valid = head == previous
+	return verification, nil
+}
+
+// scanAudit lê o registro e retorna o JSON dos detalhes como gravado
+func scanAudit(row scanner, entry *models.AuditEntry) (string, error) {
+	var details string
+	if err := row.Scan(
+		&entry.ID,
+		&entry.ActorID,
+		&entry.TargetID,
+		&entry.Action,
+		&entry.IP,
+		&entry.UserAgent,
+		&details,
+		&entry.CreatedAt,
+		&entry.PrevHash,
+		&entry.Hash,
+	); err != nil {
+		return "", err
+	}
+
+	if err := json.Unmarshal([]byte(details), &entry.Details); err != nil {
+		return "", err
+	}
+	return details, nil
+}
+
+// truncate corta o texto em size caracteres, o limite das colunas varchar,
+// sem partir um caractere multibyte ao meio
+func truncate(value string, size int) string {
+	if utf8.RuneCountInString(value) <= size {
+		return value
+	}
+	return string([]rune(value)[:size])
+}
@@ -0,0 +1,216 @@
+package repository
+
+import (
+	"api/src/models"
+	"database/sql"
+	"strings"
+)
+
+type Bookmarks struct {
+	db *sql.DB
+}
+
+func NewBookmarksRepository(db *sql.DB) *Bookmarks {
+	return &Bookmarks{db: db}
+}
+
+// Save salva a publicação para o usuário. Salvar de novo apenas muda a coleção.
+func (b *Bookmarks) Save(userID, publishID, collectionID uint64) error {
+	statement, err := b.db.Prepare(
+		`insert into bookmarks (user_id, publish_id, collection_id) values (?, ?, ?)
+		on duplicate key update collection_id = values(collection_id)`,
+	)
+	if err != nil {
+		return err
+	}
+	defer statement.Close()
+
+	var collection sql.NullInt64
+	if collectionID != 0 {
+		collection = sql.NullInt64{Int64: int64(collectionID), Valid: true}
+	}
+
+	if _, err = statement.Exec(userID, publishID, collection); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (b *Bookmarks) Remove(userID, publishID uint64) error {
+	statement, err := b.db.Prepare("delete from bookmarks where user_id = ? and publish_id = ?")
+	if err != nil {
+		return err
+	}
+	defer statement.Close()
+
+	if _, err = statement.Exec(userID, publishID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Bookmarked indica quais das publicações foram salvas pelo usuário
+func (b *Bookmarks) Bookmarked(userID uint64, publishIDs []uint64) (map[uint64]bool, error) {
+	bookmarked := map[uint64]bool{}
+	if len(publishIDs) == 0 {
+		return bookmarked, nil
+	}
+
+	args := []interface{}{userID}
+	for _, publishID := range publishIDs {
+		args = append(args, publishID)
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(publishIDs)), ",")
+
+	rows, err := b.db.Query(
+		"select publish_id from bookmarks where user_id = ? and publish_id in ("+placeholders+")", args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var publishID uint64
+		if err = rows.Scan(&publishID); err != nil {
+			return nil, err
+		}
+		bookmarked[publishID] = true
+	}
+
+	return bookmarked, nil
+}
+
+// Get lista as publicações salvas, da mais recente para a mais antiga.
+// Com collectionID diferente de 0, lista apenas as da coleção.
+func (b *Bookmarks) Get(userID, collectionID, limit, offset uint64) ([]models.Publish, error) {
+	query := `select ` + publishColumns + `
+				from bookmarks b
+				inner join publishes p on b.publish_id = p.id
+				inner join users u on p.author_id = u.id
+				where b.user_id = ? and p.status = 'published' and u.status = 'active'`
+	args := []interface{}{userID}
+	if collectionID != 0 {
+		query += " and b.collection_id = ?"
+		args = append(args, collectionID)
+	}
+	args = append(args, limit, offset)
+
+	rows, err := b.db.Query(query+" order by b.created_at desc, p.id desc limit ? offset ?", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	publishes := []models.Publish{}
+	for rows.Next() {
+		var publish models.Publish
+		if err = scanPublish(rows, &publish); err != nil {
+			return nil, err
+		}
+		publishes = append(publishes, publish)
+	}
+
+	return publishes, nil
+}
+
+func (b *Bookmarks) CreateCollection(collection models.Collection) (uint64, error) {
+	statement, err := b.db.Prepare("insert into collections (user_id, name) values (?, ?)")
+	if err != nil {
+		return 0, err
+	}
+	defer statement.Close()
+
+	result, err := statement.Exec(collection.UserID, collection.Name)
+	if err != nil {
+		return 0, err
+	}
+
+	lastInsertID, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	return uint64(lastInsertID), nil
+}
+
+func (b *Bookmarks) GetCollections(userID uint64) ([]models.Collection, error) {
+	rows, err := b.db.Query(
+		`select c.id, c.user_id, c.name, count(b.publish_id), c.created_at
+				from collections c
+				left join bookmarks b on b.collection_id = c.id
+				where c.user_id = ?
+				group by c.id, c.user_id, c.name, c.created_at
+				order by c.name`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	collections := []models.Collection{}
+	for rows.Next() {
+		var collection models.Collection
+		if err = rows.Scan(
+			&collection.ID,
+			&collection.UserID,
+			&collection.Name,
+			&collection.Bookmarks,
+			&collection.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		collections = append(collections, collection)
+	}
+
+	return collections, nil
+}
+
+func (b *Bookmarks) GetCollection(collectionID uint64) (models.Collection, error) {
+	row, err := b.db.Query("select id, user_id, name, created_at from collections where id = ?", collectionID)
+	if err != nil {
+		return models.Collection{}, err
+	}
+	defer row.Close()
+
+	var collection models.Collection
+	if row.Next() {
+		if err = row.Scan(&collection.ID, &collection.UserID, &collection.Name, &collection.CreatedAt); err != nil {
+			return models.Collection{}, err
+		}
+	}
+
+	return collection, nil
+}
+
+func (b *Bookmarks) RenameCollection(collectionID uint64, name string) error {
+	statement, err := b.db.Prepare("update collections set name = ? where id = ?")
+	if err != nil {
+		return err
+	}
+	defer statement.Close()
+
+	if _, err = statement.Exec(name, collectionID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteCollection apaga a coleção; os itens salvos nela continuam salvos, sem coleção
+func (b *Bookmarks) DeleteCollection(collectionID uint64) error {
+	statement, err := b.db.Prepare("delete from collections where id = ?")
+	if err != nil {
+		return err
+	}
+	defer statement.Close()
+
+	if _, err = statement.Exec(collectionID); err != nil {
+		return err
+	}
+
+	return nil
+}
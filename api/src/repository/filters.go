@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"api/src/models"
+	"database/sql"
+)
+
+type ContentFilters struct {
+	db *sql.DB
+}
+
+func NewContentFiltersRepository(db *sql.DB) *ContentFilters {
+	return &ContentFilters{db: db}
+}
+
+func (c *ContentFilters) Create(filter models.ContentFilter) (uint64, error) {
+	statement, err := c.db.Prepare("insert into content_filters (kind, pattern, action) values (?, ?, ?)")
+	if err != nil {
+		return 0, err
+	}
+	defer statement.Close()
+
+	result, err := statement.Exec(filter.Kind, filter.Pattern, filter.Action)
+	if err != nil {
+		return 0, err
+	}
+
+	lastInsertID, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	return uint64(lastInsertID), nil
+}
+
+func (c *ContentFilters) Get() ([]models.ContentFilter, error) {
+	rows, err := c.db.Query("select id, kind, pattern, action, created_at from content_filters order by id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	filters := []models.ContentFilter{}
+	for rows.Next() {
+		var filter models.ContentFilter
+		if err = rows.Scan(
+			&filter.ID,
+			&filter.Kind,
+			&filter.Pattern,
+			&filter.Action,
+			&filter.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		filters = append(filters, filter)
+	}
+
+	return filters, nil
+}
+
+func (c *ContentFilters) Delete(filterID uint64) error {
+	statement, err := c.db.Prepare("delete from content_filters where id = ?")
+	if err != nil {
+		return err
+	}
+	defer statement.Close()
+
+	if _, err = statement.Exec(filterID); err != nil {
+		return err
+	}
+
+	return nil
+}
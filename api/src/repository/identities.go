@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"api/src/models"
+	"database/sql"
+	"time"
+)
+
+type Identities struct {
+	db *sql.DB
+}
+
+func NewIdentitiesRepository(db *sql.DB) *Identities {
+	return &Identities{db: db}
+}
+
+func (i *Identities) CreateState(state models.OAuthState) error {
+	_, err := i.db.Exec(
+		"insert into oauth_states (state, provider, code_verifier, nonce, expires_at) values (?, ?, ?, ?, ?)",
+		state.State, state.Provider, state.Verifier, state.Nonce, state.ExpiresAt,
+	)
+	return err
+}
+
+// ConsumeState busca e apaga o state, que só pode ser usado uma vez. Retorna
+// false se ele não existe, é de outro provedor ou já expirou. States vencidos
+// são apagados junto.
+func (i *Identities) ConsumeState(stateValue, provider string, now time.Time) (models.OAuthState, bool, error) {
+	tx, err := i.db.Begin()
+	if err != nil {
+		return models.OAuthState{}, false, err
+	}
+	defer tx.Rollback()
+
+	var state models.OAuthState
+	err = tx.QueryRow(
+		"select state, provider, code_verifier, nonce, expires_at from oauth_states where state = ? for update",
+		stateValue,
+	).Scan(&state.State, &state.Provider, &state.Verifier, &state.Nonce, &state.ExpiresAt)
+	if err != nil && err != sql.ErrNoRows {
+		return models.OAuthState{}, false, err
+	}
+	found := err == nil
+
+	if _, err = tx.Exec("delete from oauth_states where state = ? or expires_at <= ?", stateValue, now); err != nil {
+		return models.OAuthState{}, false, err
+	}
+	if err = tx.Commit(); err != nil {
+		return models.OAuthState{}, false, err
+	}
+
+	if !found || state.Provider != provider || !now.Before(state.ExpiresAt) {
+		return models.OAuthState{}, false, nil
+	}
+	return state, true, nil
+}
+
+// GetUserID retorna o usuário ligado à identidade, ou 0 se ela não foi ligada
+func (i *Identities) GetUserID(provider, subject string) (uint64, error) {
+	var userID uint64
+	err := i.db.QueryRow(
+		"select user_id from user_identities where provider = ? and subject = ?", provider, subject,
+	).Scan(&userID)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return userID, err
+}
+
+// Link liga a identidade a um usuário existente
+func (i *Identities) Link(identity models.Identity) error {
+	_, err := i.db.Exec(
+		"insert into user_identities (provider, subject, user_id, email) values (?, ?, ?, ?)",
+		identity.Provider, identity.Subject, identity.UserID, identity.Email,
+	)
+	return err
+}
+
+// CreateUser cria o usuário e a identidade que o originou na mesma transação
+func (i *Identities) CreateUser(user models.User, identity models.Identity) (uint64, error) {
+	tx, err := i.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(
+		"insert into users (name, nick, email, password) values (?, ?, ?, ?)",
+		user.Name, user.Nick, user.Email, user.Password,
+	)
+	if err != nil {
+		return 0, err
+	}
+	userID, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err = tx.Exec(
+		"insert into user_identities (provider, subject, user_id, email) values (?, ?, ?, ?)",
+		identity.Provider, identity.Subject, userID, identity.Email,
+	); err != nil {
+		return 0, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, err
+	}
+	return uint64(userID), nil
+}
@@ -0,0 +1,198 @@
+package repository
+
+import (
+	"api/src/models"
+	"database/sql"
+	"sort"
+)
+
+type Imports struct {
+	db *sql.DB
+}
+
+func NewImportsRepository(db *sql.DB) *Imports {
+	return &Imports{db: db}
+}
+
+// Import recria o usuário do arquivo com suas publicações, mantendo as datas
+// originais, e os vínculos de seguidor com os usuários que já existem, buscados
+// pelo nick. O perfil já deve estar preparado, com a senha em hash. Havendo
+// conflitos, ou em dry-run, nada é gravado e o relatório descreve o plano.
+func (i *Imports) Import(takeout models.Takeout, dryRun bool) (models.ImportReport, error) {
+	report := models.ImportReport{
+		DryRun:       dryRun,
+		Nick:         takeout.Profile.Nick,
+		Conflicts:    []string{},
+		MissingUsers: []string{},
+	}
+
+	tx, err := i.db.Begin()
+	if err != nil {
+		return report, err
+	}
+	defer tx.Rollback()
+
+	if report.Conflicts, err = importConflicts(tx, takeout.Profile); err != nil {
+		return report, err
+	}
+
+	followers, err := resolveNicks(tx, takeout.Followers, takeout.Profile.Nick, &report)
+	if err != nil {
+		return report, err
+	}
+	following, err := resolveNicks(tx, takeout.Following, takeout.Profile.Nick, &report)
+	if err != nil {
+		return report, err
+	}
+	report.Followers, report.Following = len(followers), len(following)
+
+	write := !dryRun && len(report.Conflicts) == 0
+	if write {
+		if report.UserID, err = insertImportedUser(tx, takeout.Profile); err != nil {
+			return report, err
+		}
+	}
+
+	// Em ordem de ID as publicações vêm antes das respostas a elas
+	publishes := append(append([]models.Publish{}, takeout.Publishes...), takeout.Comments...)
+	sort.Slice(publishes, func(a, b int) bool { return publishes[a].ID < publishes[b].ID })
+
+	imported := map[uint64]uint64{}
+	for _, publish := range publishes {
+		if publish.ParentID != 0 {
+			parentID, found := imported[publish.ParentID]
+			if !found {
+				report.SkippedComments++
+				continue
+			}
+			publish.ParentID = parentID
+		}
+
+		originalID := publish.ID
+		publish.AuthorID = report.UserID
+		publish.AuthorNick = takeout.Profile.Nick
+		if write {
+			if publish.ID, err = insertImportedPublish(tx, publish); err != nil {
+				return report, err
+			}
+			report.Imported = append(report.Imported, publish)
+		}
+		imported[originalID] = publish.ID
+
+		if publish.ParentID == 0 {
+			report.Publishes++
+		} else {
+			report.Comments++
+		}
+	}
+
+	if !write {
+		return report, nil
+	}
+
+	for _, followerID := range followers {
+		if _, err = insertFollower(tx, report.UserID, followerID); err != nil {
+			return report, err
+		}
+	}
+	for _, userID := range following {
+		if _, err = insertFollower(tx, userID, report.UserID); err != nil {
+			return report, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return report, err
+	}
+	return report, nil
+}
+
+// importConflicts lista os dados do perfil que já estão em uso por outra conta
+func importConflicts(tx *sql.Tx, profile models.User) ([]string, error) {
+	conflicts := []string{}
+	checks := []struct {
+		query   string
+		value   string
+		message string
+	}{
+		{"select count(*) from users where nick = ?", profile.Nick, "O nick " + profile.Nick + " já está em uso"},
+		{"select count(*) from users where email = ?", profile.Email, "O e-mail " + profile.Email + " já está em uso"},
+	}
+
+	for _, check := range checks {
+		var count int
+		if err := tx.QueryRow(check.query, check.value).Scan(&count); err != nil {
+			return nil, err
+		}
+		if count > 0 {
+			conflicts = append(conflicts, check.message)
+		}
+	}
+
+	return conflicts, nil
+}
+
+// resolveNicks busca os IDs dos usuários ativos pelo nick, registrando no
+// relatório os que não existem
+func resolveNicks(tx *sql.Tx, users []models.User, ownNick string, report *models.ImportReport) ([]uint64, error) {
+	var IDs []uint64
+	for _, user := range users {
+		if user.Nick == "" || user.Nick == ownNick {
+			continue
+		}
+
+		var ID uint64
+		err := tx.QueryRow("select id from users where nick = ? and status = 'active'", user.Nick).Scan(&ID)
+		if err == sql.ErrNoRows {
+			report.MissingUsers = append(report.MissingUsers, user.Nick)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		IDs = append(IDs, ID)
+	}
+
+	return IDs, nil
+}
+
+func insertImportedUser(tx *sql.Tx, user models.User) (uint64, error) {
+	result, err := tx.Exec(
+		`insert into users (name, nick, email, password, is_private, bio, location, website, created_at)
+		values (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		user.Name, user.Nick, user.Email, user.Password, user.IsPrivate, user.Bio, user.Location, user.Website,
+		user.CreatedAt,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	ID, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	return uint64(ID), nil
+}
+
+func insertImportedPublish(tx *sql.Tx, publish models.Publish) (uint64, error) {
+	var parentID sql.NullInt64
+	if publish.ParentID != 0 {
+		parentID = sql.NullInt64{Int64: int64(publish.ParentID), Valid: true}
+	}
+
+	result, err := tx.Exec(
+		`insert into publishes (title, content, author_id, status, publish_at, parent_id, created_at)
+		values (?, ?, ?, ?, ?, ?, ?)`,
+		publish.Title, publish.Content, publish.AuthorID, publish.Status, publish.PublishAt, parentID,
+		publish.CreatedAt,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	ID, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	return uint64(ID), nil
+}
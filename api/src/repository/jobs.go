@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"api/src/models"
+	"database/sql"
+	"strings"
+	"time"
+)
+
+// jobColumns são as colunas lidas por scanJob
+const jobColumns = `id, type, user_id, status, payload, result, error, attempts, run_after, created_at, finished_at`
+
+type Jobs struct {
+	db *sql.DB
+}
+
+func NewJobsRepository(db *sql.DB) *Jobs {
+	return &Jobs{db: db}
+}
+
+// Create enfileira o job para ser executado a partir de RunAfter, ou
+// imediatamente se não informado. Jobs sem dono têm UserID 0.
+func (j *Jobs) Create(job models.Job) (uint64, error) {
+	var userID sql.NullInt64
+	if job.UserID != 0 {
+		userID = sql.NullInt64{Int64: int64(job.UserID), Valid: true}
+	}
+	if job.RunAfter.IsZero() {
+		job.RunAfter = time.Now()
+	}
+
+	result, err := j.db.Exec(
+		"insert into jobs (type, user_id, payload, run_after) values (?, ?, ?, ?)",
+		job.Type, userID, job.Payload, job.RunAfter,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	ID, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	return uint64(ID), nil
+}
+
+// Get busca o job pelo ID
+func (j *Jobs) Get(ID uint64) (models.Job, error) {
+	var job models.Job
+	err := scanJob(j.db.QueryRow("select "+jobColumns+" from jobs where id = ?", ID), &job)
+	return job, err
+}
+
+// GetUnfinished busca o job mais recente do tipo que ainda não terminou para o
+// usuário. Retorna false se não houver nenhum.
+func (j *Jobs) GetUnfinished(jobType string, userID uint64) (models.Job, bool, error) {
+	var job models.Job
+	err := scanJob(j.db.QueryRow(
+		"select "+jobColumns+` from jobs where type = ? and user_id = ? and status in (?, ?)
+		order by id desc limit 1`,
+		jobType, userID, models.JobPending, models.JobRunning,
+	), &job)
+	if err == sql.ErrNoRows {
+		return models.Job{}, false, nil
+	}
+	if err != nil {
+		return models.Job{}, false, err
+	}
+	return job, true, nil
+}
+
+// Claim reserva até limit jobs dos tipos informados que devem rodar até now.
+// Os jobs ficam com status running e só voltam a ser reservados depois do
+// lease, caso o processo que os executava tenha parado no meio.
+func (j *Jobs) Claim(types []string, now time.Time, lease time.Duration, limit int) ([]models.Job, error) {
+	if len(types) == 0 {
+		return nil, nil
+	}
+
+	tx, err := j.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	args := []interface{}{models.JobPending, models.JobRunning, now}
+	for _, jobType := range types {
+		args = append(args, jobType)
+	}
+	args = append(args, limit)
+
+	rows, err := tx.Query(
+		"select "+jobColumns+` from jobs
+		where status in (?, ?) and run_after <= ? and type in (?`+strings.Repeat(", ?", len(types)-1)+`)
+		order by run_after limit ? for update skip locked`,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var jobs []models.Job
+	for rows.Next() {
+		var job models.Job
+		if err = scanJob(rows, &job); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range jobs {
+		jobs[i].Status = models.JobRunning
+		jobs[i].Attempts++
+		if _, err = tx.Exec(
+			"update jobs set status = ?, attempts = ?, run_after = ? where id = ?",
+			jobs[i].Status, jobs[i].Attempts, now.Add(lease), jobs[i].ID,
+		); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// Save grava o resultado de uma execução do job
+func (j *Jobs) Save(job models.Job) error {
+	var finishedAt sql.NullTime
+	if job.FinishedAt != nil {
+		finishedAt = sql.NullTime{Time: *job.FinishedAt, Valid: true}
+	}
+
+	_, err := j.db.Exec(
+		"update jobs set status = ?, result = ?, error = ?, run_after = ?, finished_at = ? where id = ?",
+		job.Status, job.Result, truncate(job.Error, 255), job.RunAfter, finishedAt, job.ID,
+	)
+	return err
+}
+
+func scanJob(row scanner, job *models.Job) error {
+	var userID sql.NullInt64
+	var finishedAt sql.NullTime
+	if err := row.Scan(
+		&job.ID,
+		&job.Type,
+		&userID,
+		&job.Status,
+		&job.Payload,
+		&job.Result,
+		&job.Error,
+		&job.Attempts,
+		&job.RunAfter,
+		&job.CreatedAt,
+		&finishedAt,
+	); err != nil {
+		return err
+	}
+
+	job.UserID = uint64(userID.Int64)
+	if finishedAt.Valid {
+		job.FinishedAt = &finishedAt.Time
+	}
+	return nil
+}
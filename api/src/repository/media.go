@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"api/src/models"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+type Media struct {
+	db *sql.DB
+}
+
+func NewMediaRepository(db *sql.DB) *Media {
+	return &Media{db: db}
+}
+
+func (m *Media) Create(media models.Media) (uint64, error) {
+	statement, err := m.db.Prepare(
+		`insert into media (user_id, storage_key, thumbnail_key, content_type, size, width, height)
+		values (?, ?, ?, ?, ?, ?, ?)`,
+	)
+	if err != nil {
+		return 0, err
+	}
+	defer statement.Close()
+
+	result, err := statement.Exec(
+		media.UserID,
+		media.Key,
+		media.ThumbnailKey,
+		media.ContentType,
+		media.Size,
+		media.Width,
+		media.Height,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	lastInsertID, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	return uint64(lastInsertID), nil
+}
+
+// Owned verifica se todas as mídias existem e pertencem ao usuário
+func (m *Media) Owned(userID uint64, mediaIDs []uint64) (bool, error) {
+	if len(mediaIDs) == 0 {
+		return true, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(mediaIDs)), ",")
+	args := []interface{}{userID}
+	for _, id := range mediaIDs {
+		args = append(args, id)
+	}
+
+	var count int
+	if err := m.db.QueryRow(
+		"select count(*) from media where user_id = ? and id in ("+placeholders+")", args...,
+	).Scan(&count); err != nil {
+		return false, err
+	}
+
+	return count == len(mediaIDs), nil
+}
+
+// attachMedia vincula as mídias à publicação, na ordem informada. Falha se
+// alguma não existir ou não pertencer ao autor, desfazendo a transação.
+func attachMedia(tx *sql.Tx, publishID, authorID uint64, mediaIDs []uint64) error {
+	if len(mediaIDs) == 0 {
+		return nil
+	}
+
+	statement, err := tx.Prepare(
+		`insert into publish_media (publish_id, media_id, position)
+		select ?, id, ? from media where id = ? and user_id = ?`,
+	)
+	if err != nil {
+		return err
+	}
+	defer statement.Close()
+
+	for position, mediaID := range mediaIDs {
+		result, err := statement.Exec(publishID, position, mediaID, authorID)
+		if err != nil {
+			return err
+		}
+
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return fmt.Errorf("mídia %d não encontrada", mediaID)
+		}
+	}
+
+	return nil
+}
+
+// GetByPublishes retorna as mídias de cada publicação informada, na ordem em que foram anexadas
+func (m *Media) GetByPublishes(publishIDs []uint64) (map[uint64][]models.Media, error) {
+	media := map[uint64][]models.Media{}
+	if len(publishIDs) == 0 {
+		return media, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(publishIDs)), ",")
+	args := make([]interface{}, len(publishIDs))
+	for i, id := range publishIDs {
+		args[i] = id
+	}
+
+	rows, err := m.db.Query(
+		`select pm.publish_id, m.id, m.user_id, m.storage_key, m.thumbnail_key, m.content_type,
+		m.size, m.width, m.height, m.created_at
+		from publish_media pm
+		inner join media m on pm.media_id = m.id
+		where pm.publish_id in (`+placeholders+`)
+		order by pm.publish_id, pm.position`,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var publishID uint64
+		var item models.Media
+		if err = rows.Scan(
+			&publishID,
+			&item.ID,
+			&item.UserID,
+			&item.Key,
+			&item.ThumbnailKey,
+			&item.ContentType,
+			&item.Size,
+			&item.Width,
+			&item.Height,
+			&item.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		media[publishID] = append(media[publishID], item)
+	}
+
+	return media, nil
+}
+
+// Exists indica se a chave pertence a alguma mídia cadastrada
+func (m *Media) Exists(key string) (bool, error) {
+	var exists bool
+	if err := m.db.QueryRow(
+		"select exists(select 1 from media where storage_key = ? or thumbnail_key = ?)", key, key,
+	).Scan(&exists); err != nil {
+		return false, err
+	}
+
+	return exists, nil
+}
@@ -0,0 +1,210 @@
+package repository
+
+import (
+	"api/src/models"
+	"database/sql"
+)
+
+type Notifications struct {
+	db *sql.DB
+}
+
+func NewNotificationsRepository(db *sql.DB) *Notifications {
+	return &Notifications{db: db}
+}
+
+// Create grava a notificação, respeitando as preferências do destinatário.
+// Retorna 0 quando a notificação não precisou ser criada.
+func (n *Notifications) Create(notification models.Notification) (uint64, error) {
+	if notification.UserID == notification.ActorID {
+		return 0, nil
+	}
+
+	enabled, err := n.isEnabled(notification.UserID, notification.Type)
+	if err != nil {
+		return 0, err
+	}
+	if !enabled {
+		return 0, nil
+	}
+
+	statement, err := n.db.Prepare(
+		"insert into notifications (user_id, actor_id, type, publish_id, report_id) values (?, ?, ?, ?, ?)",
+	)
+	if err != nil {
+		return 0, err
+	}
+	defer statement.Close()
+
+	var actorID, publishID, reportID sql.NullInt64
+	if notification.ActorID != 0 {
+		actorID = sql.NullInt64{Int64: int64(notification.ActorID), Valid: true}
+	}
+	if notification.PublishID != 0 {
+		publishID = sql.NullInt64{Int64: int64(notification.PublishID), Valid: true}
+	}
+	if notification.ReportID != 0 {
+		reportID = sql.NullInt64{Int64: int64(notification.ReportID), Valid: true}
+	}
+
+	result, err := statement.Exec(notification.UserID, actorID, notification.Type, publishID, reportID)
+	if err != nil {
+		return 0, err
+	}
+
+	lastInsertID, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	return uint64(lastInsertID), nil
+}
+
+func (n *Notifications) Get(userID, limit, offset uint64) ([]models.Notification, error) {
+	rows, err := n.db.Query(
+		`select n.id, n.user_id, n.actor_id, u.nick, n.type, n.publish_id, n.report_id, n.is_read, n.created_at
+				from notifications n
+				left join users u on n.actor_id = u.id
+				where n.user_id = ?
+				order by n.id desc
+				limit ? offset ?`,
+		userID, limit, offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notifications := []models.Notification{}
+	for rows.Next() {
+		var notification models.Notification
+		var actorID, publishID, reportID sql.NullInt64
+		var actorNick sql.NullString
+		if err = rows.Scan(
+			&notification.ID,
+			&notification.UserID,
+			&actorID,
+			&actorNick,
+			&notification.Type,
+			&publishID,
+			&reportID,
+			&notification.Read,
+			&notification.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		notification.ActorID = uint64(actorID.Int64)
+		notification.ActorNick = actorNick.String
+		notification.PublishID = uint64(publishID.Int64)
+		notification.ReportID = uint64(reportID.Int64)
+		notifications = append(notifications, notification)
+	}
+
+	return notifications, nil
+}
+
+func (n *Notifications) CountUnread(userID uint64) (uint64, error) {
+	var unread uint64
+	if err := n.db.QueryRow(
+		"select count(*) from notifications where user_id = ? and is_read = false", userID,
+	).Scan(&unread); err != nil {
+		return 0, err
+	}
+
+	return unread, nil
+}
+
+// MarkAsRead marca uma notificação do usuário como lida
+func (n *Notifications) MarkAsRead(notificationID, userID uint64) error {
+	statement, err := n.db.Prepare("update notifications set is_read = true where id = ? and user_id = ?")
+	if err != nil {
+		return err
+	}
+	defer statement.Close()
+
+	if _, err = statement.Exec(notificationID, userID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (n *Notifications) MarkAllAsRead(userID uint64) error {
+	statement, err := n.db.Prepare("update notifications set is_read = true where user_id = ? and is_read = false")
+	if err != nil {
+		return err
+	}
+	defer statement.Close()
+
+	if _, err = statement.Exec(userID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetPreferences retorna todos os tipos de notificação, habilitados por padrão
+func (n *Notifications) GetPreferences(userID uint64) ([]models.NotificationPreference, error) {
+	rows, err := n.db.Query("select type, enabled from notification_preferences where user_id = ?", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stored := map[string]bool{}
+	for rows.Next() {
+		var preference models.NotificationPreference
+		if err = rows.Scan(&preference.Type, &preference.Enabled); err != nil {
+			return nil, err
+		}
+		stored[preference.Type] = preference.Enabled
+	}
+
+	var preferences []models.NotificationPreference
+	for _, notificationType := range models.NotificationTypes {
+		enabled, ok := stored[notificationType]
+		if !ok {
+			enabled = true
+		}
+		preferences = append(preferences, models.NotificationPreference{Type: notificationType, Enabled: enabled})
+	}
+
+	return preferences, nil
+}
+
+func (n *Notifications) UpdatePreference(userID uint64, preference models.NotificationPreference) error {
+	statement, err := n.db.Prepare(
+		`insert into notification_preferences (user_id, type, enabled) values (?, ?, ?)
+				on duplicate key update enabled = values(enabled)`,
+	)
+	if err != nil {
+		return err
+	}
+	defer statement.Close()
+
+	if _, err = statement.Exec(userID, preference.Type, preference.Enabled); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (n *Notifications) isEnabled(userID uint64, notificationType string) (bool, error) {
+	row, err := n.db.Query(
+		"select enabled from notification_preferences where user_id = ? and type = ?",
+		userID, notificationType,
+	)
+	if err != nil {
+		return false, err
+	}
+	defer row.Close()
+
+	enabled := true
+	if row.Next() {
+		if err = row.Scan(&enabled); err != nil {
+			return false, err
+		}
+	}
+
+	return enabled, nil
+}
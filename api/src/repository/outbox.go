@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+)
+
+// insertOutboxEvent grava o evento na mesma transação da alteração que o
+// originou, para que nenhum evento se perca se o processo cair antes do envio
+func insertOutboxEvent(tx *sql.Tx, eventType string, userID uint64, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec(
+		"insert into webhook_outbox (event_type, user_id, payload) values (?, ?, ?)",
+		eventType, userID, string(payload),
+	); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,191 @@
+package repository
+
+import (
+	"api/src/models"
+	"database/sql"
+	"strings"
+)
+
+type Polls struct {
+	db *sql.DB
+}
+
+func NewPollsRepository(db *sql.DB) *Polls {
+	return &Polls{db: db}
+}
+
+// insertPoll grava a enquete na mesma transação da publicação
+func insertPoll(tx *sql.Tx, publishID uint64, poll models.Poll) error {
+	result, err := tx.Exec(
+		"insert into polls (publish_id, multiple, expires_at) values (?, ?, ?)",
+		publishID, poll.Multiple, poll.ExpiresAt,
+	)
+	if err != nil {
+		return err
+	}
+	pollID, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	for position, option := range poll.Options {
+		if _, err = tx.Exec(
+			"insert into poll_options (poll_id, position, text) values (?, ?, ?)",
+			pollID, position, option.Text,
+		); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetByPublishes retorna as enquetes das publicações, indexadas pelo ID da
+// publicação, com os votos de viewerID em Choices. Os resultados vêm completos
+// e devem passar por Tally antes de serem exibidos.
+func (p *Polls) GetByPublishes(publishIDs []uint64, viewerID uint64) (map[uint64]models.Poll, error) {
+	polls := map[uint64]models.Poll{}
+	if len(publishIDs) == 0 {
+		return polls, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(publishIDs)), ",")
+	args := []interface{}{viewerID}
+	for _, id := range publishIDs {
+		args = append(args, id)
+	}
+
+	rows, err := p.db.Query(
+		`select pl.publish_id, pl.id, pl.multiple, pl.expires_at, pl.voters,
+				exists(select 1 from poll_voters pv where pv.poll_id = pl.id and pv.user_id = ?),
+				o.id, o.text, o.votes
+				from polls pl
+				inner join poll_options o on o.poll_id = pl.id
+				where pl.publish_id in (`+placeholders+`)
+				order by pl.id, o.position`,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	pollPublish := map[uint64]uint64{}
+	for rows.Next() {
+		var publishID, voters, votes uint64
+		var poll models.Poll
+		var option models.PollOption
+		if err = rows.Scan(
+			&publishID,
+			&poll.ID,
+			&poll.Multiple,
+			&poll.ExpiresAt,
+			&voters,
+			&poll.Voted,
+			&option.ID,
+			&option.Text,
+			&votes,
+		); err != nil {
+			return nil, err
+		}
+
+		if stored, ok := polls[publishID]; ok {
+			poll = stored
+		} else {
+			poll.Voters = &voters
+			pollPublish[poll.ID] = publishID
+		}
+		option.Votes = &votes
+		poll.Options = append(poll.Options, option)
+		polls[publishID] = poll
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return polls, p.loadChoices(polls, pollPublish, viewerID)
+}
+
+// loadChoices preenche as opções escolhidas por viewerID em cada enquete
+func (p *Polls) loadChoices(polls map[uint64]models.Poll, pollPublish map[uint64]uint64, viewerID uint64) error {
+	if len(pollPublish) == 0 {
+		return nil
+	}
+
+	args := []interface{}{viewerID}
+	for pollID := range pollPublish {
+		args = append(args, pollID)
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(pollPublish)), ",")
+
+	rows, err := p.db.Query(
+		`select poll_id, option_id from poll_votes
+				where user_id = ? and poll_id in (`+placeholders+`)`,
+		args...,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var pollID, optionID uint64
+		if err = rows.Scan(&pollID, &optionID); err != nil {
+			return err
+		}
+
+		publishID := pollPublish[pollID]
+		poll := polls[publishID]
+		poll.Choices = append(poll.Choices, optionID)
+		polls[publishID] = poll
+	}
+
+	return rows.Err()
+}
+
+// Vote registra o voto de userID e retorna false se ele já tinha votado. A
+// chave primária de poll_voters garante um único voto por usuário mesmo com
+// requisições simultâneas, e os contadores mudam na mesma transação.
+func (p *Polls) Vote(pollID, userID uint64, choices []uint64) (bool, error) {
+	tx, err := p.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec("insert ignore into poll_voters (poll_id, user_id) values (?, ?)", pollID, userID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if affected == 0 {
+		return false, nil
+	}
+
+	for _, optionID := range choices {
+		if _, err = tx.Exec(
+			"insert into poll_votes (poll_id, user_id, option_id) values (?, ?, ?)",
+			pollID, userID, optionID,
+		); err != nil {
+			return false, err
+		}
+		if _, err = tx.Exec(
+			"update poll_options set votes = votes + 1 where id = ? and poll_id = ?",
+			optionID, pollID,
+		); err != nil {
+			return false, err
+		}
+	}
+
+	if _, err = tx.Exec("update polls set voters = voters + 1 where id = ?", pollID); err != nil {
+		return false, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -1,10 +1,21 @@
 package repository
 
 import (
+	"api/src/config"
 	"api/src/models"
 	"database/sql"
+	"strings"
+	"time"
 )
 
+// publishColumns são as colunas lidas por scanPublish, com a tabela publishes
+// como p e users como u
+const publishColumns = `p.id, p.title, p.content, p.author_id, p.likes, p.created_at, p.status, p.publish_at,
+	p.parent_id, (select count(*) from publishes r where r.parent_id = p.id and r.status = 'published'), u.nick`
+
+// Quantidade de publicações agendadas promovidas por transação
+const promoteBatchSize = 50
+
 type Publishes struct {
 	db *sql.DB
 }
@@ -14,13 +25,21 @@ func NewPublishRepository(db *sql.DB) *Publishes {
 }
 
 func (p *Publishes) Create(publish models.Publish) (uint64, error) {
-	statement, err := p.db.Prepare("insert into publishes (title, content, author_id) values (?, ?, ?)")
+	tx, err := p.db.Begin()
 	if err != nil {
 		return 0, err
 	}
-	defer statement.Close()
+	defer tx.Rollback()
+
+	var parentID sql.NullInt64
+	if publish.ParentID != 0 {
+		parentID = sql.NullInt64{Int64: int64(publish.ParentID), Valid: true}
+	}
 
-	result, err := statement.Exec(publish.Title, publish.Content, publish.AuthorID)
+	result, err := tx.Exec(
+		"insert into publishes (title, content, author_id, status, publish_at, parent_id) values (?, ?, ?, ?, ?, ?)",
+		publish.Title, publish.Content, publish.AuthorID, publish.Status, publish.PublishAt, parentID,
+	)
 	if err != nil {
 		return 0, err
 	}
@@ -28,13 +47,34 @@ func (p *Publishes) Create(publish models.Publish) (uint64, error) {
 	if err != nil {
 		return 0, err
 	}
-	return uint64(lastInsertId), nil
+
+	publish.ID = uint64(lastInsertId)
+	if publish.Poll != nil {
+		if err = insertPoll(tx, publish.ID, *publish.Poll); err != nil {
+			return 0, err
+		}
+	}
+
+	if err = attachMedia(tx, publish.ID, publish.AuthorID, publish.MediaIDs); err != nil {
+		return 0, err
+	}
+
+	if publish.IsPublished() {
+		if err = announcePublish(tx, publish); err != nil {
+			return 0, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, err
+	}
+	return publish.ID, nil
 }
 
 func (p *Publishes) GetPublish(publishId uint64) (models.Publish, error) {
 	row, err := p.db.Query(
-		`select p.*, u.nick from publishes p 
-    			inner join users u on p.author_id = u.id 
+		`select `+publishColumns+` from publishes p
+				inner join users u on p.author_id = u.id
 				where p.id = ?`,
 		publishId,
 	)
@@ -45,15 +85,7 @@ func (p *Publishes) GetPublish(publishId uint64) (models.Publish, error) {
 
 	var publish models.Publish
 	if row.Next() {
-		if err = row.Scan(
-			&publish.ID,
-			&publish.Title,
-			&publish.Content,
-			&publish.AuthorID,
-			&publish.Likes,
-			&publish.CreatedAt,
-			&publish.AuthorNick,
-		); err != nil {
+		if err = scanPublish(row, &publish); err != nil {
 			return models.Publish{}, err
 		}
 	}
@@ -61,14 +93,25 @@ func (p *Publishes) GetPublish(publishId uint64) (models.Publish, error) {
 	return publish, nil
 }
 
+// GetPublishes monta o feed a partir da timeline do usuário, completada na
+// leitura com as publicações dos autores seguidos que não recebem fan-out
 func (p *Publishes) GetPublishes(userId uint64) ([]models.Publish, error) {
 	rows, err := p.db.Query(
-		`select distinct p.*, u.nick from publishes p 
-    			inner join users u on p.author_id = u.id 
-				inner join followers f on p.author_id = f.user_id 
-				where u.id = ? or f.follower_id = ?
+		`select `+publishColumns+` from publishes p
+				inner join users u on p.author_id = u.id
+				where p.status = 'published' and u.status = 'active'
+				and (
+					p.id in (select publish_id from timelines where user_id = ?)
+					or p.author_id in (
+						select f.user_id from followers f
+						where f.follower_id = ? and (select count(*) from followers where user_id = f.user_id) > ?
+					)
+				)
+				and p.author_id not in (select blocked_id from blocks where user_id = ?)
+				and p.author_id not in (select user_id from blocks where blocked_id = ?)
+				and p.author_id not in (select muted_id from mutes where user_id = ?)
 				order by 1 desc`,
-		userId, userId,
+		userId, userId, config.FanOutThreshold, userId, userId, userId,
 	)
 	if err != nil {
 		return nil, err
@@ -78,15 +121,7 @@ func (p *Publishes) GetPublishes(userId uint64) ([]models.Publish, error) {
 	var publishes []models.Publish
 	for rows.Next() {
 		var publish models.Publish
-		if err = rows.Scan(
-			&publish.ID,
-			&publish.Title,
-			&publish.Content,
-			&publish.AuthorID,
-			&publish.Likes,
-			&publish.CreatedAt,
-			&publish.AuthorNick,
-		); err != nil {
+		if err = scanPublish(rows, &publish); err != nil {
 			return nil, err
 		}
 		publishes = append(publishes, publish)
@@ -96,12 +131,12 @@ func (p *Publishes) GetPublishes(userId uint64) ([]models.Publish, error) {
 }
 
 func (p *Publishes) Update(publish models.Publish, userId uint64) error {
-	statement, err := p.db.Prepare("update publishes set title = ?, content = ? where author_id = ?")
+	statement, err := p.db.Prepare("update publishes set title = ?, content = ? where id = ? and author_id = ?")
 	if err != nil {
 		return err
 	}
 
-	_, err = statement.Exec(publish.Title, publish.Content, userId)
+	_, err = statement.Exec(publish.Title, publish.Content, publish.ID, userId)
 	if err != nil {
 		return err
 	}
@@ -109,23 +144,86 @@ func (p *Publishes) Update(publish models.Publish, userId uint64) error {
 	return nil
 }
 
+// Delete apaga a publicação. Se ela tiver respostas, vira uma publicação
+// deleted sem conteúdo para que a thread mantenha o formato.
 func (p *Publishes) Delete(publishId uint64) error {
-	statement, err := p.db.Prepare("delete from publishes where id = ?")
+	tx, err := p.db.Begin()
 	if err != nil {
 		return err
 	}
-	defer statement.Close()
-	_, err = statement.Exec(publishId)
+	defer tx.Rollback()
+
+	if err = deletePublish(tx, publishId); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// deletePublish faz a exclusão de Delete dentro de uma transação já aberta
+func deletePublish(tx *sql.Tx, publishId uint64) error {
+	var authorID uint64
+	var status string
+	var parentID sql.NullInt64
+	err := tx.QueryRow(
+		"select author_id, status, parent_id from publishes where id = ?", publishId,
+	).Scan(&authorID, &status, &parentID)
+	if err == sql.ErrNoRows {
+		return nil
+	}
 	if err != nil {
 		return err
 	}
 
+	var replies uint64
+	if err = tx.QueryRow("select count(*) from publishes where parent_id = ?", publishId).Scan(&replies); err != nil {
+		return err
+	}
+
+	if replies > 0 {
+		if _, err = tx.Exec(
+			`update publishes set status = 'deleted', title = '', content = '', likes = 0, publish_at = null
+			where id = ?`,
+			publishId,
+		); err != nil {
+			return err
+		}
+		if _, err = tx.Exec("delete from publish_likes where publish_id = ?", publishId); err != nil {
+			return err
+		}
+		if _, err = tx.Exec("delete from timelines where publish_id = ?", publishId); err != nil {
+			return err
+		}
+		if _, err = tx.Exec("delete from bookmarks where publish_id = ?", publishId); err != nil {
+			return err
+		}
+	} else {
+		if _, err = tx.Exec("delete from publishes where id = ?", publishId); err != nil {
+			return err
+		}
+		if err = pruneTombstones(tx, uint64(parentID.Int64)); err != nil {
+			return err
+		}
+	}
+
+	// Rascunhos nunca foram anunciados, então não há o que avisar aos webhooks
+	if status == models.PublishPublished {
+		if err = insertOutboxEvent(tx, models.EventPublishDeleted, authorID, models.Publish{
+			ID:       publishId,
+			AuthorID: authorID,
+		}); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
 func (p *Publishes) GetPublishesByUser(userID uint64) ([]models.Publish, error) {
 	rows, err := p.db.Query(
-		"select p.*, u.nick from publishes p inner join users u on p.author_id = u.id where p.author_id = ?",
+		`select `+publishColumns+` from publishes p
+				inner join users u on p.author_id = u.id
+				where p.author_id = ? and p.status = 'published'`,
 		userID,
 	)
 	if err != nil {
@@ -133,6 +231,167 @@ func (p *Publishes) GetPublishesByUser(userID uint64) ([]models.Publish, error)
 	}
 	defer rows.Close()
 	var publishes []models.Publish
+	for rows.Next() {
+		var publish models.Publish
+		if err = scanPublish(rows, &publish); err != nil {
+			return nil, err
+		}
+		publishes = append(publishes, publish)
+	}
+
+	return publishes, nil
+}
+
+// GetAllByAuthor lista todas as publicações e respostas do autor, inclusive
+// rascunhos e agendadas, das mais antigas para as mais recentes
+func (p *Publishes) GetAllByAuthor(authorID uint64) ([]models.Publish, error) {
+	return p.list(
+		`select `+publishColumns+` from publishes p
+				inner join users u on p.author_id = u.id
+				where p.author_id = ? and p.status <> 'deleted'
+				order by p.id`,
+		authorID,
+	)
+}
+
+// GetLikedBy lista as publicações curtidas pelo usuário
+func (p *Publishes) GetLikedBy(userID uint64) ([]models.Publish, error) {
+	return p.list(
+		`select `+publishColumns+` from publish_likes l
+				inner join publishes p on l.publish_id = p.id
+				inner join users u on p.author_id = u.id
+				where l.user_id = ? and p.status = 'published'
+				order by p.id`,
+		userID,
+	)
+}
+
+func (p *Publishes) list(query string, args ...interface{}) ([]models.Publish, error) {
+	rows, err := p.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	publishes := []models.Publish{}
+	for rows.Next() {
+		var publish models.Publish
+		if err = scanPublish(rows, &publish); err != nil {
+			return nil, err
+		}
+		publishes = append(publishes, publish)
+	}
+
+	return publishes, rows.Err()
+}
+
+// Like registra a curtida do usuário e retorna false se ela já existia. A
+// curtida e o contador são gravados na mesma transação.
+func (p *Publishes) Like(publishID, userID uint64) (bool, error) {
+	tx, err := p.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec("insert ignore into publish_likes (publish_id, user_id) values (?, ?)", publishID, userID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if affected == 0 {
+		return false, nil
+	}
+
+	if _, err = tx.Exec("update publishes set likes = likes + 1 where id = ?", publishID); err != nil {
+		return false, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (p *Publishes) Unlike(publishID, userID uint64) error {
+	tx, err := p.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec("delete from publish_likes where publish_id = ? and user_id = ?", publishID, userID)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return nil
+	}
+
+	if _, err = tx.Exec("update publishes set likes = likes - 1 where id = ? and likes > 0", publishID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetByIDs retorna as publicações na mesma ordem dos IDs informados
+func (p *Publishes) GetByIDs(publishIDs []uint64) ([]models.Publish, error) {
+	publishes := []models.Publish{}
+	if len(publishIDs) == 0 {
+		return publishes, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(publishIDs)), ",")
+	args := make([]interface{}, len(publishIDs))
+	for i, id := range publishIDs {
+		args[i] = id
+	}
+
+	rows, err := p.db.Query(
+		`select `+publishColumns+` from publishes p
+				inner join users u on p.author_id = u.id
+				where p.id in (`+placeholders+`) and p.status = 'published' and u.status = 'active'`,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	byID := map[uint64]models.Publish{}
+	for rows.Next() {
+		var publish models.Publish
+		if err = scanPublish(rows, &publish); err != nil {
+			return nil, err
+		}
+		byID[publish.ID] = publish
+	}
+
+	for _, id := range publishIDs {
+		if publish, ok := byID[id]; ok {
+			publishes = append(publishes, publish)
+		}
+	}
+
+	return publishes, nil
+}
+
+// Each percorre todas as publicações, usado para reconstruir índices
+func (p *Publishes) Each(fn func(publish models.Publish) error) error {
+	rows, err := p.db.Query("select id, title, content, author_id, created_at from publishes where status = 'published'")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
 	for rows.Next() {
 		var publish models.Publish
 		if err = rows.Scan(
@@ -140,10 +399,99 @@ func (p *Publishes) GetPublishesByUser(userID uint64) ([]models.Publish, error)
 			&publish.Title,
 			&publish.Content,
 			&publish.AuthorID,
-			&publish.Likes,
 			&publish.CreatedAt,
-			&publish.AuthorNick,
 		); err != nil {
+			return err
+		}
+		if err = fn(publish); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// GetFeedCandidates retorna publicações recentes de quem o usuário segue, dele
+// mesmo e de quem é seguido por quem ele segue (segundo grau). Affinity conta
+// quantas publicações do autor o usuário já curtiu e Comments, as respostas.
+func (p *Publishes) GetFeedCandidates(userID uint64, since time.Time, limit int) ([]models.FeedCandidate, error) {
+	rows, err := p.db.Query(
+		`select `+publishColumns+`,
+				p.author_id <> ? and p.author_id not in (select user_id from followers where follower_id = ?),
+				(select count(*) from publish_likes pl inner join publishes lp on pl.publish_id = lp.id
+					where pl.user_id = ? and lp.author_id = p.author_id)
+				from publishes p
+				inner join users u on p.author_id = u.id
+				where p.created_at >= ? and p.status = 'published' and u.status = 'active'
+				and (
+					p.author_id = ?
+					or p.author_id in (select user_id from followers where follower_id = ?)
+					or (not u.is_private and p.author_id in (
+						select f2.user_id from followers f1
+						inner join followers f2 on f1.user_id = f2.follower_id
+						where f1.follower_id = ?
+					))
+				)
+				and p.author_id not in (select blocked_id from blocks where user_id = ?)
+				and p.author_id not in (select user_id from blocks where blocked_id = ?)
+				and p.author_id not in (select muted_id from mutes where user_id = ?)
+				order by p.created_at desc
+				limit ?`,
+		userID, userID, userID, since, userID, userID, userID, userID, userID, userID, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var candidates []models.FeedCandidate
+	for rows.Next() {
+		var candidate models.FeedCandidate
+		if err = scanPublish(rows, &candidate.Publish, &candidate.SecondDegree, &candidate.Affinity); err != nil {
+			return nil, err
+		}
+		candidate.Comments = candidate.Publish.ReplyCount
+		candidates = append(candidates, candidate)
+	}
+
+	return candidates, nil
+}
+
+// HasDuplicate indica se o autor tem, desde since, outra publicação com o mesmo título e conteúdo
+func (p *Publishes) HasDuplicate(authorID, excludeID uint64, title, content string, since time.Time) (bool, error) {
+	var duplicated bool
+	if err := p.db.QueryRow(
+		`select exists(
+			select 1 from publishes
+			where author_id = ? and id <> ? and title = ? and content = ?
+			and created_at >= ? and status <> 'deleted'
+		)`,
+		authorID, excludeID, title, content, since,
+	).Scan(&duplicated); err != nil {
+		return false, err
+	}
+
+	return duplicated, nil
+}
+
+// GetDrafts lista os rascunhos e as publicações agendadas do autor
+func (p *Publishes) GetDrafts(authorID uint64) ([]models.Publish, error) {
+	rows, err := p.db.Query(
+		`select `+publishColumns+` from publishes p
+				inner join users u on p.author_id = u.id
+				where p.author_id = ? and p.status in ('draft', 'scheduled')
+				order by p.publish_at is null, p.publish_at, p.id desc`,
+		authorID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	publishes := []models.Publish{}
+	for rows.Next() {
+		var publish models.Publish
+		if err = scanPublish(rows, &publish); err != nil {
 			return nil, err
 		}
 		publishes = append(publishes, publish)
@@ -151,3 +499,152 @@ func (p *Publishes) GetPublishesByUser(userID uint64) ([]models.Publish, error)
 
 	return publishes, nil
 }
+
+// Schedule agenda um rascunho, ou reagenda uma publicação agendada, para publishAt
+func (p *Publishes) Schedule(publishID uint64, publishAt time.Time) error {
+	statement, err := p.db.Prepare(
+		"update publishes set status = 'scheduled', publish_at = ? where id = ? and status in ('draft', 'scheduled')",
+	)
+	if err != nil {
+		return err
+	}
+	defer statement.Close()
+
+	if _, err = statement.Exec(publishAt, publishID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Publish publica imediatamente um rascunho ou uma publicação agendada.
+// Retorna false se a publicação já tinha sido publicada.
+func (p *Publishes) Publish(publishID uint64, now time.Time) (models.Publish, bool, error) {
+	tx, err := p.db.Begin()
+	if err != nil {
+		return models.Publish{}, false, err
+	}
+	defer tx.Rollback()
+
+	publishes, err := promote(tx, now, "p.id = ?", publishID)
+	if err != nil || len(publishes) == 0 {
+		return models.Publish{}, false, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return models.Publish{}, false, err
+	}
+	return publishes[0], true, nil
+}
+
+// PromoteDue publica as publicações agendadas cujo horário já passou. As linhas
+// são travadas com skip locked, então várias instâncias podem rodar ao mesmo
+// tempo sem que uma publicação seja promovida duas vezes.
+func (p *Publishes) PromoteDue(now time.Time) ([]models.Publish, error) {
+	tx, err := p.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	publishes, err := promote(tx, now, "p.status = 'scheduled' and p.publish_at <= ? and u.status = 'active'", now)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	return publishes, nil
+}
+
+// promote trava os rascunhos e agendadas que atendem à condição, marca
+// como publicadas em now e faz o fan-out e o registro no outbox
+func promote(tx *sql.Tx, now time.Time, condition string, args ...interface{}) ([]models.Publish, error) {
+	rows, err := tx.Query(
+		`select `+publishColumns+` from publishes p
+				inner join users u on p.author_id = u.id
+				where p.status in ('draft', 'scheduled') and `+condition+`
+				order by p.publish_at, p.id
+				limit ?
+				for update of p skip locked`,
+		append(args, promoteBatchSize)...,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var publishes []models.Publish
+	for rows.Next() {
+		var publish models.Publish
+		if err = scanPublish(rows, &publish); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		publishes = append(publishes, publish)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range publishes {
+		publishes[i].Status = models.PublishPublished
+		publishes[i].CreatedAt = now
+		publishes[i].PublishAt = nil
+
+		if _, err = tx.Exec(
+			"update publishes set status = 'published', created_at = ?, publish_at = null where id = ?",
+			now, publishes[i].ID,
+		); err != nil {
+			return nil, err
+		}
+
+		if err = announcePublish(tx, publishes[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return publishes, nil
+}
+
+// announcePublish registra a publicação no outbox e a copia para as timelines
+func announcePublish(tx *sql.Tx, publish models.Publish) error {
+	if err := insertOutboxEvent(tx, models.EventPublishCreated, publish.AuthorID, publish); err != nil {
+		return err
+	}
+
+	return fanOutPublish(tx, publish.ID, publish.AuthorID)
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanPublish lê as colunas de publishColumns. Colunas selecionadas depois
+// delas são lidas em extra, na mesma ordem.
+func scanPublish(row scanner, publish *models.Publish, extra ...interface{}) error {
+	var publishAt sql.NullTime
+	var parentID sql.NullInt64
+	if err := row.Scan(append([]interface{}{
+		&publish.ID,
+		&publish.Title,
+		&publish.Content,
+		&publish.AuthorID,
+		&publish.Likes,
+		&publish.CreatedAt,
+		&publish.Status,
+		&publishAt,
+		&parentID,
+		&publish.ReplyCount,
+		&publish.AuthorNick,
+	}, extra...)...); err != nil {
+		return err
+	}
+
+	if publishAt.Valid {
+		publish.PublishAt = &publishAt.Time
+	}
+	publish.ParentID = uint64(parentID.Int64)
+	return nil
+}
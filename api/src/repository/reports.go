@@ -0,0 +1,263 @@
+package repository
+
+import (
+	"api/src/models"
+	"database/sql"
+)
+
+type Reports struct {
+	db *sql.DB
+}
+
+func NewReportsRepository(db *sql.DB) *Reports {
+	return &Reports{db: db}
+}
+
+// Create registra a denúncia. Se o mesmo usuário já tiver uma denúncia em
+// aberto sobre o mesmo alvo, retorna o ID dela em vez de criar outra.
+// Denúncias automáticas têm ReporterID 0.
+func (r *Reports) Create(report models.Report) (uint64, error) {
+	var reporterID, publishID sql.NullInt64
+	if report.ReporterID != 0 {
+		reporterID = sql.NullInt64{Int64: int64(report.ReporterID), Valid: true}
+	}
+	if report.PublishID != 0 {
+		publishID = sql.NullInt64{Int64: int64(report.PublishID), Valid: true}
+	}
+
+	var existingID uint64
+	err := r.db.QueryRow(
+		`select id from reports
+		where reporter_id <=> ? and target_type = ? and target_user_id = ? and publish_id <=> ?
+		and status in ('open', 'claimed')`,
+		reporterID, report.TargetType, report.TargetUserID, publishID,
+	).Scan(&existingID)
+	if err == nil {
+		return existingID, nil
+	}
+	if err != sql.ErrNoRows {
+		return 0, err
+	}
+
+	result, err := r.db.Exec(
+		`insert into reports (reporter_id, target_type, target_user_id, publish_id, reason, details)
+		values (?, ?, ?, ?, ?, ?)`,
+		reporterID, report.TargetType, report.TargetUserID, publishID, report.Reason, report.Details,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	lastInsertID, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	return uint64(lastInsertID), nil
+}
+
+const reportColumns = `r.id, r.reporter_id, coalesce(u.nick, ''), r.target_type, r.target_user_id, r.publish_id,
+	r.reason, r.details, r.status, r.moderator_id, r.created_at, r.updated_at`
+
+// Get lista a fila de moderação com as denúncias no estado informado, das mais antigas para as mais novas
+func (r *Reports) Get(status string, limit, offset uint64) ([]models.Report, error) {
+	rows, err := r.db.Query(
+		`select `+reportColumns+` from reports r
+		left join users u on r.reporter_id = u.id
+		where r.status = ?
+		order by r.id
+		limit ? offset ?`,
+		status, limit, offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reports := []models.Report{}
+	for rows.Next() {
+		var report models.Report
+		if err = scanReport(rows, &report); err != nil {
+			return nil, err
+		}
+		reports = append(reports, report)
+	}
+
+	return reports, nil
+}
+
+// GetByID retorna a denúncia com o histórico de ações de moderação
+func (r *Reports) GetByID(reportID uint64) (models.Report, error) {
+	var report models.Report
+	err := scanReport(r.db.QueryRow(
+		`select `+reportColumns+` from reports r
+		left join users u on r.reporter_id = u.id
+		where r.id = ?`,
+		reportID,
+	), &report)
+	if err == sql.ErrNoRows {
+		return models.Report{}, nil
+	}
+	if err != nil {
+		return models.Report{}, err
+	}
+
+	rows, err := r.db.Query(
+		`select a.id, a.report_id, a.moderator_id, coalesce(u.nick, ''), a.action, a.reason, a.created_at
+		from moderation_actions a
+		left join users u on a.moderator_id = u.id
+		where a.report_id = ?
+		order by a.id`,
+		reportID,
+	)
+	if err != nil {
+		return models.Report{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var action models.ModerationAction
+		var moderatorID sql.NullInt64
+		if err = rows.Scan(
+			&action.ID,
+			&action.ReportID,
+			&moderatorID,
+			&action.ModeratorNick,
+			&action.Action,
+			&action.Reason,
+			&action.CreatedAt,
+		); err != nil {
+			return models.Report{}, err
+		}
+		action.ModeratorID = uint64(moderatorID.Int64)
+		report.Actions = append(report.Actions, action)
+	}
+
+	return report, nil
+}
+
+// Act aplica a ação de moderação e a registra na mesma transação. Na remoção
+// de conteúdo a publicação denunciada é apagada e as outras denúncias abertas
+// contra ela são encerradas junto; elas são retornadas para que os
+// denunciantes sejam avisados. Retorna false quando a denúncia já foi
+// encerrada ou está com outro moderador.
+func (r *Reports) Act(reportID uint64, action models.ModerationAction) (bool, []models.Report, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, nil, err
+	}
+	defer tx.Rollback()
+
+	var status string
+	var moderatorID, publishID sql.NullInt64
+	err = tx.QueryRow(
+		"select status, moderator_id, publish_id from reports where id = ? for update", reportID,
+	).Scan(&status, &moderatorID, &publishID)
+	if err == sql.ErrNoRows {
+		return false, nil, nil
+	}
+	if err != nil {
+		return false, nil, err
+	}
+
+	if status != models.ReportOpen && status != models.ReportClaimed {
+		return false, nil, nil
+	}
+	if moderatorID.Valid && uint64(moderatorID.Int64) != action.ModeratorID {
+		return false, nil, nil
+	}
+
+	closed := []models.Report{{ID: reportID}}
+	newStatus := models.ReportResolved
+	switch action.Action {
+	case models.ModerationClaim:
+		newStatus = models.ReportClaimed
+	case models.ModerationDismiss:
+		newStatus = models.ReportDismissed
+	case models.ModerationRemoveContent:
+		if publishID.Valid {
+			// Antes de apagar, já que a exclusão anula o publish_id das denúncias
+			others, err := openReportsOf(tx, uint64(publishID.Int64), reportID)
+			if err != nil {
+				return false, nil, err
+			}
+			closed = append(closed, others...)
+
+			if err = deletePublish(tx, uint64(publishID.Int64)); err != nil {
+				return false, nil, err
+			}
+		}
+	}
+
+	for _, report := range closed {
+		if _, err = tx.Exec(
+			"update reports set status = ?, moderator_id = ? where id = ?",
+			newStatus, action.ModeratorID, report.ID,
+		); err != nil {
+			return false, nil, err
+		}
+
+		if _, err = tx.Exec(
+			"insert into moderation_actions (report_id, moderator_id, action, reason) values (?, ?, ?, ?)",
+			report.ID, action.ModeratorID, action.Action, action.Reason,
+		); err != nil {
+			return false, nil, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return false, nil, err
+	}
+	return true, closed[1:], nil
+}
+
+// openReportsOf trava e retorna as outras denúncias ainda abertas contra a publicação
+func openReportsOf(tx *sql.Tx, publishID, exceptID uint64) ([]models.Report, error) {
+	rows, err := tx.Query(
+		`select id, reporter_id from reports
+		where publish_id = ? and id <> ? and status in (?, ?) for update`,
+		publishID, exceptID, models.ReportOpen, models.ReportClaimed,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reports []models.Report
+	for rows.Next() {
+		var report models.Report
+		var reporterID sql.NullInt64
+		if err = rows.Scan(&report.ID, &reporterID); err != nil {
+			return nil, err
+		}
+		report.ReporterID = uint64(reporterID.Int64)
+		reports = append(reports, report)
+	}
+
+	return reports, rows.Err()
+}
+
+func scanReport(row scanner, report *models.Report) error {
+	var reporterID, publishID, moderatorID sql.NullInt64
+	if err := row.Scan(
+		&report.ID,
+		&reporterID,
+		&report.ReporterNick,
+		&report.TargetType,
+		&report.TargetUserID,
+		&publishID,
+		&report.Reason,
+		&report.Details,
+		&report.Status,
+		&moderatorID,
+		&report.CreatedAt,
+		&report.UpdatedAt,
+	); err != nil {
+		return err
+	}
+
+	report.ReporterID = uint64(reporterID.Int64)
+	report.PublishID = uint64(publishID.Int64)
+	report.ModeratorID = uint64(moderatorID.Int64)
+	return nil
+}
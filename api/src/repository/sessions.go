@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"api/src/models"
+	"database/sql"
+	"time"
+)
+
+// Intervalo mínimo entre as atualizações de last_seen_at de uma sessão
+const sessionTouchInterval = time.Minute
+
+type Sessions struct {
+	db *sql.DB
+}
+
+func NewSessionsRepository(db *sql.DB) *Sessions {
+	return &Sessions{db: db}
+}
+
+func (s *Sessions) Create(session models.Session) (uint64, error) {
+	session.UserAgent = truncate(session.UserAgent, 255)
+
+	result, err := s.db.Exec(
+		"insert into sessions (user_id, device, user_agent, ip, expires_at) values (?, ?, ?, ?, ?)",
+		session.UserID, session.Device, session.UserAgent, session.IP, session.ExpiresAt,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	ID, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	return uint64(ID), nil
+}
+
+// Touch confere se a sessão do usuário continua válida e atualiza a última
+// atividade, no máximo uma vez por sessionTouchInterval
+func (s *Sessions) Touch(sessionID, userID uint64, now time.Time) (bool, error) {
+	var active bool
+	err := s.db.QueryRow(
+		"select revoked_at is null and expires_at > ? from sessions where id = ? and user_id = ?",
+		now, sessionID, userID,
+	).Scan(&active)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil || !active {
+		return false, err
+	}
+
+	if _, err = s.db.Exec(
+		"update sessions set last_seen_at = ? where id = ? and last_seen_at < ?",
+		now, sessionID, now.Add(-sessionTouchInterval),
+	); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// GetActive lista as sessões não revogadas e não expiradas do usuário
+func (s *Sessions) GetActive(userID uint64) ([]models.Session, error) {
+	rows, err := s.db.Query(
+		`select id, user_id, device, user_agent, ip, created_at, last_seen_at, expires_at from sessions
+		where user_id = ? and revoked_at is null and expires_at > ?
+		order by last_seen_at desc`,
+		userID, time.Now(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []models.Session{}
+	for rows.Next() {
+		var session models.Session
+		if err = rows.Scan(
+			&session.ID,
+			&session.UserID,
+			&session.Device,
+			&session.UserAgent,
+			&session.IP,
+			&session.CreatedAt,
+			&session.LastSeenAt,
+			&session.ExpiresAt,
+		); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+
+	return sessions, rows.Err()
+}
+
+// Revoke encerra a sessão do usuário e retorna false se ela não existe ou já
+// estava encerrada
+func (s *Sessions) Revoke(sessionID, userID uint64) (bool, error) {
+	result, err := s.db.Exec(
+		"update sessions set revoked_at = ? where id = ? and user_id = ? and revoked_at is null",
+		time.Now(), sessionID, userID,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// RevokeAll encerra todas as sessões do usuário exceto exceptID, que pode ser
+// 0 para encerrar todas. Retorna quantas foram encerradas.
+func (s *Sessions) RevokeAll(userID, exceptID uint64) (int64, error) {
+	result, err := s.db.Exec(
+		"update sessions set revoked_at = ? where user_id = ? and id <> ? and revoked_at is null",
+		time.Now(), userID, exceptID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
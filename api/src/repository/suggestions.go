@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"api/src/models"
+	"api/src/suggestions"
+	"database/sql"
+	"strings"
+	"time"
+)
+
+const (
+	// Usuários mais populares considerados quando há poucos amigos de amigos
+	popularCandidates = 20
+	// Janela de publicações usada para comparar hashtags
+	hashtagWindow = 30 * 24 * time.Hour
+)
+
+type Suggestions struct {
+	db *sql.DB
+}
+
+func NewSuggestionsRepository(db *sql.DB) *Suggestions {
+	return &Suggestions{db: db}
+}
+
+// LoadGraph carrega do banco o grafo usado para ranquear as sugestões do usuário
+func (s *Suggestions) LoadGraph(viewerID uint64) (suggestions.Graph, error) {
+	graph := suggestions.Graph{
+		ViewerID:   viewerID,
+		Following:  map[uint64]bool{},
+		FollowedBy: map[uint64][]uint64{},
+		Hashtags:   map[uint64]map[string]bool{},
+		Followers:  map[uint64]uint64{},
+		Excluded:   map[uint64]bool{},
+	}
+
+	if err := s.scanPairs(
+		"select user_id, 0 from followers where follower_id = ?",
+		[]interface{}{viewerID},
+		func(userID, _ uint64) { graph.Following[userID] = true },
+	); err != nil {
+		return suggestions.Graph{}, err
+	}
+
+	candidates := map[uint64]bool{}
+	if err := s.scanPairs(
+		`select f1.user_id, f2.user_id from followers f1
+		inner join followers f2 on f2.follower_id = f1.user_id
+		where f1.follower_id = ?`,
+		[]interface{}{viewerID},
+		func(friendID, userID uint64) {
+			graph.FollowedBy[friendID] = append(graph.FollowedBy[friendID], userID)
+			candidates[userID] = true
+		},
+	); err != nil {
+		return suggestions.Graph{}, err
+	}
+
+	if err := s.scanPairs(
+		"select user_id, count(*) from followers group by user_id order by 2 desc, 1 limit ?",
+		[]interface{}{popularCandidates},
+		func(userID, _ uint64) {
+			graph.Extra = append(graph.Extra, userID)
+			candidates[userID] = true
+		},
+	); err != nil {
+		return suggestions.Graph{}, err
+	}
+
+	if err := s.scanPairs(
+		`select blocked_id, 0 from blocks where user_id = ?
+		union select user_id, 0 from blocks where blocked_id = ?
+		union select muted_id, 0 from mutes where user_id = ?
+		union select dismissed_id, 0 from suggestion_dismissals where user_id = ?
+		union select user_id, 0 from follow_requests where requester_id = ?`,
+		[]interface{}{viewerID, viewerID, viewerID, viewerID, viewerID},
+		func(userID, _ uint64) { graph.Excluded[userID] = true },
+	); err != nil {
+		return suggestions.Graph{}, err
+	}
+
+	if len(candidates) == 0 {
+		return graph, nil
+	}
+
+	ids := []interface{}{}
+	for userID := range candidates {
+		ids = append(ids, userID)
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",")
+
+	if err := s.scanPairs(
+		"select user_id, count(*) from followers where user_id in ("+placeholders+") group by user_id",
+		ids,
+		func(userID, followers uint64) { graph.Followers[userID] = followers },
+	); err != nil {
+		return suggestions.Graph{}, err
+	}
+
+	rows, err := s.db.Query(
+		`select author_id, title, content from publishes
+		where author_id in (`+placeholders+`, ?) and created_at >= ? and status = 'published'`,
+		append(ids, viewerID, time.Now().Add(-hashtagWindow))...,
+	)
+	if err != nil {
+		return suggestions.Graph{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var publish models.Publish
+		if err = rows.Scan(&publish.AuthorID, &publish.Title, &publish.Content); err != nil {
+			return suggestions.Graph{}, err
+		}
+		for _, hashtag := range publish.Hashtags() {
+			if graph.Hashtags[publish.AuthorID] == nil {
+				graph.Hashtags[publish.AuthorID] = map[string]bool{}
+			}
+			graph.Hashtags[publish.AuthorID][hashtag] = true
+		}
+	}
+
+	return graph, nil
+}
+
+func (s *Suggestions) Dismiss(userID, dismissedID uint64) error {
+	statement, err := s.db.Prepare("insert ignore into suggestion_dismissals (user_id, dismissed_id) values (?, ?)")
+	if err != nil {
+		return err
+	}
+	defer statement.Close()
+
+	if _, err = statement.Exec(userID, dismissedID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// scanPairs executa uma consulta de duas colunas inteiras chamando fn para cada linha
+func (s *Suggestions) scanPairs(query string, args []interface{}, fn func(first, second uint64)) error {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var first, second uint64
+		if err = rows.Scan(&first, &second); err != nil {
+			return err
+		}
+		fn(first, second)
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"api/src/models"
+	"database/sql"
+	"strings"
+)
+
+// Limite de segurança para a subida até a raiz da thread
+const maxThreadAncestors = 1000
+
+// GetAncestors retorna as publicações respondidas por publishID até a raiz,
+// começando pela raiz
+func (p *Publishes) GetAncestors(publishID uint64) ([]models.Publish, error) {
+	rows, err := p.db.Query(
+		`with recursive ancestors (id, parent_id, depth) as (
+			select id, parent_id, 0 from publishes where id = ?
+			union all
+			select p.id, p.parent_id, a.depth + 1 from publishes p
+			inner join ancestors a on p.id = a.parent_id
+			where a.depth < ?
+		)
+		select `+publishColumns+` from ancestors a
+		inner join publishes p on p.id = a.id
+		inner join users u on p.author_id = u.id
+		where a.depth > 0
+		order by a.depth desc`,
+		publishID, maxThreadAncestors,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	publishes := []models.Publish{}
+	for rows.Next() {
+		var publish models.Publish
+		if err = scanPublish(rows, &publish); err != nil {
+			return nil, err
+		}
+		publishes = append(publishes, publish)
+	}
+
+	return publishes, nil
+}
+
+// GetDescendants retorna, em lista plana, uma página das respostas diretas a
+// publishID e as respostas delas até depth níveis, em ordem cronológica.
+// Rascunhos e agendadas ficam de fora.
+func (p *Publishes) GetDescendants(publishID, depth, limit, offset uint64) ([]models.Publish, error) {
+	rows, err := p.db.Query(
+		`with recursive descendants (id, depth) as (
+			select id, 1 from (
+				select id from publishes
+				where parent_id = ? and status in ('published', 'deleted')
+				order by id limit ? offset ?
+			) page
+			union all
+			select p.id, d.depth + 1 from publishes p
+			inner join descendants d on p.parent_id = d.id
+			where d.depth < ? and p.status in ('published', 'deleted')
+		)
+		select `+publishColumns+` from descendants d
+		inner join publishes p on p.id = d.id
+		inner join users u on p.author_id = u.id
+		order by p.id`,
+		publishID, limit, offset, depth,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	publishes := []models.Publish{}
+	for rows.Next() {
+		var publish models.Publish
+		if err = scanPublish(rows, &publish); err != nil {
+			return nil, err
+		}
+		publishes = append(publishes, publish)
+	}
+
+	return publishes, nil
+}
+
+// GetParents retorna as publicações respondidas, inclusive as apagadas, indexadas pelo ID
+func (p *Publishes) GetParents(parentIDs []uint64) (map[uint64]models.Publish, error) {
+	parents := map[uint64]models.Publish{}
+	if len(parentIDs) == 0 {
+		return parents, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(parentIDs)), ",")
+	args := make([]interface{}, len(parentIDs))
+	for i, id := range parentIDs {
+		args[i] = id
+	}
+
+	rows, err := p.db.Query(
+		`select `+publishColumns+` from publishes p
+				inner join users u on p.author_id = u.id
+				where p.id in (`+placeholders+`) and p.status in ('published', 'deleted')`,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var publish models.Publish
+		if err = scanPublish(rows, &publish); err != nil {
+			return nil, err
+		}
+		parents[publish.ID] = publish
+	}
+
+	return parents, nil
+}
+
+// pruneTombstones remove, subindo a thread a partir de publishID, as
+// publicações apagadas que ficaram sem nenhuma resposta
+func pruneTombstones(tx *sql.Tx, publishID uint64) error {
+	for publishID != 0 {
+		var status string
+		var parentID sql.NullInt64
+		err := tx.QueryRow(
+			"select status, parent_id from publishes where id = ?", publishID,
+		).Scan(&status, &parentID)
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if status != models.PublishDeleted {
+			return nil
+		}
+
+		var replies uint64
+		if err = tx.QueryRow("select count(*) from publishes where parent_id = ?", publishID).Scan(&replies); err != nil {
+			return err
+		}
+		if replies > 0 {
+			return nil
+		}
+
+		if _, err = tx.Exec("delete from publishes where id = ?", publishID); err != nil {
+			return err
+		}
+		publishID = uint64(parentID.Int64)
+	}
+
+	return nil
+}
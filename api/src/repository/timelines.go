@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"api/src/config"
+	"api/src/models"
+	"database/sql"
+)
+
+// As timelines guardam, para cada usuário, os IDs das publicações do seu feed.
+// Publicações são copiadas na escrita (fan-out), exceto as de autores com mais
+// de config.FanOutThreshold seguidores, que são buscadas na leitura.
+
+type Timelines struct {
+	db *sql.DB
+}
+
+func NewTimelinesRepository(db *sql.DB) *Timelines {
+	return &Timelines{db: db}
+}
+
+// fanOutPublish copia a publicação para a timeline do autor e de seus seguidores
+func fanOutPublish(tx *sql.Tx, publishID, authorID uint64) error {
+	if _, err := tx.Exec(
+		"insert ignore into timelines (user_id, publish_id, author_id) values (?, ?, ?)",
+		authorID, publishID, authorID,
+	); err != nil {
+		return err
+	}
+
+	_, err := tx.Exec(
+		`insert ignore into timelines (user_id, publish_id, author_id)
+		select f.follower_id, ?, ? from followers f
+		where f.user_id = ? and (select count(*) from followers where user_id = ?) <= ?`,
+		publishID, authorID, authorID, authorID, config.FanOutThreshold,
+	)
+	return err
+}
+
+// backfillTimeline copia as publicações do autor para a timeline de um novo
+// seguidor. Copia todas, e não só as recentes, para que a timeline continue
+// igual ao que expectedTimeline descreve.
+func backfillTimeline(tx *sql.Tx, followerID, authorID uint64) error {
+	_, err := tx.Exec(
+		`insert ignore into timelines (user_id, publish_id, author_id)
+		select ?, p.id, p.author_id from publishes p
+		where `+timelinePublish+` and p.author_id = ? and `+fannedOutAuthor,
+		followerID, authorID, config.FanOutThreshold,
+	)
+	return err
+}
+
+// removeFromTimeline tira da timeline do usuário as publicações do autor
+func removeFromTimeline(tx *sql.Tx, userID, authorID uint64) error {
+	_, err := tx.Exec("delete from timelines where user_id = ? and author_id = ?", userID, authorID)
+	return err
+}
+
+// Condições que definem o que entra nas timelines, compartilhadas pelo
+// backfill, pelo rebuild e pela verificação. fannedOutAuthor recebe o limite
+// de seguidores.
+const (
+	timelinePublish = `p.status = 'published'`
+	fannedOutAuthor = `(select count(*) from followers where user_id = p.author_id) <= ?`
+)
+
+// expectedTimeline é a consulta que reproduz, a partir das tabelas de origem,
+// o que a timeline do usuário deveria conter
+const expectedTimeline = `
+	select p.id, p.author_id from publishes p
+	where ` + timelinePublish + `
+	and (
+		p.author_id = ?
+		or (p.author_id in (select user_id from followers where follower_id = ?) and ` + fannedOutAuthor + `)
+	)`
+
+// Rebuild recria a timeline do usuário a partir das tabelas de origem
+func (t *Timelines) Rebuild(userID uint64) error {
+	tx, err := t.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.Exec("delete from timelines where user_id = ?", userID); err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec(
+		"insert into timelines (user_id, publish_id, author_id) select ?, e.id, e.author_id from ("+expectedTimeline+") e",
+		userID, userID, userID, config.FanOutThreshold,
+	); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Check compara a timeline do usuário com o que as tabelas de origem indicam
+func (t *Timelines) Check(userID uint64) (models.TimelineCheck, error) {
+	check := models.TimelineCheck{UserID: userID}
+
+	if err := t.db.QueryRow(
+		`select count(*) from (`+expectedTimeline+`) e
+		where e.id not in (select publish_id from timelines where user_id = ?)`,
+		userID, userID, config.FanOutThreshold, userID,
+	).Scan(&check.Missing); err != nil {
+		return models.TimelineCheck{}, err
+	}
+
+	if err := t.db.QueryRow(
+		`select count(*) from timelines t
+		where t.user_id = ? and t.publish_id not in (select e.id from (`+expectedTimeline+`) e)`,
+		userID, userID, userID, config.FanOutThreshold,
+	).Scan(&check.Extra); err != nil {
+		return models.TimelineCheck{}, err
+	}
+
+	return check, nil
+}
+
+// UserIDs retorna os IDs de todos os usuários, para rebuild e verificação em lote
+func (t *Timelines) UserIDs() ([]uint64, error) {
+	rows, err := t.db.Query("select id from users order by id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var userIDs []uint64
+	for rows.Next() {
+		var userID uint64
+		if err = rows.Scan(&userID); err != nil {
+			return nil, err
+		}
+		userIDs = append(userIDs, userID)
+	}
+
+	return userIDs, nil
+}
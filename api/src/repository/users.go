@@ -4,6 +4,8 @@ import (
 	"api/src/models"
 	"database/sql"
 	"fmt"
+	"strings"
+	"time"
 )
 
 // Struct que recebe um ponteiro da conexao com o banco de dados
@@ -40,12 +42,16 @@ func (u Users) Create(user models.User) (uint64, error) {
 	return uint64(lastInsertID), nil
 }
 
-func (u Users) Get(nameOrNick string) ([]models.User, error) {
+// Get busca usuários por nome ou nick, omitindo quem tem bloqueio com viewerID
+func (u Users) Get(nameOrNick string, viewerID uint64) ([]models.User, error) {
 	nameOrNick = fmt.Sprintf("%%%s%%", nameOrNick) // %nameOrNick%
 
 	rows, err := u.db.Query(
-		"select id, name, nick, email, created_at from users where name LIKE ? or nick LIKE ?",
-		nameOrNick, nameOrNick)
+		`select id, name, nick, email, created_at from users
+		where (name LIKE ? or nick LIKE ?) and status = 'active'
+		and id not in (select blocked_id from blocks where user_id = ?)
+		and id not in (select user_id from blocks where blocked_id = ?)`,
+		nameOrNick, nameOrNick, viewerID, viewerID)
 	if err != nil {
 		return nil, err
 	}
@@ -75,7 +81,8 @@ func (u Users) Get(nameOrNick string) ([]models.User, error) {
 
 func (u Users) GetByID(ID uint64) (models.User, error) {
 	rows, err := u.db.Query(
-		"select id, name, nick, email, created_at from users where id = ?", ID,
+		`select id, name, nick, email, is_private, bio, location, website, avatar_url, banner_url, avatar_key,
+		status, created_at from users where id = ?`, ID,
 	)
 	if err != nil {
 		return models.User{}, err
@@ -90,6 +97,14 @@ func (u Users) GetByID(ID uint64) (models.User, error) {
 			&user.Name,
 			&user.Nick,
 			&user.Email,
+			&user.IsPrivate,
+			&user.Bio,
+			&user.Location,
+			&user.Website,
+			&user.AvatarURL,
+			&user.BannerURL,
+			&user.AvatarKey,
+			&user.Status,
 			&user.CreatedAt,
 		); err != nil {
 			return models.User{}, err
@@ -101,22 +116,121 @@ func (u Users) GetByID(ID uint64) (models.User, error) {
 	return models.User{}, err
 }
 
+// GetProfile retorna o usuário com seus contadores e se viewerID o segue
+func (u Users) GetProfile(ID, viewerID uint64) (models.Profile, error) {
+	user, err := u.GetByID(ID)
+	if err != nil || user.ID == 0 {
+		return models.Profile{}, err
+	}
+	if user.Status != models.UserActive && ID != viewerID {
+		return models.Profile{}, nil
+	}
+
+	profile := models.Profile{User: user}
+	if err = u.db.QueryRow(
+		`select
+			(select count(*) from followers where user_id = ?),
+			(select count(*) from followers where follower_id = ?),
+			(select count(*) from publishes where author_id = ? and status = 'published'),
+			exists(select 1 from followers where user_id = ? and follower_id = ?)`,
+		ID, ID, ID, ID, viewerID,
+	).Scan(
+		&profile.FollowersCount,
+		&profile.FollowingCount,
+		&profile.PublishesCount,
+		&profile.Following,
+	); err != nil {
+		return models.Profile{}, err
+	}
+
+	return profile, nil
+}
+
 func (u Users) Update(ID uint64, user models.User) error {
-	statement, err := u.db.Prepare("update users set name = ?, nick = ?, email = ? where id = ?")
+	statement, err := u.db.Prepare(
+		`update users set name = ?, nick = ?, email = ?, is_private = ?,
+		bio = ?, location = ?, website = ?, avatar_url = ?, banner_url = ? where id = ?`,
+	)
 	if err != nil {
 		return err
 	}
 	defer statement.Close()
 
-	if _, err = statement.Exec(user.Name, user.Nick, user.Email, ID); err != nil {
+	if _, err = statement.Exec(
+		user.Name,
+		user.Nick,
+		user.Email,
+		user.IsPrivate,
+		user.Bio,
+		user.Location,
+		user.Website,
+		user.AvatarURL,
+		user.BannerURL,
+		ID,
+	); err != nil {
 		return err
 	}
 
 	return nil
 }
 
+// Delete apaga o usuário de vez, junto com tudo o que depende dele
 func (u Users) Delete(ID uint64) error {
-	statement, err := u.db.Prepare("delete from users where id = ?")
+	tx, err := u.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err = deleteUser(tx, ID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func deleteUser(tx *sql.Tx, ID uint64) error {
+	if _, err := tx.Exec("delete from users where id = ?", ID); err != nil {
+		return err
+	}
+
+	return insertOutboxEvent(tx, models.EventUserDeleted, ID, models.User{ID: ID})
+}
+
+// Deactivate oculta a conta até o próximo login
+func (u Users) Deactivate(ID uint64) error {
+	statement, err := u.db.Prepare("update users set status = 'deactivated', delete_after = null where id = ?")
+	if err != nil {
+		return err
+	}
+	defer statement.Close()
+
+	if _, err = statement.Exec(ID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SoftDelete oculta a conta e agenda a exclusão definitiva para deleteAfter.
+// Até lá, um login restaura a conta.
+func (u Users) SoftDelete(ID uint64, deleteAfter time.Time) error {
+	statement, err := u.db.Prepare("update users set status = 'deleted', delete_after = ? where id = ?")
+	if err != nil {
+		return err
+	}
+	defer statement.Close()
+
+	if _, err = statement.Exec(deleteAfter, ID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Restore reativa uma conta desativada ou excluída que ainda não foi apagada
+func (u Users) Restore(ID uint64) error {
+	statement, err := u.db.Prepare("update users set status = 'active', delete_after = null where id = ?")
 	if err != nil {
 		return err
 	}
@@ -129,56 +243,182 @@ func (u Users) Delete(ID uint64) error {
 	return nil
 }
 
+// PurgeDeleted apaga de vez as contas cujo período de carência terminou até
+// now. As linhas são travadas com skip locked, então várias instâncias podem
+// rodar ao mesmo tempo. Retorna os IDs apagados.
+func (u Users) PurgeDeleted(now time.Time, limit int) ([]uint64, error) {
+	tx, err := u.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(
+		`select id from users where status = 'deleted' and delete_after <= ?
+		order by delete_after limit ? for update skip locked`,
+		now, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var IDs []uint64
+	for rows.Next() {
+		var ID uint64
+		if err = rows.Scan(&ID); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		IDs = append(IDs, ID)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, ID := range IDs {
+		if err = deleteUser(tx, ID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	return IDs, nil
+}
+
+// NickTaken indica se o nick já pertence a alguma conta, inclusive desativadas e excluídas
+func (u Users) NickTaken(nick string) (bool, error) {
+	var count int
+	if err := u.db.QueryRow("select count(*) from users where nick = ?", nick).Scan(&count); err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// IsActive indica se a conta existe e não está desativada nem excluída
+func (u Users) IsActive(ID uint64) (bool, error) {
+	var active bool
+	err := u.db.QueryRow("select status = 'active' from users where id = ?", ID).Scan(&active)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return active, err
+}
+
 func (u Users) GetByEmail(email string) (models.User, error) {
-	row, err := u.db.Query("select id, password from users where email = ?", email)
+	return u.getForLogin("email", email)
+}
+
+// GetForLogin busca o usuário com os dados usados no login, como GetByEmail
+func (u Users) GetForLogin(ID uint64) (models.User, error) {
+	return u.getForLogin("id", ID)
+}
+
+// getForLogin busca pela coluna informada, que nunca vem da requisição
+func (u Users) getForLogin(column string, value interface{}) (models.User, error) {
+	row, err := u.db.Query("select id, password, status, delete_after from users where "+column+" = ?", value)
 	if err != nil {
 		return models.User{}, err
 	}
 	defer row.Close()
 	var user models.User
 	if row.Next() {
-		if err := row.Scan(&user.ID, &user.Password); err != nil {
+		var deleteAfter sql.NullTime
+		if err := row.Scan(&user.ID, &user.Password, &user.Status, &deleteAfter); err != nil {
 			return models.User{}, err
 		}
+		if deleteAfter.Valid {
+			user.DeleteAfter = &deleteAfter.Time
+		}
 	}
 
 	return user, nil
 }
 
-func (u Users) FollowUser(userID, followerID uint64) error {
-	statement, err := u.db.Prepare(
+// FollowUser retorna se o vínculo é novo; seguir de novo não é erro
+func (u Users) FollowUser(userID, followerID uint64) (bool, error) {
+	tx, err := u.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	created, err := insertFollower(tx, userID, followerID)
+	if err != nil {
+		return false, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return false, err
+	}
+	return created, nil
+}
+
+// insertFollower cria o vínculo de seguidor e registra o evento quando ele é
+// novo. Retorna se o vínculo foi criado.
+func insertFollower(tx *sql.Tx, userID, followerID uint64) (bool, error) {
+	result, err := tx.Exec(
 		"insert ignore into followers (follower_id, user_id) values (?,?)",
+		followerID, userID,
 	)
 	if err != nil {
-		return err
+		return false, err
 	}
-	defer statement.Close()
-	_, err = statement.Exec(followerID, userID)
+
+	affected, err := result.RowsAffected()
 	if err != nil {
-		return err
+		return false, err
+	}
+	if affected == 0 {
+		return false, nil
 	}
 
-	return nil
+	if err = backfillTimeline(tx, followerID, userID); err != nil {
+		return false, err
+	}
+
+	if err = insertOutboxEvent(tx, models.EventUserFollowed, userID, struct {
+		UserID     uint64 `json:"user_id"`
+		FollowerID uint64 `json:"follower_id"`
+	}{userID, followerID}); err != nil {
+		return false, err
+	}
+	return true, nil
 }
 
 func (u Users) StopFollowUser(userID, followerID uint64) error {
-	statement, err := u.db.Prepare("delete from followers where user_id = ? and follower_id = ?")
+	tx, err := u.db.Begin()
 	if err != nil {
 		return err
 	}
-	defer statement.Close()
-	_, err = statement.Exec(userID, followerID)
-	if err != nil {
+	defer tx.Rollback()
+
+	if _, err = tx.Exec(
+		"delete from followers where user_id = ? and follower_id = ?", userID, followerID,
+	); err != nil {
 		return err
 	}
 
-	return nil
+	// Também cancela um pedido pendente para seguir uma conta privada
+	if _, err = tx.Exec(
+		"delete from follow_requests where user_id = ? and requester_id = ?", userID, followerID,
+	); err != nil {
+		return err
+	}
+
+	if err = removeFromTimeline(tx, followerID, userID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 func (u Users) GetFollowers(userID uint64) ([]models.User, error) {
 	rows, err := u.db.Query(`
 		select u.id, u.name, u.nick, u.email, u.created_at from users u
-		inner join followers f on u.id = f.follower_id where f.user_id = ? 
+		inner join followers f on u.id = f.follower_id where f.user_id = ? and u.status = 'active'
 		`, userID)
 	if err != nil {
 		return nil, err
@@ -203,10 +443,38 @@ func (u Users) GetFollowers(userID uint64) ([]models.User, error) {
 	return users, nil
 }
 
+// GetAudience lista os IDs dos seguidores ativos que recebem as novas
+// publicações do usuário, sem os que o silenciaram ou têm bloqueio com ele
+func (u Users) GetAudience(userID uint64) ([]uint64, error) {
+	rows, err := u.db.Query(`
+		select f.follower_id from followers f
+		inner join users u on u.id = f.follower_id
+		where f.user_id = ? and u.status = 'active'
+		and f.follower_id not in (select user_id from mutes where muted_id = ?)
+		and f.follower_id not in (select user_id from blocks where blocked_id = ?)
+		and f.follower_id not in (select blocked_id from blocks where user_id = ?)
+		`, userID, userID, userID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var followerIDs []uint64
+	for rows.Next() {
+		var followerID uint64
+		if err = rows.Scan(&followerID); err != nil {
+			return nil, err
+		}
+		followerIDs = append(followerIDs, followerID)
+	}
+
+	return followerIDs, rows.Err()
+}
+
 func (u Users) GetFollowing(userID uint64) ([]models.User, error) {
 	rows, err := u.db.Query(`
 	select u.id, u.name, u.nick, u.email, u.created_at from users u
-	inner join followers f on u.id = f.user_id where f.follower_id = ?
+	inner join followers f on u.id = f.user_id where f.follower_id = ? and u.status = 'active'
 	`, userID)
 	if err != nil {
 		return nil, err
@@ -262,3 +530,428 @@ func (u Users) UpdatePassword(userID uint64, passwordHash string) error {
 
 	return nil
 }
+
+// GetRole retorna o papel do usuário, ou "" se ele não existir
+func (u Users) GetRole(userID uint64) (string, error) {
+	var role string
+	err := u.db.QueryRow("select role from users where id = ?", userID).Scan(&role)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return role, err
+}
+
+func (u Users) UpdateRole(userID uint64, role string) error {
+	statement, err := u.db.Prepare("update users set role = ? where id = ?")
+	if err != nil {
+		return err
+	}
+	defer statement.Close()
+
+	if _, err = statement.Exec(role, userID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (u Users) GetByNick(nick string) (models.User, error) {
+	row, err := u.db.Query("select id, name, nick from users where nick = ? and status = 'active'", nick)
+	if err != nil {
+		return models.User{}, err
+	}
+	defer row.Close()
+	var user models.User
+	if row.Next() {
+		if err := row.Scan(&user.ID, &user.Name, &user.Nick); err != nil {
+			return models.User{}, err
+		}
+	}
+
+	return user, nil
+}
+
+// Block bloqueia o usuário e desfaz os vínculos de seguidor nas duas direções
+func (u Users) Block(userID, blockedID uint64) error {
+	tx, err := u.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.Exec("insert ignore into blocks (user_id, blocked_id) values (?, ?)", userID, blockedID); err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec(
+		"delete from followers where (user_id = ? and follower_id = ?) or (user_id = ? and follower_id = ?)",
+		userID, blockedID, blockedID, userID,
+	); err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec(
+		"delete from follow_requests where (user_id = ? and requester_id = ?) or (user_id = ? and requester_id = ?)",
+		userID, blockedID, blockedID, userID,
+	); err != nil {
+		return err
+	}
+
+	if err = removeFromTimeline(tx, userID, blockedID); err != nil {
+		return err
+	}
+	if err = removeFromTimeline(tx, blockedID, userID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (u Users) Unblock(userID, blockedID uint64) error {
+	statement, err := u.db.Prepare("delete from blocks where user_id = ? and blocked_id = ?")
+	if err != nil {
+		return err
+	}
+	defer statement.Close()
+
+	if _, err = statement.Exec(userID, blockedID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// IsBlocked indica se existe bloqueio entre os dois usuários, em qualquer direção
+func (u Users) IsBlocked(userID, otherID uint64) (bool, error) {
+	var blocks uint64
+	if err := u.db.QueryRow(
+		`select count(*) from blocks
+		where (user_id = ? and blocked_id = ?) or (user_id = ? and blocked_id = ?)`,
+		userID, otherID, otherID, userID,
+	).Scan(&blocks); err != nil {
+		return false, err
+	}
+
+	return blocks > 0, nil
+}
+
+func (u Users) GetBlocked(userID uint64) ([]models.User, error) {
+	return u.getRelated(`
+		select u.id, u.name, u.nick, u.email, u.created_at from users u
+		inner join blocks b on u.id = b.blocked_id where b.user_id = ?
+		`, userID)
+}
+
+func (u Users) Mute(userID, mutedID uint64) error {
+	statement, err := u.db.Prepare("insert ignore into mutes (user_id, muted_id) values (?, ?)")
+	if err != nil {
+		return err
+	}
+	defer statement.Close()
+
+	if _, err = statement.Exec(userID, mutedID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (u Users) Unmute(userID, mutedID uint64) error {
+	statement, err := u.db.Prepare("delete from mutes where user_id = ? and muted_id = ?")
+	if err != nil {
+		return err
+	}
+	defer statement.Close()
+
+	if _, err = statement.Exec(userID, mutedID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (u Users) GetMuted(userID uint64) ([]models.User, error) {
+	return u.getRelated(`
+		select u.id, u.name, u.nick, u.email, u.created_at from users u
+		inner join mutes m on u.id = m.muted_id where m.user_id = ?
+		`, userID)
+}
+
+func (u Users) getRelated(query string, userID uint64) ([]models.User, error) {
+	rows, err := u.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var users []models.User
+	for rows.Next() {
+		var user models.User
+		err = rows.Scan(
+			&user.ID,
+			&user.Name,
+			&user.Nick,
+			&user.Email,
+			&user.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	return users, nil
+}
+
+func (u Users) IsPrivate(userID uint64) (bool, error) {
+	var isPrivate bool
+	if err := u.db.QueryRow("select is_private from users where id = ?", userID).Scan(&isPrivate); err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return isPrivate, nil
+}
+
+// CanViewContent indica se viewerID pode ver publicações, seguidores e
+// seguidos de userID: contas privadas só são visíveis para seguidores
+// aprovados, e contas desativadas ou excluídas não são visíveis para ninguém
+func (u Users) CanViewContent(viewerID, userID uint64) (bool, error) {
+	if viewerID == userID {
+		return true, nil
+	}
+
+	var allowed bool
+	if err := u.db.QueryRow(
+		`select u.status = 'active' and (not u.is_private or exists(
+			select 1 from followers f where f.user_id = u.id and f.follower_id = ?
+		)) from users u where u.id = ?`,
+		viewerID, userID,
+	).Scan(&allowed); err != nil {
+		if err == sql.ErrNoRows {
+			return true, nil
+		}
+		return false, err
+	}
+
+	return allowed, nil
+}
+
+func (u Users) CreateFollowRequest(userID, requesterID uint64) (bool, error) {
+	result, err := u.db.Exec(
+		"insert ignore into follow_requests (user_id, requester_id) values (?, ?)", userID, requesterID,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+func (u Users) GetFollowRequests(userID uint64) ([]models.User, error) {
+	return u.getRelated(`
+		select u.id, u.name, u.nick, u.email, u.created_at from users u
+		inner join follow_requests r on u.id = r.requester_id where r.user_id = ?
+		order by r.created_at
+		`, userID)
+}
+
+// ApproveFollowRequest transforma o pedido pendente em seguidor. Retorna false se não havia pedido.
+func (u Users) ApproveFollowRequest(userID, requesterID uint64) (bool, error) {
+	tx, err := u.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(
+		"delete from follow_requests where user_id = ? and requester_id = ?", userID, requesterID,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if affected == 0 {
+		return false, nil
+	}
+
+	if _, err = insertFollower(tx, userID, requesterID); err != nil {
+		return false, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (u Users) RejectFollowRequest(userID, requesterID uint64) error {
+	statement, err := u.db.Prepare("delete from follow_requests where user_id = ? and requester_id = ?")
+	if err != nil {
+		return err
+	}
+	defer statement.Close()
+
+	if _, err = statement.Exec(userID, requesterID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (u Users) UpdateAvatar(userID uint64, avatarKey string) error {
+	statement, err := u.db.Prepare("update users set avatar_key = ? where id = ?")
+	if err != nil {
+		return err
+	}
+	defer statement.Close()
+
+	if _, err = statement.Exec(avatarKey, userID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetByIDs retorna os dados públicos dos usuários informados
+func (u Users) GetByIDs(IDs []uint64) (map[uint64]models.User, error) {
+	users := map[uint64]models.User{}
+	if len(IDs) == 0 {
+		return users, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(IDs)), ",")
+	args := make([]interface{}, len(IDs))
+	for i, ID := range IDs {
+		args[i] = ID
+	}
+
+	rows, err := u.db.Query(
+		"select id, name, nick, email, created_at from users where id in ("+placeholders+") and status = 'active'", args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var user models.User
+		if err = rows.Scan(
+			&user.ID,
+			&user.Name,
+			&user.Nick,
+			&user.Email,
+			&user.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		users[user.ID] = user
+	}
+
+	return users, nil
+}
+
+// GetRelationships retorna a relação de userID com cada um dos alvos, na mesma ordem
+func (u Users) GetRelationships(userID uint64, targetIDs []uint64) ([]models.Relationship, error) {
+	relationships := make([]models.Relationship, len(targetIDs))
+	if len(targetIDs) == 0 {
+		return relationships, nil
+	}
+
+	byTarget := map[uint64]*models.Relationship{}
+	args := []interface{}{userID}
+	for i, targetID := range targetIDs {
+		relationships[i] = models.Relationship{UserID: userID, TargetID: targetID}
+		byTarget[targetID] = &relationships[i]
+		args = append(args, targetID)
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(targetIDs)), ",")
+
+	flags := []struct {
+		query string
+		set   func(relationship *models.Relationship)
+	}{
+		{
+			"select user_id from followers where follower_id = ? and user_id in (" + placeholders + ")",
+			func(relationship *models.Relationship) { relationship.Following = true },
+		},
+		{
+			"select follower_id from followers where user_id = ? and follower_id in (" + placeholders + ")",
+			func(relationship *models.Relationship) { relationship.FollowedBy = true },
+		},
+		{
+			"select user_id from follow_requests where requester_id = ? and user_id in (" + placeholders + ")",
+			func(relationship *models.Relationship) { relationship.Requested = true },
+		},
+		{
+			"select blocked_id from blocks where user_id = ? and blocked_id in (" + placeholders + ")",
+			func(relationship *models.Relationship) { relationship.Blocked = true },
+		},
+		{
+			"select muted_id from mutes where user_id = ? and muted_id in (" + placeholders + ")",
+			func(relationship *models.Relationship) { relationship.Muted = true },
+		},
+	}
+
+	for _, flag := range flags {
+		rows, err := u.db.Query(flag.query, args...)
+		if err != nil {
+			return nil, err
+		}
+
+		for rows.Next() {
+			var targetID uint64
+			if err = rows.Scan(&targetID); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			flag.set(byTarget[targetID])
+		}
+		rows.Close()
+	}
+
+	return relationships, nil
+}
+
+// GetMutuals lista quem segue o usuário e também é seguido por ele
+func (u Users) GetMutuals(userID, limit, offset uint64) ([]models.User, error) {
+	rows, err := u.db.Query(`
+		select u.id, u.name, u.nick, u.email, u.created_at from users u
+		inner join followers f1 on u.id = f1.follower_id and f1.user_id = ?
+		inner join followers f2 on u.id = f2.user_id and f2.follower_id = ?
+		where u.status = 'active'
+		order by u.id
+		limit ? offset ?
+		`, userID, userID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	users := []models.User{}
+	for rows.Next() {
+		var user models.User
+		err = rows.Scan(
+			&user.ID,
+			&user.Name,
+			&user.Nick,
+			&user.Email,
+			&user.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	return users, nil
+}
@@ -0,0 +1,302 @@
+package repository
+
+import (
+	"api/src/models"
+	"database/sql"
+	"strings"
+	"time"
+)
+
+type Webhooks struct {
+	db *sql.DB
+}
+
+func NewWebhooksRepository(db *sql.DB) *Webhooks {
+	return &Webhooks{db: db}
+}
+
+func (w *Webhooks) Create(webhook models.Webhook) (uint64, error) {
+	statement, err := w.db.Prepare("insert into webhooks (user_id, url, secret, events) values (?, ?, ?, ?)")
+	if err != nil {
+		return 0, err
+	}
+	defer statement.Close()
+
+	result, err := statement.Exec(webhook.UserID, webhook.URL, webhook.Secret, strings.Join(webhook.Events, ","))
+	if err != nil {
+		return 0, err
+	}
+
+	lastInsertID, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	return uint64(lastInsertID), nil
+}
+
+func (w *Webhooks) GetByUser(userID uint64) ([]models.Webhook, error) {
+	rows, err := w.db.Query(
+		"select id, user_id, url, events, active, created_at from webhooks where user_id = ? order by id",
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var webhooks []models.Webhook
+	for rows.Next() {
+		var webhook models.Webhook
+		var events string
+		if err = rows.Scan(
+			&webhook.ID,
+			&webhook.UserID,
+			&webhook.URL,
+			&events,
+			&webhook.Active,
+			&webhook.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		webhook.Events = strings.Split(events, ",")
+		webhooks = append(webhooks, webhook)
+	}
+
+	return webhooks, nil
+}
+
+func (w *Webhooks) GetByID(webhookID uint64) (models.Webhook, error) {
+	row, err := w.db.Query(
+		"select id, user_id, url, events, active, created_at from webhooks where id = ?", webhookID,
+	)
+	if err != nil {
+		return models.Webhook{}, err
+	}
+	defer row.Close()
+
+	var webhook models.Webhook
+	if row.Next() {
+		var events string
+		if err = row.Scan(
+			&webhook.ID,
+			&webhook.UserID,
+			&webhook.URL,
+			&events,
+			&webhook.Active,
+			&webhook.CreatedAt,
+		); err != nil {
+			return models.Webhook{}, err
+		}
+		webhook.Events = strings.Split(events, ",")
+	}
+
+	return webhook, nil
+}
+
+func (w *Webhooks) Delete(webhookID uint64) error {
+	statement, err := w.db.Prepare("delete from webhooks where id = ?")
+	if err != nil {
+		return err
+	}
+	defer statement.Close()
+
+	if _, err = statement.Exec(webhookID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (w *Webhooks) GetDeliveries(webhookID, limit, offset uint64) ([]models.WebhookDelivery, error) {
+	rows, err := w.db.Query(
+		`select d.id, d.webhook_id, d.outbox_id, o.event_type, d.status, d.attempts,
+				d.status_code, d.error, d.next_attempt_at, d.created_at
+				from webhook_deliveries d
+				inner join webhook_outbox o on d.outbox_id = o.id
+				where d.webhook_id = ?
+				order by d.id desc
+				limit ? offset ?`,
+		webhookID, limit, offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []models.WebhookDelivery{}
+	for rows.Next() {
+		var delivery models.WebhookDelivery
+		if err = rows.Scan(
+			&delivery.ID,
+			&delivery.WebhookID,
+			&delivery.OutboxID,
+			&delivery.EventType,
+			&delivery.Status,
+			&delivery.Attempts,
+			&delivery.StatusCode,
+			&delivery.Error,
+			&delivery.NextAttemptAt,
+			&delivery.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, nil
+}
+
+// Replay agenda um novo envio do mesmo evento, preservando o histórico da entrega original
+func (w *Webhooks) Replay(webhookID, deliveryID uint64) (uint64, error) {
+	result, err := w.db.Exec(
+		`insert into webhook_deliveries (webhook_id, outbox_id)
+				select webhook_id, outbox_id from webhook_deliveries where id = ? and webhook_id = ?`,
+		deliveryID, webhookID,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	lastInsertID, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	return uint64(lastInsertID), nil
+}
+
+// DispatchOutbox transforma os eventos pendentes do outbox em entregas para
+// cada webhook interessado. Webhooks de administradores recebem todos os eventos.
+func (w *Webhooks) DispatchOutbox(limit int) error {
+	tx, err := w.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(
+		`select id, event_type, user_id from webhook_outbox
+				where dispatched = false order by id limit ? for update skip locked`,
+		limit,
+	)
+	if err != nil {
+		return err
+	}
+
+	var events []models.OutboxEvent
+	for rows.Next() {
+		var event models.OutboxEvent
+		if err = rows.Scan(&event.ID, &event.Type, &event.UserID); err != nil {
+			rows.Close()
+			return err
+		}
+		events = append(events, event)
+	}
+	rows.Close()
+
+	for _, event := range events {
+		if _, err = tx.Exec(
+			`insert into webhook_deliveries (webhook_id, outbox_id)
+					select w.id, ? from webhooks w
+					inner join users u on w.user_id = u.id
+					where w.active = true and find_in_set(?, w.events) > 0
+					and (w.user_id = ? or u.role = ?)`,
+			event.ID, event.Type, event.UserID, models.RoleAdmin,
+		); err != nil {
+			return err
+		}
+
+		if _, err = tx.Exec("update webhook_outbox set dispatched = true where id = ?", event.ID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// ClaimDueDeliveries reserva as entregas vencidas adiando a próxima tentativa
+// pelo tempo de lease, para que outra instância não as envie ao mesmo tempo
+func (w *Webhooks) ClaimDueDeliveries(limit int, lease time.Duration) ([]models.WebhookDelivery, error) {
+	tx, err := w.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(
+		`select d.id, d.webhook_id, d.outbox_id, d.attempts, w.url, w.secret,
+				o.event_type, o.payload, o.created_at
+				from webhook_deliveries d
+				inner join webhooks w on d.webhook_id = w.id
+				inner join webhook_outbox o on d.outbox_id = o.id
+				where d.status = ? and d.next_attempt_at <= ?
+				order by d.next_attempt_at limit ? for update of d skip locked`,
+		models.DeliveryPending, time.Now(), limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var deliveries []models.WebhookDelivery
+	for rows.Next() {
+		var delivery models.WebhookDelivery
+		if err = rows.Scan(
+			&delivery.ID,
+			&delivery.WebhookID,
+			&delivery.OutboxID,
+			&delivery.Attempts,
+			&delivery.Webhook.URL,
+			&delivery.Webhook.Secret,
+			&delivery.Event.Type,
+			&delivery.Event.Payload,
+			&delivery.Event.CreatedAt,
+		); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		delivery.Event.ID = delivery.OutboxID
+		delivery.EventType = delivery.Event.Type
+		deliveries = append(deliveries, delivery)
+	}
+	rows.Close()
+
+	for _, delivery := range deliveries {
+		if _, err = tx.Exec(
+			"update webhook_deliveries set next_attempt_at = ? where id = ?",
+			time.Now().Add(lease), delivery.ID,
+		); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// SaveAttempt registra o resultado de uma tentativa de envio
+func (w *Webhooks) SaveAttempt(delivery models.WebhookDelivery) error {
+	statement, err := w.db.Prepare(
+		`update webhook_deliveries set status = ?, attempts = ?, status_code = ?, error = ?, next_attempt_at = ?
+				where id = ?`,
+	)
+	if err != nil {
+		return err
+	}
+	defer statement.Close()
+
+	if _, err = statement.Exec(
+		delivery.Status,
+		delivery.Attempts,
+		delivery.StatusCode,
+		truncate(delivery.Error, 255),
+		delivery.NextAttemptAt,
+		delivery.ID,
+	); err != nil {
+		return err
+	}
+
+	return nil
+}
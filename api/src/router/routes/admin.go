@@ -0,0 +1,68 @@
+package routes
+
+import (
+	"api/src/controllers"
+	"api/src/models"
+	"net/http"
+)
+
+var admins = []string{models.RoleAdmin}
+
+var adminRoutes = []Route{
+	{
+		URI:           "/admin/users/{userId}/role",
+		Method:        http.MethodPut,
+		Function:      controllers.UpdateUserRole,
+		RequireRoles:  admins,
+		RequireScopes: []string{models.ScopeAdmin},
+	},
+	{
+		URI:           "/admin/filters",
+		Method:        http.MethodGet,
+		Function:      controllers.GetContentFilters,
+		RequireRoles:  admins,
+		RequireScopes: []string{models.ScopeAdmin},
+	},
+	{
+		URI:           "/admin/filters",
+		Method:        http.MethodPost,
+		Function:      controllers.CreateContentFilter,
+		RequireRoles:  admins,
+		RequireScopes: []string{models.ScopeAdmin},
+	},
+	{
+		URI:           "/admin/filters/reload",
+		Method:        http.MethodPost,
+		Function:      controllers.ReloadContentFilters,
+		RequireRoles:  admins,
+		RequireScopes: []string{models.ScopeAdmin},
+	},
+	{
+		URI:           "/admin/filters/{filterId}",
+		Method:        http.MethodDelete,
+		Function:      controllers.DeleteContentFilter,
+		RequireRoles:  admins,
+		RequireScopes: []string{models.ScopeAdmin},
+	},
+	{
+		URI:           "/admin/import",
+		Method:        http.MethodPost,
+		Function:      controllers.ImportUser,
+		RequireRoles:  admins,
+		RequireScopes: []string{models.ScopeAdmin},
+	},
+	{
+		URI:           "/admin/audit",
+		Method:        http.MethodGet,
+		Function:      controllers.GetAuditLog,
+		RequireRoles:  admins,
+		RequireScopes: []string{models.ScopeAdmin},
+	},
+	{
+		URI:           "/admin/audit/verify",
+		Method:        http.MethodGet,
+		Function:      controllers.VerifyAuditLog,
+		RequireRoles:  admins,
+		RequireScopes: []string{models.ScopeAdmin},
+	},
+}
@@ -0,0 +1,28 @@
+package routes
+
+import (
+	"api/src/controllers"
+	"net/http"
+)
+
+// Sem escopos: chaves de API não podem criar ou revogar outras chaves
+var apiKeysRoutes = []Route{
+	{
+		URI:                   "/api-keys",
+		Method:                http.MethodPost,
+		Function:              controllers.CreateAPIKey,
+		RequireAuthentication: true,
+	},
+	{
+		URI:                   "/api-keys",
+		Method:                http.MethodGet,
+		Function:              controllers.GetAPIKeys,
+		RequireAuthentication: true,
+	},
+	{
+		URI:                   "/api-keys/{keyId}",
+		Method:                http.MethodDelete,
+		Function:              controllers.DeleteAPIKey,
+		RequireAuthentication: true,
+	},
+}
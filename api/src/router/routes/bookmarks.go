@@ -0,0 +1,59 @@
+package routes
+
+import (
+	"api/src/controllers"
+	"api/src/models"
+	"net/http"
+)
+
+var bookmarksRoutes = []Route{
+	{
+		URI:                   "/publishes/{publishId}/bookmark",
+		Method:                http.MethodPost,
+		Function:              controllers.BookmarkPublish,
+		RequireAuthentication: true,
+		RequireScopes:         []string{models.ScopeBookmarksWrite},
+	},
+	{
+		URI:                   "/publishes/{publishId}/unbookmark",
+		Method:                http.MethodPost,
+		Function:              controllers.UnbookmarkPublish,
+		RequireAuthentication: true,
+		RequireScopes:         []string{models.ScopeBookmarksWrite},
+	},
+	{
+		URI:                   "/bookmarks",
+		Method:                http.MethodGet,
+		Function:              controllers.GetBookmarks,
+		RequireAuthentication: true,
+		RequireScopes:         []string{models.ScopeBookmarksRead},
+	},
+	{
+		URI:                   "/collections",
+		Method:                http.MethodPost,
+		Function:              controllers.CreateCollection,
+		RequireAuthentication: true,
+		RequireScopes:         []string{models.ScopeBookmarksWrite},
+	},
+	{
+		URI:                   "/collections",
+		Method:                http.MethodGet,
+		Function:              controllers.GetCollections,
+		RequireAuthentication: true,
+		RequireScopes:         []string{models.ScopeBookmarksRead},
+	},
+	{
+		URI:                   "/collections/{collectionId}",
+		Method:                http.MethodPut,
+		Function:              controllers.UpdateCollection,
+		RequireAuthentication: true,
+		RequireScopes:         []string{models.ScopeBookmarksWrite},
+	},
+	{
+		URI:                   "/collections/{collectionId}",
+		Method:                http.MethodDelete,
+		Function:              controllers.DeleteCollection,
+		RequireAuthentication: true,
+		RequireScopes:         []string{models.ScopeBookmarksWrite},
+	},
+}
@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"api/src/controllers"
+	"api/src/models"
+	"net/http"
+)
+
+var feedRoute = Route{
+	URI:                   "/feed",
+	Method:                http.MethodGet,
+	Function:              controllers.GetFeed,
+	RequireAuthentication: true,
+	RequireScopes:         []string{models.ScopePublishesRead},
+}
@@ -10,4 +10,26 @@ var loginRoute = Route{
 	Method:                http.MethodPost,
 	Function:              controllers.Login,
 	RequireAuthentication: false,
-}
\ No newline at end of file
+}
+
+// Login com provedores OpenID Connect; /login/providers é registrada antes de /login/{provider}
+var oidcRoutes = []Route{
+	{
+		URI:                   "/login/providers",
+		Method:                http.MethodGet,
+		Function:              controllers.GetLoginProviders,
+		RequireAuthentication: false,
+	},
+	{
+		URI:                   "/login/{provider}",
+		Method:                http.MethodGet,
+		Function:              controllers.StartOIDCLogin,
+		RequireAuthentication: false,
+	},
+	{
+		URI:                   "/login/{provider}/callback",
+		Method:                http.MethodGet,
+		Function:              controllers.OIDCCallback,
+		RequireAuthentication: false,
+	},
+}
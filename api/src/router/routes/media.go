@@ -0,0 +1,30 @@
+package routes
+
+import (
+	"api/src/controllers"
+	"api/src/models"
+	"net/http"
+)
+
+var mediaRoutes = []Route{
+	{
+		URI:                   "/media",
+		Method:                http.MethodPost,
+		Function:              controllers.UploadMedia,
+		RequireAuthentication: true,
+		RequireScopes:         []string{models.ScopePublishesWrite},
+	},
+	{
+		URI:                   "/media/{key}",
+		Method:                http.MethodGet,
+		Function:              controllers.ServeMedia,
+		RequireAuthentication: false,
+	},
+	{
+		URI:                   "/users/{userId}/avatar",
+		Method:                http.MethodPost,
+		Function:              controllers.UploadAvatar,
+		RequireAuthentication: true,
+		RequireScopes:         []string{models.ScopeUsersWrite},
+	},
+}
@@ -0,0 +1,68 @@
+package routes
+
+import (
+	"api/src/controllers"
+	"api/src/models"
+	"net/http"
+)
+
+var moderators = []string{models.RoleModerator, models.RoleAdmin}
+
+var moderationRoutes = []Route{
+	{
+		URI:                   "/publishes/{publishId}/report",
+		Method:                http.MethodPost,
+		Function:              controllers.ReportPublish,
+		RequireAuthentication: true,
+		RequireScopes:         []string{models.ScopeReportsWrite},
+	},
+	{
+		URI:                   "/users/{userId}/report",
+		Method:                http.MethodPost,
+		Function:              controllers.ReportUser,
+		RequireAuthentication: true,
+		RequireScopes:         []string{models.ScopeReportsWrite},
+	},
+	{
+		URI:           "/moderation/reports",
+		Method:        http.MethodGet,
+		Function:      controllers.GetReports,
+		RequireRoles:  moderators,
+		RequireScopes: []string{models.ScopeModeration},
+	},
+	{
+		URI:           "/moderation/reports/{reportId}",
+		Method:        http.MethodGet,
+		Function:      controllers.GetReport,
+		RequireRoles:  moderators,
+		RequireScopes: []string{models.ScopeModeration},
+	},
+	{
+		URI:           "/moderation/reports/{reportId}/claim",
+		Method:        http.MethodPost,
+		Function:      controllers.ClaimReport,
+		RequireRoles:  moderators,
+		RequireScopes: []string{models.ScopeModeration},
+	},
+	{
+		URI:           "/moderation/reports/{reportId}/resolve",
+		Method:        http.MethodPost,
+		Function:      controllers.ResolveReport,
+		RequireRoles:  moderators,
+		RequireScopes: []string{models.ScopeModeration},
+	},
+	{
+		URI:           "/moderation/reports/{reportId}/dismiss",
+		Method:        http.MethodPost,
+		Function:      controllers.DismissReport,
+		RequireRoles:  moderators,
+		RequireScopes: []string{models.ScopeModeration},
+	},
+	{
+		URI:           "/moderation/reports/{reportId}/remove",
+		Method:        http.MethodPost,
+		Function:      controllers.RemoveReportedContent,
+		RequireRoles:  moderators,
+		RequireScopes: []string{models.ScopeModeration},
+	},
+}
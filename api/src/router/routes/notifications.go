@@ -0,0 +1,45 @@
+package routes
+
+import (
+	"api/src/controllers"
+	"api/src/models"
+	"net/http"
+)
+
+var notificationsRoutes = []Route{
+	{
+		URI:                   "/notifications",
+		Method:                http.MethodGet,
+		Function:              controllers.GetNotifications,
+		RequireAuthentication: true,
+		RequireScopes:         []string{models.ScopeNotificationsRead},
+	},
+	{
+		URI:                   "/notifications/read-all",
+		Method:                http.MethodPost,
+		Function:              controllers.MarkAllNotificationsAsRead,
+		RequireAuthentication: true,
+		RequireScopes:         []string{models.ScopeNotificationsWrite},
+	},
+	{
+		URI:                   "/notifications/preferences",
+		Method:                http.MethodGet,
+		Function:              controllers.GetNotificationPreferences,
+		RequireAuthentication: true,
+		RequireScopes:         []string{models.ScopeNotificationsRead},
+	},
+	{
+		URI:                   "/notifications/preferences",
+		Method:                http.MethodPut,
+		Function:              controllers.UpdateNotificationPreferences,
+		RequireAuthentication: true,
+		RequireScopes:         []string{models.ScopeNotificationsWrite},
+	},
+	{
+		URI:                   "/notifications/{notificationId}/read",
+		Method:                http.MethodPost,
+		Function:              controllers.MarkNotificationAsRead,
+		RequireAuthentication: true,
+		RequireScopes:         []string{models.ScopeNotificationsWrite},
+	},
+}
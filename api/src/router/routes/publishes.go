@@ -2,6 +2,7 @@ package routes
 
 import (
 	"api/src/controllers"
+	"api/src/models"
 	"net/http"
 )
 
@@ -11,35 +12,90 @@ var publishesRoutes = []Route{
 		Method:                http.MethodPost,
 		Function:              controllers.CreatePublish,
 		RequireAuthentication: true,
+		RequireScopes:         []string{models.ScopePublishesWrite},
 	},
 	{
 		URI:                   "/publishes",
 		Method:                http.MethodGet,
 		Function:              controllers.GetPublishes,
 		RequireAuthentication: true,
+		RequireScopes:         []string{models.ScopePublishesRead},
+	},
+	{
+		URI:                   "/publishes/drafts",
+		Method:                http.MethodGet,
+		Function:              controllers.GetDrafts,
+		RequireAuthentication: true,
+		RequireScopes:         []string{models.ScopePublishesRead},
 	},
 	{
 		URI:                   "/publishes/{publishId}",
 		Method:                http.MethodGet,
 		Function:              controllers.GetPublish,
 		RequireAuthentication: true,
+		RequireScopes:         []string{models.ScopePublishesRead},
 	},
 	{
 		URI:                   "/publishes/{publishId}",
 		Method:                http.MethodPut,
 		Function:              controllers.UpdatePublish,
 		RequireAuthentication: true,
+		RequireScopes:         []string{models.ScopePublishesWrite},
 	},
 	{
 		URI:                   "/publishes/{publishId}",
 		Method:                http.MethodDelete,
 		Function:              controllers.DeletePublish,
 		RequireAuthentication: true,
+		RequireScopes:         []string{models.ScopePublishesWrite},
 	},
 	{
 		URI:                   "/users/{userId}/publishes",
 		Method:                http.MethodGet,
 		Function:              controllers.GetPublishesByUser,
 		RequireAuthentication: true,
+		RequireScopes:         []string{models.ScopePublishesRead},
+	},
+	{
+		URI:                   "/publishes/{publishId}/like",
+		Method:                http.MethodPost,
+		Function:              controllers.LikePublish,
+		RequireAuthentication: true,
+		RequireScopes:         []string{models.ScopePublishesWrite},
+	},
+	{
+		URI:                   "/publishes/{publishId}/unlike",
+		Method:                http.MethodPost,
+		Function:              controllers.UnlikePublish,
+		RequireAuthentication: true,
+		RequireScopes:         []string{models.ScopePublishesWrite},
+	},
+	{
+		URI:                   "/publishes/{publishId}/schedule",
+		Method:                http.MethodPost,
+		Function:              controllers.SchedulePublish,
+		RequireAuthentication: true,
+		RequireScopes:         []string{models.ScopePublishesWrite},
+	},
+	{
+		URI:                   "/publishes/{publishId}/publish",
+		Method:                http.MethodPost,
+		Function:              controllers.PublishDraft,
+		RequireAuthentication: true,
+		RequireScopes:         []string{models.ScopePublishesWrite},
+	},
+	{
+		URI:                   "/publishes/{publishId}/thread",
+		Method:                http.MethodGet,
+		Function:              controllers.GetThread,
+		RequireAuthentication: true,
+		RequireScopes:         []string{models.ScopePublishesRead},
+	},
+	{
+		URI:                   "/publishes/{publishId}/vote",
+		Method:                http.MethodPost,
+		Function:              controllers.VotePoll,
+		RequireAuthentication: true,
+		RequireScopes:         []string{models.ScopePublishesWrite},
 	},
 }
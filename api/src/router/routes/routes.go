@@ -12,18 +12,42 @@ type Route struct {
 	Method                string
 	Function              func(http.ResponseWriter, *http.Request)
 	RequireAuthentication bool
+	// RequireRoles restringe a rota aos usuários com um dos papéis, exigindo autenticação
+	RequireRoles []string
+	// RequireScopes são os escopos que uma chave de API precisa ter para usar
+	// a rota. Rotas autenticadas sem escopos só aceitam o JWT do login.
+	RequireScopes []string
 }
 
 func Configure(router *mux.Router) *mux.Router {
 	routes := usersRoutes
 	routes = append(routes, loginRoute)
+	routes = append(routes, oidcRoutes...)
 	routes = append(routes, publishesRoutes...)
+	routes = append(routes, notificationsRoutes...)
+	routes = append(routes, streamRoute)
+	routes = append(routes, webhooksRoutes...)
+	routes = append(routes, mediaRoutes...)
+	routes = append(routes, searchRoutes...)
+	routes = append(routes, feedRoute)
+	routes = append(routes, bookmarksRoutes...)
+	routes = append(routes, moderationRoutes...)
+	routes = append(routes, adminRoutes...)
+	routes = append(routes, sessionsRoutes...)
+	routes = append(routes, apiKeysRoutes...)
 
 	for _, route := range routes {
-		if route.RequireAuthentication {
+		if len(route.RequireRoles) > 0 {
 			router.HandleFunc(
 				route.URI,
-				middlewares.Logger(middlewares.Authenticate(route.Function)),
+				middlewares.Logger(middlewares.Authenticate(
+					middlewares.RequireScopes(route.RequireScopes, middlewares.Authorize(route.RequireRoles, route.Function)),
+				)),
+			).Methods(route.Method)
+		} else if route.RequireAuthentication {
+			router.HandleFunc(
+				route.URI,
+				middlewares.Logger(middlewares.Authenticate(middlewares.RequireScopes(route.RequireScopes, route.Function))),
 			).Methods(route.Method)
 		} else {
 			router.HandleFunc(route.URI, middlewares.Logger(route.Function)).Methods(route.Method)
@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"api/src/controllers"
+	"api/src/models"
+	"net/http"
+)
+
+var searchRoutes = []Route{
+	{
+		URI:                   "/search/publishes",
+		Method:                http.MethodGet,
+		Function:              controllers.SearchPublishes,
+		RequireAuthentication: true,
+		RequireScopes:         []string{models.ScopePublishesRead},
+	},
+}
@@ -0,0 +1,28 @@
+package routes
+
+import (
+	"api/src/controllers"
+	"net/http"
+)
+
+var sessionsRoutes = []Route{
+	{
+		URI:                   "/sessions",
+		Method:                http.MethodGet,
+		Function:              controllers.GetSessions,
+		RequireAuthentication: true,
+	},
+	// Registrada antes de /sessions/{sessionId}
+	{
+		URI:                   "/sessions/others",
+		Method:                http.MethodDelete,
+		Function:              controllers.RevokeOtherSessions,
+		RequireAuthentication: true,
+	},
+	{
+		URI:                   "/sessions/{sessionId}",
+		Method:                http.MethodDelete,
+		Function:              controllers.RevokeSession,
+		RequireAuthentication: true,
+	},
+}
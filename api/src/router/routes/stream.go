@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"api/src/controllers"
+	"api/src/models"
+	"net/http"
+)
+
+var streamRoute = Route{
+	URI:                   "/stream",
+	Method:                http.MethodGet,
+	Function:              controllers.Stream,
+	RequireAuthentication: true,
+	RequireScopes:         []string{models.ScopePublishesRead, models.ScopeNotificationsRead},
+}
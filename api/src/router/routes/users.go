@@ -2,6 +2,7 @@ package routes
 
 import (
 	"api/src/controllers"
+	"api/src/models"
 	"net/http"
 )
 
@@ -17,18 +18,36 @@ var usersRoutes = []Route{
 		Method:                http.MethodGet,
 		Function:              controllers.GetUsers,
 		RequireAuthentication: true,
+		RequireScopes:         []string{models.ScopeUsersRead},
+	},
+	// Registradas antes de /users/{userId} para que "suggestions" não seja lido como ID
+	{
+		URI:                   "/users/suggestions",
+		Method:                http.MethodGet,
+		Function:              controllers.GetSuggestions,
+		RequireAuthentication: true,
+		RequireScopes:         []string{models.ScopeUsersRead},
+	},
+	{
+		URI:                   "/users/suggestions/{userId}/dismiss",
+		Method:                http.MethodPost,
+		Function:              controllers.DismissSuggestion,
+		RequireAuthentication: true,
+		RequireScopes:         []string{models.ScopeUsersWrite},
 	},
 	{
 		URI:                   "/users/{userId}",
 		Method:                http.MethodGet,
 		Function:              controllers.GetUser,
 		RequireAuthentication: true,
+		RequireScopes:         []string{models.ScopeUsersRead},
 	},
 	{
 		URI:                   "/users/{userId}",
 		Method:                http.MethodPut,
 		Function:              controllers.UpdateUser,
 		RequireAuthentication: true,
+		RequireScopes:         []string{models.ScopeUsersWrite},
 	},
 	{
 		URI:                   "/users/{userId}",
@@ -36,29 +55,51 @@ var usersRoutes = []Route{
 		Function:              controllers.DeleteUser,
 		RequireAuthentication: true,
 	},
+	{
+		URI:                   "/users/{userId}/deactivate",
+		Method:                http.MethodPost,
+		Function:              controllers.DeactivateUser,
+		RequireAuthentication: true,
+	},
+	{
+		URI:                   "/users/{userId}/export",
+		Method:                http.MethodPost,
+		Function:              controllers.ExportUser,
+		RequireAuthentication: true,
+	},
+	{
+		URI:                   "/users/{userId}/export/{jobId}",
+		Method:                http.MethodGet,
+		Function:              controllers.GetExport,
+		RequireAuthentication: true,
+	},
 	{
 		URI:                   "/users/{userId}/follow",
 		Method:                http.MethodPost,
 		Function:              controllers.FollowUser,
 		RequireAuthentication: true,
+		RequireScopes:         []string{models.ScopeFollowsWrite},
 	},
 	{
 		URI:                   "/users/{userId}/stop-follow",
 		Method:                http.MethodPost,
 		Function:              controllers.StopFollowUser,
 		RequireAuthentication: true,
+		RequireScopes:         []string{models.ScopeFollowsWrite},
 	},
 	{
 		URI:                   "/users/{userId}/followers",
 		Method:                http.MethodGet,
 		Function:              controllers.GetFollowers,
 		RequireAuthentication: true,
+		RequireScopes:         []string{models.ScopeFollowsRead},
 	},
 	{
 		URI:                   "/users/{userId}/following",
 		Method:                http.MethodGet,
 		Function:              controllers.GetFollowing,
 		RequireAuthentication: true,
+		RequireScopes:         []string{models.ScopeFollowsRead},
 	},
 	{
 		URI:                   "/users/{userId}/update-password",
@@ -66,4 +107,88 @@ var usersRoutes = []Route{
 		Function:              controllers.UpdatePassword,
 		RequireAuthentication: true,
 	},
+	{
+		URI:                   "/users/{userId}/block",
+		Method:                http.MethodPost,
+		Function:              controllers.BlockUser,
+		RequireAuthentication: true,
+		RequireScopes:         []string{models.ScopeFollowsWrite},
+	},
+	{
+		URI:                   "/users/{userId}/unblock",
+		Method:                http.MethodPost,
+		Function:              controllers.UnblockUser,
+		RequireAuthentication: true,
+		RequireScopes:         []string{models.ScopeFollowsWrite},
+	},
+	{
+		URI:                   "/users/{userId}/mute",
+		Method:                http.MethodPost,
+		Function:              controllers.MuteUser,
+		RequireAuthentication: true,
+		RequireScopes:         []string{models.ScopeFollowsWrite},
+	},
+	{
+		URI:                   "/users/{userId}/unmute",
+		Method:                http.MethodPost,
+		Function:              controllers.UnmuteUser,
+		RequireAuthentication: true,
+		RequireScopes:         []string{models.ScopeFollowsWrite},
+	},
+	{
+		URI:                   "/blocks",
+		Method:                http.MethodGet,
+		Function:              controllers.GetBlockedUsers,
+		RequireAuthentication: true,
+		RequireScopes:         []string{models.ScopeFollowsRead},
+	},
+	{
+		URI:                   "/mutes",
+		Method:                http.MethodGet,
+		Function:              controllers.GetMutedUsers,
+		RequireAuthentication: true,
+		RequireScopes:         []string{models.ScopeFollowsRead},
+	},
+	{
+		URI:                   "/follow-requests",
+		Method:                http.MethodGet,
+		Function:              controllers.GetFollowRequests,
+		RequireAuthentication: true,
+		RequireScopes:         []string{models.ScopeFollowsRead},
+	},
+	{
+		URI:                   "/follow-requests/{userId}/approve",
+		Method:                http.MethodPost,
+		Function:              controllers.ApproveFollowRequest,
+		RequireAuthentication: true,
+		RequireScopes:         []string{models.ScopeFollowsWrite},
+	},
+	{
+		URI:                   "/follow-requests/{userId}/reject",
+		Method:                http.MethodPost,
+		Function:              controllers.RejectFollowRequest,
+		RequireAuthentication: true,
+		RequireScopes:         []string{models.ScopeFollowsWrite},
+	},
+	{
+		URI:                   "/users/{userId}/relationship",
+		Method:                http.MethodGet,
+		Function:              controllers.GetRelationship,
+		RequireAuthentication: true,
+		RequireScopes:         []string{models.ScopeFollowsRead},
+	},
+	{
+		URI:                   "/users/{userId}/relationships",
+		Method:                http.MethodGet,
+		Function:              controllers.GetRelationships,
+		RequireAuthentication: true,
+		RequireScopes:         []string{models.ScopeFollowsRead},
+	},
+	{
+		URI:                   "/users/{userId}/mutuals",
+		Method:                http.MethodGet,
+		Function:              controllers.GetMutuals,
+		RequireAuthentication: true,
+		RequireScopes:         []string{models.ScopeFollowsRead},
+	},
 }
@@ -0,0 +1,45 @@
+package routes
+
+import (
+	"api/src/controllers"
+	"api/src/models"
+	"net/http"
+)
+
+var webhooksRoutes = []Route{
+	{
+		URI:                   "/webhooks",
+		Method:                http.MethodPost,
+		Function:              controllers.CreateWebhook,
+		RequireAuthentication: true,
+		RequireScopes:         []string{models.ScopeWebhooksWrite},
+	},
+	{
+		URI:                   "/webhooks",
+		Method:                http.MethodGet,
+		Function:              controllers.GetWebhooks,
+		RequireAuthentication: true,
+		RequireScopes:         []string{models.ScopeWebhooksRead},
+	},
+	{
+		URI:                   "/webhooks/{webhookId}",
+		Method:                http.MethodDelete,
+		Function:              controllers.DeleteWebhook,
+		RequireAuthentication: true,
+		RequireScopes:         []string{models.ScopeWebhooksWrite},
+	},
+	{
+		URI:                   "/webhooks/{webhookId}/deliveries",
+		Method:                http.MethodGet,
+		Function:              controllers.GetWebhookDeliveries,
+		RequireAuthentication: true,
+		RequireScopes:         []string{models.ScopeWebhooksRead},
+	},
+	{
+		URI:                   "/webhooks/{webhookId}/deliveries/{deliveryId}/replay",
+		Method:                http.MethodPost,
+		Function:              controllers.ReplayWebhookDelivery,
+		RequireAuthentication: true,
+		RequireScopes:         []string{models.ScopeWebhooksWrite},
+	},
+}
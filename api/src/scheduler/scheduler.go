@@ -0,0 +1,79 @@
+package scheduler
+
+import (
+	"api/src/models"
+	"log"
+	"time"
+)
+
+// Clock fornece a hora atual, podendo ser substituído em testes
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock usa o relógio do sistema
+type SystemClock struct{}
+
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// Promoter publica as publicações agendadas que venceram até now
+type Promoter interface {
+	PromoteDue(now time.Time) ([]models.Publish, error)
+}
+
+// Scheduler promove periodicamente as publicações agendadas. A exclusividade
+// entre instâncias da API fica a cargo do Promoter, que trava as linhas no banco.
+type Scheduler struct {
+	promoter  Promoter
+	clock     Clock
+	interval  time.Duration
+	onPublish func(publish models.Publish) error
+}
+
+// NewScheduler cria o scheduler. onPublish é chamada para cada publicação
+// promovida, depois que ela já foi gravada como publicada.
+func NewScheduler(promoter Promoter, clock Clock, interval time.Duration, onPublish func(publish models.Publish) error) *Scheduler {
+	return &Scheduler{
+		promoter:  promoter,
+		clock:     clock,
+		interval:  interval,
+		onPublish: onPublish,
+	}
+}
+
+// Run executa o scheduler indefinidamente, devendo ser chamado em uma goroutine
+func (s *Scheduler) Run() {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := s.Tick(); err != nil {
+			log.Println("scheduler:", err)
+		}
+	}
+}
+
+// Tick promove, em lotes, todas as publicações vencidas na hora atual do Clock
+func (s *Scheduler) Tick() error {
+	now := s.clock.Now()
+	for {
+		publishes, err := s.promoter.PromoteDue(now)
+		if err != nil {
+			return err
+		}
+		if len(publishes) == 0 {
+			return nil
+		}
+
+		for _, publish := range publishes {
+			if s.onPublish == nil {
+				continue
+			}
+			if err = s.onPublish(publish); err != nil {
+				log.Println("scheduler:", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package scheduler
+
+import (
+	"api/src/models"
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+var baseTime = time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+
+type fixedClock struct {
+	now time.Time
+}
+
+func (c *fixedClock) Now() time.Time {
+	return c.now
+}
+
+// memoryPromoter faz em memória o que o repositório faz no banco: promove, em
+// lotes ordenados por publish_at e id, as agendadas vencidas até now
+type memoryPromoter struct {
+	scheduled []models.Publish
+	batchSize int
+	calls     []time.Time
+	err       error
+}
+
+func (m *memoryPromoter) PromoteDue(now time.Time) ([]models.Publish, error) {
+	m.calls = append(m.calls, now)
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	sort.Slice(m.scheduled, func(i, j int) bool {
+		if !m.scheduled[i].PublishAt.Equal(*m.scheduled[j].PublishAt) {
+			return m.scheduled[i].PublishAt.Before(*m.scheduled[j].PublishAt)
+		}
+		return m.scheduled[i].ID < m.scheduled[j].ID
+	})
+
+	var due, remaining []models.Publish
+	for _, publish := range m.scheduled {
+		if !publish.PublishAt.After(now) && len(due) < m.batchSize {
+			publish.Status = models.PublishPublished
+			due = append(due, publish)
+			continue
+		}
+		remaining = append(remaining, publish)
+	}
+	m.scheduled = remaining
+
+	return due, nil
+}
+
+func scheduledAt(id uint64, offset time.Duration) models.Publish {
+	publishAt := baseTime.Add(offset)
+	return models.Publish{ID: id, Status: models.PublishScheduled, PublishAt: &publishAt}
+}
+
+func publishIDs(publishes []models.Publish) []uint64 {
+	ids := []uint64{}
+	for _, publish := range publishes {
+		ids = append(ids, publish.ID)
+	}
+	return ids
+}
+
+func TestTickPromotesDuePublishes(t *testing.T) {
+	clock := &fixedClock{now: baseTime}
+	promoter := &memoryPromoter{
+		batchSize: 2,
+		scheduled: []models.Publish{
+			scheduledAt(5, time.Hour),
+			scheduledAt(4, 0),
+			scheduledAt(3, -time.Minute),
+			scheduledAt(2, -time.Hour),
+			scheduledAt(1, -time.Minute),
+			scheduledAt(6, time.Second),
+		},
+	}
+
+	var promoted []models.Publish
+	scheduler := NewScheduler(promoter, clock, time.Minute, func(publish models.Publish) error {
+		promoted = append(promoted, publish)
+		return nil
+	})
+
+	if err := scheduler.Tick(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Vencidas, inclusive a que vence exatamente agora, na ordem de publish_at e id
+	if got, want := publishIDs(promoted), []uint64{2, 1, 3, 4}; !reflect.DeepEqual(got, want) {
+		t.Errorf("promovidas = %v, esperado %v", got, want)
+	}
+	for _, publish := range promoted {
+		if publish.Status != models.PublishPublished {
+			t.Errorf("publicação %d com estado %s", publish.ID, publish.Status)
+		}
+	}
+	if got, want := publishIDs(promoter.scheduled), []uint64{6, 5}; !reflect.DeepEqual(got, want) {
+		t.Errorf("agendadas restantes = %v, esperado %v", got, want)
+	}
+
+	// Todos os lotes usam a mesma hora do relógio, até um lote vazio
+	if len(promoter.calls) != 3 {
+		t.Errorf("PromoteDue chamado %d vezes, esperado 3", len(promoter.calls))
+	}
+	for _, call := range promoter.calls {
+		if !call.Equal(baseTime) {
+			t.Errorf("PromoteDue chamado com %v, esperado %v", call, baseTime)
+		}
+	}
+
+	promoted = nil
+	if err := scheduler.Tick(); err != nil {
+		t.Fatal(err)
+	}
+	if len(promoted) != 0 {
+		t.Errorf("nada deveria ser promovido sem o relógio andar, veio %v", publishIDs(promoted))
+	}
+
+	clock.now = baseTime.Add(time.Hour)
+	if err := scheduler.Tick(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := publishIDs(promoted), []uint64{6, 5}; !reflect.DeepEqual(got, want) {
+		t.Errorf("promovidas = %v, esperado %v", got, want)
+	}
+}
+
+func TestTickContinuesAfterCallbackError(t *testing.T) {
+	promoter := &memoryPromoter{
+		batchSize: 10,
+		scheduled: []models.Publish{scheduledAt(1, -time.Minute), scheduledAt(2, -time.Minute)},
+	}
+
+	var calls int
+	scheduler := NewScheduler(promoter, &fixedClock{now: baseTime}, time.Minute, func(publish models.Publish) error {
+		calls++
+		return errors.New("falha ao anunciar")
+	})
+
+	if err := scheduler.Tick(); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 {
+		t.Errorf("onPublish chamado %d vezes, esperado 2", calls)
+	}
+}
+
+func TestTickReturnsPromoterError(t *testing.T) {
+	promoter := &memoryPromoter{err: errors.New("banco indisponível")}
+	scheduler := NewScheduler(promoter, &fixedClock{now: baseTime}, time.Minute, nil)
+
+	if err := scheduler.Tick(); err != promoter.err {
+		t.Errorf("Tick() = %v, esperado %v", err, promoter.err)
+	}
+}
@@ -0,0 +1,69 @@
+package search
+
+import (
+	"api/src/models"
+	"time"
+)
+
+// Query descreve uma busca de publicações. Text aceita termos simples,
+// prefixos terminados em * e frases entre aspas; todos precisam casar.
+// Visible, quando informado, descarta os autores que quem busca não pode ver
+// antes da paginação, para que as páginas venham completas.
+type Query struct {
+	Text     string
+	AuthorID uint64
+	From     time.Time
+	To       time.Time
+	Limit    uint64
+	Offset   uint64
+	Visible  func(authorID uint64) (bool, error)
+}
+
+type Result struct {
+	PublishID uint64  `json:"publish_id"`
+	AuthorID  uint64  `json:"author_id"`
+	Score     float64 `json:"score"`
+}
+
+// Index é o mecanismo de busca das publicações. Implementações que dependem
+// do próprio banco, como a MySQL, podem ignorar Index e Remove.
+type Index interface {
+	Index(publish models.Publish) error
+	Remove(publishID uint64) error
+	Search(query Query) ([]Result, error)
+}
+
+// Engine é o índice usado pela API, definido em main conforme a configuração
+var Engine Index = NewMemoryIndex()
+
+// visibility aplica Query.Visible consultando cada autor uma única vez
+type visibility struct {
+	check   func(authorID uint64) (bool, error)
+	checked map[uint64]bool
+}
+
+func newVisibility(check func(authorID uint64) (bool, error)) *visibility {
+	return &visibility{check: check, checked: map[uint64]bool{}}
+}
+
+func (v *visibility) filter(results []Result) ([]Result, error) {
+	if v.check == nil {
+		return results, nil
+	}
+
+	visible := []Result{}
+	for _, result := range results {
+		allowed, ok := v.checked[result.AuthorID]
+		if !ok {
+			var err error
+			if allowed, err = v.check(result.AuthorID); err != nil {
+				return nil, err
+			}
+			v.checked[result.AuthorID] = allowed
+		}
+		if allowed {
+			visible = append(visible, result)
+		}
+	}
+	return visible, nil
+}
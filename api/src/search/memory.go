@@ -0,0 +1,212 @@
+package search
+
+import (
+	"api/src/models"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Distância entre as posições do título e do conteúdo, para que uma frase não
+// case atravessando os dois campos
+const fieldGap = 100
+
+type document struct {
+	authorID  uint64
+	createdAt time.Time
+	length    int
+}
+
+// MemoryIndex é um índice invertido em memória, usado nos testes e quando a
+// API roda sem o índice FULLTEXT do MySQL
+type MemoryIndex struct {
+	mu        sync.RWMutex
+	documents map[uint64]document
+	postings  map[string]map[uint64][]int
+}
+
+func NewMemoryIndex() *MemoryIndex {
+	return &MemoryIndex{
+		documents: map[uint64]document{},
+		postings:  map[string]map[uint64][]int{},
+	}
+}
+
+func (m *MemoryIndex) Index(publish models.Publish) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.remove(publish.ID)
+
+	titleTerms := tokenize(publish.Title)
+	contentTerms := tokenize(publish.Content)
+	for position, term := range titleTerms {
+		m.add(term, publish.ID, position)
+	}
+	for position, term := range contentTerms {
+		m.add(term, publish.ID, len(titleTerms)+fieldGap+position)
+	}
+
+	m.documents[publish.ID] = document{
+		authorID:  publish.AuthorID,
+		createdAt: publish.CreatedAt,
+		length:    len(titleTerms) + len(contentTerms),
+	}
+	return nil
+}
+
+func (m *MemoryIndex) Remove(publishID uint64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.remove(publishID)
+	return nil
+}
+
+func (m *MemoryIndex) Search(query Query) ([]Result, error) {
+	// A visibilidade é conferida fora da trava, já que pode consultar o banco
+	results, err := newVisibility(query.Visible).filter(m.rank(query))
+	if err != nil {
+		return nil, err
+	}
+	return paginate(results, query.Limit, query.Offset), nil
+}
+
+// rank retorna todos os documentos que casam com a consulta, do mais relevante ao menos
+func (m *MemoryIndex) rank(query Query) []Result {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	clauses := parse(query.Text)
+	if len(clauses) == 0 {
+		return []Result{}
+	}
+
+	var scores map[uint64]float64
+	for _, c := range clauses {
+		matches := m.match(c)
+		if scores == nil {
+			scores = matches
+			continue
+		}
+		for publishID := range scores {
+			if score, ok := matches[publishID]; ok {
+				scores[publishID] += score
+			} else {
+				delete(scores, publishID)
+			}
+		}
+	}
+
+	results := []Result{}
+	for publishID, score := range scores {
+		doc := m.documents[publishID]
+		if query.AuthorID != 0 && doc.authorID != query.AuthorID {
+			continue
+		}
+		if !query.From.IsZero() && doc.createdAt.Before(query.From) {
+			continue
+		}
+		if !query.To.IsZero() && doc.createdAt.After(query.To) {
+			continue
+		}
+		// Documentos longos não devem vencer apenas por repetirem mais termos
+		score = score / math.Sqrt(float64(doc.length))
+		results = append(results, Result{PublishID: publishID, AuthorID: doc.authorID, Score: score})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].PublishID > results[j].PublishID
+	})
+
+	return results
+}
+
+// match retorna a pontuação tf-idf de cada documento que satisfaz a cláusula
+func (m *MemoryIndex) match(c clause) map[uint64]float64 {
+	scores := map[uint64]float64{}
+
+	if c.phrase() {
+		first := m.postings[c.terms[0]]
+		for publishID, positions := range first {
+			occurrences := 0
+			for _, position := range positions {
+				if m.phraseAt(c.terms[1:], publishID, position+1) {
+					occurrences++
+				}
+			}
+			if occurrences > 0 {
+				scores[publishID] = float64(occurrences) * m.idf(len(first)) * float64(len(c.terms))
+			}
+		}
+		return scores
+	}
+
+	for term, documents := range m.postings {
+		if term != c.terms[0] && !(c.prefix && strings.HasPrefix(term, c.terms[0])) {
+			continue
+		}
+		idf := m.idf(len(documents))
+		for publishID, positions := range documents {
+			scores[publishID] += float64(len(positions)) * idf
+		}
+	}
+	return scores
+}
+
+func (m *MemoryIndex) phraseAt(terms []string, publishID uint64, position int) bool {
+	for i, term := range terms {
+		found := false
+		for _, p := range m.postings[term][publishID] {
+			if p == position+i {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *MemoryIndex) idf(documentFrequency int) float64 {
+	return math.Log(1 + float64(len(m.documents))/float64(documentFrequency))
+}
+
+func (m *MemoryIndex) add(term string, publishID uint64, position int) {
+	if m.postings[term] == nil {
+		m.postings[term] = map[uint64][]int{}
+	}
+	m.postings[term][publishID] = append(m.postings[term][publishID], position)
+}
+
+func (m *MemoryIndex) remove(publishID uint64) {
+	if _, ok := m.documents[publishID]; !ok {
+		return
+	}
+
+	for term, documents := range m.postings {
+		delete(documents, publishID)
+		if len(documents) == 0 {
+			delete(m.postings, term)
+		}
+	}
+	delete(m.documents, publishID)
+}
+
+func paginate(results []Result, limit, offset uint64) []Result {
+	if offset >= uint64(len(results)) {
+		return []Result{}
+	}
+	results = results[offset:]
+	if limit > 0 && limit < uint64(len(results)) {
+		results = results[:limit]
+	}
+	return results
+}
@@ -0,0 +1,237 @@
+package search
+
+import (
+	"api/src/models"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var day = time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"Olá, Mundo!", []string{"olá", "mundo"}},
+		{"go1.22 e C++", []string{"go1", "22", "e", "c"}},
+		{"  #golang @maria  ", []string{"golang", "maria"}},
+		{"AÇÃO-ação", []string{"ação", "ação"}},
+		{"...", []string{}},
+	}
+
+	for _, test := range tests {
+		got := tokenize(test.text)
+		if len(got) == 0 && len(test.want) == 0 {
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("tokenize(%q) = %q, esperado %q", test.text, got, test.want)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	got := parse(`Go prog* "banco de dados" ""`)
+	want := []clause{
+		{terms: []string{"go"}},
+		{terms: []string{"prog"}, prefix: true},
+		{terms: []string{"banco", "de", "dados"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parse = %+v, esperado %+v", got, want)
+	}
+}
+
+func newTestIndex(t *testing.T, publishes ...models.Publish) *MemoryIndex {
+	index := NewMemoryIndex()
+	for _, publish := range publishes {
+		if err := index.Index(publish); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return index
+}
+
+func search(t *testing.T, index *MemoryIndex, query Query) []uint64 {
+	t.Helper()
+	results, err := index.Search(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ids := []uint64{}
+	for _, result := range results {
+		ids = append(ids, result.PublishID)
+	}
+	return ids
+}
+
+func TestSearchMatching(t *testing.T) {
+	index := newTestIndex(t,
+		models.Publish{ID: 1, AuthorID: 1, Title: "Banco de dados", Content: "MySQL e índices", CreatedAt: day},
+		models.Publish{ID: 2, AuthorID: 2, Title: "Programação", Content: "Go para bancos de dados", CreatedAt: day.Add(24 * time.Hour)},
+		models.Publish{ID: 3, AuthorID: 1, Title: "Dados", Content: "de banco em banco", CreatedAt: day.Add(48 * time.Hour)},
+		models.Publish{ID: 4, AuthorID: 3, Title: "Banco", Content: "de praça", CreatedAt: day},
+	)
+
+	tests := []struct {
+		name  string
+		query Query
+		want  []uint64
+	}{
+		{"termo simples", Query{Text: "mysql"}, []uint64{1}},
+		{"todos os termos precisam casar", Query{Text: "banco dados"}, []uint64{3, 1}},
+		{"prefixo", Query{Text: "banc*"}, []uint64{3, 2, 4, 1}},
+		{"frase", Query{Text: `"banco de dados"`}, []uint64{1}},
+		{"frase não atravessa título e conteúdo", Query{Text: `"banco de praça"`}, []uint64{}},
+		{"sem diferença de maiúsculas", Query{Text: "PROGRAMAÇÃO"}, []uint64{2}},
+		{"autor", Query{Text: "banco", AuthorID: 1}, []uint64{3, 1}},
+		{"período", Query{Text: "banc*", From: day.Add(time.Hour), To: day.Add(36 * time.Hour)}, []uint64{2}},
+		{"consulta vazia", Query{Text: `"" *`}, []uint64{}},
+		{"nenhum resultado", Query{Text: "rust"}, []uint64{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := search(t, index, test.query); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Search(%q) = %v, esperado %v", test.query.Text, got, test.want)
+			}
+		})
+	}
+}
+
+func TestSearchRanking(t *testing.T) {
+	index := newTestIndex(t,
+		// O termo raro pesa mais que o comum
+		models.Publish{ID: 1, Title: "Go", Content: "linguagem"},
+		models.Publish{ID: 2, Title: "Go", Content: "concorrência"},
+		models.Publish{ID: 3, Title: "Go", Content: "linguagem concorrência"},
+		// Mais ocorrências no mesmo tamanho de texto pontuam mais
+		models.Publish{ID: 4, Title: "Rust", Content: "rust rust"},
+		models.Publish{ID: 5, Title: "Rust", Content: "memória segura"},
+		// Empates ficam com a publicação mais recente, pelo ID
+		models.Publish{ID: 6, Title: "Zig"},
+		models.Publish{ID: 7, Title: "Zig"},
+	)
+
+	tests := []struct {
+		query string
+		want  []uint64
+	}{
+		{"go concorrência", []uint64{2, 3}},
+		{"rust", []uint64{4, 5}},
+		{"zig", []uint64{7, 6}},
+	}
+
+	for _, test := range tests {
+		if got := search(t, index, Query{Text: test.query}); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("Search(%q) = %v, esperado %v", test.query, got, test.want)
+		}
+	}
+}
+
+func TestSearchPagination(t *testing.T) {
+	var publishes []models.Publish
+	for id := uint64(1); id <= 5; id++ {
+		publishes = append(publishes, models.Publish{ID: id, AuthorID: id % 2, Title: "Página", Content: "Conteúdo"})
+	}
+	index := newTestIndex(t, publishes...)
+
+	tests := []struct {
+		name  string
+		query Query
+		want  []uint64
+	}{
+		{"primeira página", Query{Text: "página", Limit: 2}, []uint64{5, 4}},
+		{"segunda página", Query{Text: "página", Limit: 2, Offset: 2}, []uint64{3, 2}},
+		{"última página incompleta", Query{Text: "página", Limit: 2, Offset: 4}, []uint64{1}},
+		{"depois do fim", Query{Text: "página", Limit: 2, Offset: 10}, []uint64{}},
+		{"sem limite", Query{Text: "página"}, []uint64{5, 4, 3, 2, 1}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := search(t, index, test.query); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Search = %v, esperado %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestSearchVisibility(t *testing.T) {
+	var publishes []models.Publish
+	for id := uint64(1); id <= 6; id++ {
+		publishes = append(publishes, models.Publish{ID: id, AuthorID: id % 3, Title: "Visível"})
+	}
+	index := newTestIndex(t, publishes...)
+
+	checks := map[uint64]int{}
+	hidden := func(authorID uint64) (bool, error) {
+		checks[authorID]++
+		return authorID != 0, nil
+	}
+
+	// Os autores invisíveis saem antes da paginação, então as páginas vêm completas
+	pages := [][]uint64{
+		search(t, index, Query{Text: "visível", Limit: 2, Visible: hidden}),
+		search(t, index, Query{Text: "visível", Limit: 2, Offset: 2, Visible: hidden}),
+		search(t, index, Query{Text: "visível", Limit: 2, Offset: 4, Visible: hidden}),
+	}
+	want := [][]uint64{{5, 4}, {2, 1}, {}}
+	if !reflect.DeepEqual(pages, want) {
+		t.Errorf("páginas = %v, esperado %v", pages, want)
+	}
+
+	// Cada autor é consultado uma vez por busca
+	for authorID, count := range checks {
+		if count != len(pages) {
+			t.Errorf("autor %d consultado %d vezes em %d buscas", authorID, count, len(pages))
+		}
+	}
+
+	failure := errors.New("banco indisponível")
+	_, err := index.Search(Query{Text: "visível", Visible: func(uint64) (bool, error) { return false, failure }})
+	if err != failure {
+		t.Errorf("erro = %v, esperado %v", err, failure)
+	}
+}
+
+func TestIndexAndRemove(t *testing.T) {
+	index := newTestIndex(t, models.Publish{ID: 1, Title: "Antigo", Content: "texto"})
+
+	if err := index.Index(models.Publish{ID: 1, Title: "Novo", Content: "texto"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := search(t, index, Query{Text: "antigo"}); len(got) != 0 {
+		t.Errorf("o título antigo não deveria ser encontrado: %v", got)
+	}
+	if got := search(t, index, Query{Text: "novo"}); !reflect.DeepEqual(got, []uint64{1}) {
+		t.Errorf("Search(novo) = %v", got)
+	}
+
+	if err := index.Remove(1); err != nil {
+		t.Fatal(err)
+	}
+	if got := search(t, index, Query{Text: "texto"}); len(got) != 0 {
+		t.Errorf("a publicação removida não deveria ser encontrada: %v", got)
+	}
+	if len(index.postings) != 0 || len(index.documents) != 0 {
+		t.Errorf("o índice deveria estar vazio: %d termos, %d documentos", len(index.postings), len(index.documents))
+	}
+
+	// Remover o que não está no índice não é erro
+	if err := index.Remove(42); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBooleanQuery(t *testing.T) {
+	got := booleanQuery(parse(`Go prog* "banco de dados"`))
+	want := `+go +prog* +"banco de dados"`
+	if got != want {
+		t.Errorf("booleanQuery = %q, esperado %q", got, want)
+	}
+}
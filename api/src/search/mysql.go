@@ -0,0 +1,118 @@
+package search
+
+import (
+	"api/src/models"
+	"database/sql"
+	"strings"
+)
+
+// Tamanho dos lotes buscados quando os resultados precisam ser filtrados por visibilidade
+const searchBatchSize = 100
+
+// MySQLIndex usa o índice FULLTEXT da tabela publishes. Como o próprio banco
+// mantém o índice, Index e Remove não fazem nada.
+type MySQLIndex struct {
+	db *sql.DB
+}
+
+func NewMySQLIndex(db *sql.DB) *MySQLIndex {
+	return &MySQLIndex{db: db}
+}
+
+func (m *MySQLIndex) Index(publish models.Publish) error {
+	return nil
+}
+
+func (m *MySQLIndex) Remove(publishID uint64) error {
+	return nil
+}
+
+func (m *MySQLIndex) Search(query Query) ([]Result, error) {
+	against := booleanQuery(parse(query.Text))
+	if against == "" {
+		return []Result{}, nil
+	}
+
+	conditions := []string{"match(title, content) against (? in boolean mode)", "status = 'published'"}
+	args := []interface{}{against, against}
+	if query.AuthorID != 0 {
+		conditions = append(conditions, "author_id = ?")
+		args = append(args, query.AuthorID)
+	}
+	if !query.From.IsZero() {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, query.From)
+	}
+	if !query.To.IsZero() {
+		conditions = append(conditions, "created_at <= ?")
+		args = append(args, query.To)
+	}
+	where := strings.Join(conditions, " and ")
+
+	if query.Visible == nil {
+		return m.search(where, args, query.Limit, query.Offset)
+	}
+
+	// Com filtro de visibilidade, os resultados são buscados em lotes a partir
+	// do início e paginados aqui, depois de descartados os autores invisíveis
+	visibility := newVisibility(query.Visible)
+	visible := []Result{}
+	for offset := uint64(0); query.Limit == 0 || uint64(len(visible)) < query.Offset+query.Limit; offset += searchBatchSize {
+		batch, err := m.search(where, args, searchBatchSize, offset)
+		if err != nil {
+			return nil, err
+		}
+
+		allowed, err := visibility.filter(batch)
+		if err != nil {
+			return nil, err
+		}
+		visible = append(visible, allowed...)
+
+		if len(batch) < searchBatchSize {
+			break
+		}
+	}
+
+	return paginate(visible, query.Limit, query.Offset), nil
+}
+
+func (m *MySQLIndex) search(where string, args []interface{}, limit, offset uint64) ([]Result, error) {
+	rows, err := m.db.Query(
+		`select id, author_id, match(title, content) against (? in boolean mode) as score
+		from publishes where `+where+`
+		order by score desc, id desc limit ? offset ?`,
+		append(append([]interface{}{}, args...), limit, offset)...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := []Result{}
+	for rows.Next() {
+		var result Result
+		if err = rows.Scan(&result.PublishID, &result.AuthorID, &result.Score); err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+
+	return results, rows.Err()
+}
+
+// booleanQuery traduz as cláusulas para o modo booleano do MySQL, exigindo todas elas
+func booleanQuery(clauses []clause) string {
+	var parts []string
+	for _, c := range clauses {
+		switch {
+		case c.phrase():
+			parts = append(parts, `+"`+strings.Join(c.terms, " ")+`"`)
+		case c.prefix:
+			parts = append(parts, "+"+c.terms[0]+"*")
+		default:
+			parts = append(parts, "+"+c.terms[0])
+		}
+	}
+	return strings.Join(parts, " ")
+}
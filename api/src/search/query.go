@@ -0,0 +1,45 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// clause é uma parte da consulta: um termo, um prefixo ou uma frase
+type clause struct {
+	terms  []string
+	prefix bool
+}
+
+func (c clause) phrase() bool {
+	return len(c.terms) > 1
+}
+
+// parse separa o texto em frases entre aspas, prefixos (termo*) e termos simples
+func parse(text string) []clause {
+	var clauses []clause
+	for i, part := range strings.Split(text, `"`) {
+		// Partes ímpares estão entre aspas
+		if i%2 == 1 {
+			if terms := tokenize(part); len(terms) > 0 {
+				clauses = append(clauses, clause{terms: terms})
+			}
+			continue
+		}
+
+		for _, word := range strings.Fields(part) {
+			prefix := strings.HasSuffix(word, "*")
+			for _, term := range tokenize(word) {
+				clauses = append(clauses, clause{terms: []string{term}, prefix: prefix})
+			}
+		}
+	}
+	return clauses
+}
+
+// tokenize quebra o texto em termos minúsculos compostos de letras e números
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
@@ -1,6 +1,13 @@
 package security
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"golang.org/x/crypto/bcrypt"
+)
 
 func Hash(password string) (string,error) {
 	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
@@ -13,4 +20,21 @@ func Hash(password string) (string,error) {
 
 func VerifyPassword(hashPassword, stringPassword string) error {
 	return  bcrypt.CompareHashAndPassword([]byte(hashPassword), []byte(stringPassword))
-}
\ No newline at end of file
+}
+
+// RandomToken gera um valor aleatório com size bytes codificado em hexadecimal
+func RandomToken(size int) (string, error) {
+	buffer := make([]byte, size)
+	if _, err := rand.Read(buffer); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(buffer), nil
+}
+
+// Sign assina o payload com HMAC-SHA256 usando a chave informada
+func Sign(key string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// LocalStore grava os arquivos em um diretório do sistema de arquivos.
+// O content type é guardado em um arquivo ao lado do conteúdo.
+type LocalStore struct {
+	root string
+}
+
+func NewLocalStore(root string) *LocalStore {
+	return &LocalStore{root: root}
+}
+
+func (l *LocalStore) Put(key, contentType string, data []byte) error {
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	if err = ioutil.WriteFile(path, data, 0644); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path+".type", []byte(contentType), 0644)
+}
+
+func (l *LocalStore) Get(key string) (io.ReadCloser, string, error) {
+	path, err := l.path(key)
+	if err != nil {
+		return nil, "", err
+	}
+
+	contentType, err := ioutil.ReadFile(path + ".type")
+	if os.IsNotExist(err) {
+		return nil, "", ErrNotFound
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, "", ErrNotFound
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	return file, string(contentType), nil
+}
+
+func (l *LocalStore) Delete(key string) error {
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err = os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err = os.Remove(path + ".type"); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+// path impede que a chave aponte para fora do diretório raiz
+func (l *LocalStore) path(key string) (string, error) {
+	clean := filepath.Clean("/" + key)
+	if clean == "/" {
+		return "", ErrNotFound
+	}
+
+	return filepath.Join(l.root, clean), nil
+}
@@ -0,0 +1,208 @@
+package storage
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	testAccessKey = "AKIDEXAMPLE"
+	testSecretKey = "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"
+	testRegion    = "us-east-1"
+	testBucket    = "media"
+)
+
+type object struct {
+	contentType string
+	data        []byte
+}
+
+// s3StandIn imita um bucket S3 no estilo path. Ele confere a assinatura V4 de
+// cada requisição, calculada aqui de forma independente da usada pelo S3Store,
+// e registra as recusas como falha em t, quando informado.
+type s3StandIn struct {
+	t       *testing.T
+	mu      sync.Mutex
+	objects map[string]object
+}
+
+func newS3StandIn(t *testing.T) (*s3StandIn, *httptest.Server) {
+	standIn := &s3StandIn{t: t, objects: map[string]object{}}
+	return standIn, httptest.NewServer(standIn)
+}
+
+func (s *s3StandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if err = s.verify(r, body); err != nil {
+		if s.t != nil {
+			s.t.Errorf("%s %s: %v", r.Method, r.URL.Path, err)
+		}
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, "<Error><Code>SignatureDoesNotMatch</Code></Error>")
+		return
+	}
+
+	prefix := "/" + testBucket + "/"
+	if !strings.HasPrefix(r.URL.Path, prefix) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	key := strings.TrimPrefix(r.URL.Path, prefix)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch r.Method {
+	case http.MethodPut:
+		s.objects[key] = object{contentType: r.Header.Get("Content-Type"), data: body}
+		w.WriteHeader(http.StatusOK)
+	case http.MethodGet:
+		stored, found := s.objects[key]
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "<Error><Code>NoSuchKey</Code></Error>")
+			return
+		}
+		w.Header().Set("Content-Type", stored.contentType)
+		w.Write(stored.data)
+	case http.MethodDelete:
+		delete(s.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *s3StandIn) verify(r *http.Request, body []byte) error {
+	sum := sha256.Sum256(body)
+	payloadHash := hex.EncodeToString(sum[:])
+	if r.Header.Get("X-Amz-Content-Sha256") != payloadHash {
+		return fmt.Errorf("hash do conteúdo não confere")
+	}
+
+	amzDate := r.Header.Get("X-Amz-Date")
+	signedAt, err := time.Parse("20060102T150405Z", amzDate)
+	if err != nil {
+		return fmt.Errorf("X-Amz-Date inválido: %q", amzDate)
+	}
+	if age := time.Since(signedAt); age > 15*time.Minute || age < -15*time.Minute {
+		return fmt.Errorf("requisição assinada há %v", age)
+	}
+
+	scope := signedAt.Format("20060102") + "/" + testRegion + "/s3/aws4_request"
+	canonicalRequest := r.Method + "\n" +
+		r.URL.EscapedPath() + "\n" +
+		r.URL.RawQuery + "\n" +
+		"host:" + r.Host + "\n" +
+		"x-amz-content-sha256:" + payloadHash + "\n" +
+		"x-amz-date:" + amzDate + "\n" +
+		"\n" +
+		"host;x-amz-content-sha256;x-amz-date\n" +
+		payloadHash
+	canonicalHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(canonicalHash[:])
+
+	key := []byte("AWS4" + testSecretKey)
+	for _, part := range []string{signedAt.Format("20060102"), testRegion, "s3", "aws4_request", stringToSign} {
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(part))
+		key = mac.Sum(nil)
+	}
+
+	want := fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=host;x-amz-content-sha256;x-amz-date, Signature=%s",
+		testAccessKey, scope, hex.EncodeToString(key),
+	)
+	if got := r.Header.Get("Authorization"); got != want {
+		return fmt.Errorf("Authorization = %q, esperado %q", got, want)
+	}
+	return nil
+}
+
+func TestS3StorePutGetDelete(t *testing.T) {
+	standIn, server := newS3StandIn(t)
+	defer server.Close()
+	store := NewS3Store(server.URL+"/", testRegion, testBucket, testAccessKey, testSecretKey)
+
+	data := []byte{0x89, 'P', 'N', 'G', 0, 1, 2}
+	if err := store.Put("avatars/1/foto original.png", "image/png", data); err != nil {
+		t.Fatal(err)
+	}
+	if stored := standIn.objects["avatars/1/foto original.png"]; string(stored.data) != string(data) || stored.contentType != "image/png" {
+		t.Errorf("objeto gravado = %+v", stored)
+	}
+
+	body, contentType, err := store.Get("/avatars/1/foto original.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ioutil.ReadAll(body)
+	body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(data) || contentType != "image/png" {
+		t.Errorf("Get = %q (%s), esperado %q (image/png)", got, contentType, data)
+	}
+
+	if err = store.Delete("avatars/1/foto original.png"); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err = store.Get("avatars/1/foto original.png"); err != ErrNotFound {
+		t.Errorf("Get depois de Delete = %v, esperado ErrNotFound", err)
+	}
+
+	// Apagar o que não existe não é erro, como no S3
+	if err = store.Delete("avatars/1/foto original.png"); err != nil {
+		t.Errorf("Delete de chave inexistente = %v", err)
+	}
+}
+
+func TestS3StoreEmptyObject(t *testing.T) {
+	_, server := newS3StandIn(t)
+	defer server.Close()
+	store := NewS3Store(server.URL, testRegion, testBucket, testAccessKey, testSecretKey)
+
+	if err := store.Put("vazio.txt", "text/plain", nil); err != nil {
+		t.Fatal(err)
+	}
+	body, _, err := store.Get("vazio.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer body.Close()
+	if data, _ := ioutil.ReadAll(body); len(data) != 0 {
+		t.Errorf("conteúdo = %q, esperado vazio", data)
+	}
+}
+
+func TestS3StoreWrongCredentials(t *testing.T) {
+	// Sem t, a assinatura recusada não é uma falha do teste
+	standIn, server := newS3StandIn(nil)
+	defer server.Close()
+
+	store := NewS3Store(server.URL, testRegion, testBucket, testAccessKey, "outra-chave")
+	err := store.Put("arquivo.txt", "text/plain", []byte("conteúdo"))
+	if err == nil {
+		t.Fatal("Put com a chave errada deveria falhar")
+	}
+	if !strings.Contains(err.Error(), "403") || !strings.Contains(err.Error(), "SignatureDoesNotMatch") {
+		t.Errorf("erro = %v", err)
+	}
+	if len(standIn.objects) != 0 {
+		t.Error("nada deveria ter sido gravado")
+	}
+}
@@ -0,0 +1,19 @@
+package storage
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrNotFound é retornado quando a chave não existe no armazenamento
+var ErrNotFound = errors.New("Arquivo não encontrado")
+
+// BlobStore guarda arquivos binários identificados por uma chave
+type BlobStore interface {
+	Put(key, contentType string, data []byte) error
+	Get(key string) (io.ReadCloser, string, error)
+	Delete(key string) error
+}
+
+// Store é o armazenamento usado pela API, definido em main conforme a configuração
+var Store BlobStore = NewLocalStore("uploads")
@@ -0,0 +1,97 @@
+package suggestions
+
+import (
+	"math"
+	"sort"
+)
+
+// Weights controla a importância de cada sinal na pontuação das sugestões
+type Weights struct {
+	Mutual     float64
+	Hashtags   float64
+	Popularity float64
+}
+
+var DefaultWeights = Weights{Mutual: 3, Hashtags: 1.5, Popularity: 0.5}
+
+// Graph reúne o que se sabe do grafo ao redor de quem recebe as sugestões
+type Graph struct {
+	ViewerID uint64
+	// Quem o usuário segue
+	Following map[uint64]bool
+	// Para cada usuário seguido, quem ele segue
+	FollowedBy map[uint64][]uint64
+	// Hashtags usadas recentemente por cada usuário, inclusive o próprio
+	Hashtags map[uint64]map[string]bool
+	// Quantidade de seguidores de cada candidato
+	Followers map[uint64]uint64
+	// Candidatos adicionais, como os usuários mais populares
+	Extra []uint64
+	// Usuários que não podem ser sugeridos: bloqueados, silenciados, dispensados
+	Excluded map[uint64]bool
+}
+
+type Suggestion struct {
+	UserID         uint64
+	Score          float64
+	Mutuals        []uint64
+	SharedHashtags []string
+}
+
+// Rank pontua os amigos de amigos e os candidatos extras e devolve os limit
+// melhores. Empates são resolvidos pelo menor ID, para um resultado estável.
+func Rank(graph Graph, weights Weights, limit int) []Suggestion {
+	candidates := map[uint64]*Suggestion{}
+	candidate := func(userID uint64) *Suggestion {
+		if userID == graph.ViewerID || graph.Following[userID] || graph.Excluded[userID] {
+			return nil
+		}
+		if candidates[userID] == nil {
+			candidates[userID] = &Suggestion{UserID: userID}
+		}
+		return candidates[userID]
+	}
+
+	for friendID, followedByFriend := range graph.FollowedBy {
+		if !graph.Following[friendID] {
+			continue
+		}
+		for _, userID := range followedByFriend {
+			if suggestion := candidate(userID); suggestion != nil {
+				suggestion.Mutuals = append(suggestion.Mutuals, friendID)
+			}
+		}
+	}
+	for _, userID := range graph.Extra {
+		candidate(userID)
+	}
+
+	viewerHashtags := graph.Hashtags[graph.ViewerID]
+	suggestions := make([]Suggestion, 0, len(candidates))
+	for _, suggestion := range candidates {
+		for hashtag := range graph.Hashtags[suggestion.UserID] {
+			if viewerHashtags[hashtag] {
+				suggestion.SharedHashtags = append(suggestion.SharedHashtags, hashtag)
+			}
+		}
+		sort.Strings(suggestion.SharedHashtags)
+		sort.Slice(suggestion.Mutuals, func(i, j int) bool { return suggestion.Mutuals[i] < suggestion.Mutuals[j] })
+
+		suggestion.Score = weights.Mutual*float64(len(suggestion.Mutuals)) +
+			weights.Hashtags*float64(len(suggestion.SharedHashtags)) +
+			weights.Popularity*math.Log1p(float64(graph.Followers[suggestion.UserID]))
+		suggestions = append(suggestions, *suggestion)
+	}
+
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].Score != suggestions[j].Score {
+			return suggestions[i].Score > suggestions[j].Score
+		}
+		return suggestions[i].UserID < suggestions[j].UserID
+	})
+
+	if limit > 0 && len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+	return suggestions
+}
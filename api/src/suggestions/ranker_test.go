@@ -0,0 +1,163 @@
+package suggestions
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Pesos só com o sinal de amigos em comum, para que as pontuações sejam exatas
+var mutualOnly = Weights{Mutual: 1}
+
+func TestRank(t *testing.T) {
+	tests := []struct {
+		name    string
+		graph   Graph
+		weights Weights
+		limit   int
+		want    []uint64
+	}{
+		{
+			name: "ordena pela quantidade de amigos em comum",
+			graph: Graph{
+				ViewerID:  1,
+				Following: map[uint64]bool{2: true, 3: true, 4: true},
+				FollowedBy: map[uint64][]uint64{
+					2: {10, 11, 12},
+					3: {11, 12},
+					4: {12},
+				},
+			},
+			weights: mutualOnly,
+			want:    []uint64{12, 11, 10},
+		},
+		{
+			name: "não sugere quem já é seguido nem o próprio usuário",
+			graph: Graph{
+				ViewerID:  1,
+				Following: map[uint64]bool{2: true, 3: true},
+				FollowedBy: map[uint64][]uint64{
+					2: {1, 3, 10},
+					3: {2, 10},
+				},
+			},
+			weights: mutualOnly,
+			want:    []uint64{10},
+		},
+		{
+			name: "não sugere bloqueados, silenciados e dispensados",
+			graph: Graph{
+				ViewerID:  1,
+				Following: map[uint64]bool{2: true},
+				FollowedBy: map[uint64][]uint64{
+					2: {10, 11, 12, 13},
+				},
+				Extra:    []uint64{14},
+				Excluded: map[uint64]bool{10: true, 12: true, 14: true},
+			},
+			weights: mutualOnly,
+			want:    []uint64{11, 13},
+		},
+		{
+			name: "ignora quem segue usuários que o usuário não segue",
+			graph: Graph{
+				ViewerID:  1,
+				Following: map[uint64]bool{2: true},
+				FollowedBy: map[uint64][]uint64{
+					2: {10},
+					5: {11},
+				},
+			},
+			weights: mutualOnly,
+			want:    []uint64{10},
+		},
+		{
+			name: "empates ficam em ordem de ID",
+			graph: Graph{
+				ViewerID:  1,
+				Following: map[uint64]bool{2: true},
+				FollowedBy: map[uint64][]uint64{
+					2: {30, 10, 20},
+				},
+				Extra: []uint64{40},
+			},
+			weights: mutualOnly,
+			want:    []uint64{10, 20, 30, 40},
+		},
+		{
+			name: "hashtags e popularidade desempatam os candidatos",
+			graph: Graph{
+				ViewerID:  1,
+				Following: map[uint64]bool{2: true},
+				FollowedBy: map[uint64][]uint64{
+					2: {10, 11, 12},
+				},
+				Hashtags: map[uint64]map[string]bool{
+					1:  {"go": true},
+					11: {"go": true},
+				},
+				Followers: map[uint64]uint64{12: 100},
+			},
+			weights: DefaultWeights,
+			want:    []uint64{12, 11, 10},
+		},
+		{
+			name: "respeita o limite",
+			graph: Graph{
+				ViewerID:  1,
+				Following: map[uint64]bool{2: true, 3: true},
+				FollowedBy: map[uint64][]uint64{
+					2: {10, 11, 12},
+					3: {11},
+				},
+			},
+			weights: mutualOnly,
+			limit:   2,
+			want:    []uint64{11, 10},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			suggestions := Rank(test.graph, test.weights, test.limit)
+
+			got := []uint64{}
+			for _, suggestion := range suggestions {
+				got = append(got, suggestion.UserID)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Rank() = %v, esperado %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestRankDetails(t *testing.T) {
+	graph := Graph{
+		ViewerID:  1,
+		Following: map[uint64]bool{2: true, 3: true},
+		FollowedBy: map[uint64][]uint64{
+			3: {10},
+			2: {10},
+		},
+		Hashtags: map[uint64]map[string]bool{
+			1:  {"go": true, "mysql": true, "rust": true},
+			10: {"mysql": true, "go": true, "java": true},
+		},
+	}
+
+	suggestions := Rank(graph, Weights{Mutual: 2, Hashtags: 1}, 0)
+	if len(suggestions) != 1 {
+		t.Fatalf("esperada uma sugestão, veio %d", len(suggestions))
+	}
+
+	suggestion := suggestions[0]
+	if !reflect.DeepEqual(suggestion.Mutuals, []uint64{2, 3}) {
+		t.Errorf("Mutuals = %v", suggestion.Mutuals)
+	}
+	if !reflect.DeepEqual(suggestion.SharedHashtags, []string{"go", "mysql"}) {
+		t.Errorf("SharedHashtags = %v", suggestion.SharedHashtags)
+	}
+	if suggestion.Score != 6 {
+		t.Errorf("Score = %v, esperado 6", suggestion.Score)
+	}
+}
@@ -0,0 +1,125 @@
+package webhooks
+
+import (
+	"api/src/models"
+	"api/src/repository"
+	"api/src/security"
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	batchSize   = 50
+	maxAttempts = 8
+	baseBackoff = 30 * time.Second
+	lease       = 2 * time.Minute
+)
+
+// Dispatcher lê o outbox e entrega os eventos aos webhooks cadastrados
+type Dispatcher struct {
+	repo     *repository.Webhooks
+	client   *http.Client
+	interval time.Duration
+}
+
+func NewDispatcher(db *sql.DB, interval time.Duration) *Dispatcher {
+	return &Dispatcher{
+		repo:     repository.NewWebhooksRepository(db),
+		client:   &http.Client{Timeout: 10 * time.Second},
+		interval: interval,
+	}
+}
+
+// Run executa o dispatcher indefinidamente, devendo ser chamado em uma goroutine
+func (d *Dispatcher) Run() {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := d.repo.DispatchOutbox(batchSize); err != nil {
+			log.Println("webhooks:", err)
+			continue
+		}
+
+		deliveries, err := d.repo.ClaimDueDeliveries(batchSize, lease)
+		if err != nil {
+			log.Println("webhooks:", err)
+			continue
+		}
+
+		for _, delivery := range deliveries {
+			d.deliver(&delivery)
+			if err := d.repo.SaveAttempt(delivery); err != nil {
+				log.Println("webhooks:", err)
+			}
+		}
+	}
+}
+
+func (d *Dispatcher) deliver(delivery *models.WebhookDelivery) {
+	body, err := json.Marshal(struct {
+		ID        uint64          `json:"id"`
+		Type      string          `json:"type"`
+		CreatedAt time.Time       `json:"created_at"`
+		Data      json.RawMessage `json:"data"`
+	}{
+		ID:        delivery.Event.ID,
+		Type:      delivery.Event.Type,
+		CreatedAt: delivery.Event.CreatedAt,
+		Data:      json.RawMessage(delivery.Event.Payload),
+	})
+	if err != nil {
+		d.fail(delivery, 0, err)
+		return
+	}
+
+	request, err := http.NewRequest(http.MethodPost, delivery.Webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		d.fail(delivery, 0, err)
+		return
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("X-Devbook-Event", delivery.Event.Type)
+	request.Header.Set("X-Devbook-Delivery", strconv.FormatUint(delivery.ID, 10))
+	request.Header.Set("X-Devbook-Signature", "sha256="+security.Sign(delivery.Webhook.Secret, body))
+
+	response, err := d.client.Do(request)
+	if err != nil {
+		d.fail(delivery, 0, err)
+		return
+	}
+	response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		d.fail(delivery, response.StatusCode, fmt.Errorf("resposta inesperada: %s", response.Status))
+		return
+	}
+
+	delivery.Attempts++
+	delivery.Status = models.DeliveryDelivered
+	delivery.StatusCode = response.StatusCode
+	delivery.Error = ""
+	delivery.NextAttemptAt = time.Now()
+}
+
+// fail agenda a próxima tentativa com backoff exponencial ou desiste após maxAttempts
+func (d *Dispatcher) fail(delivery *models.WebhookDelivery, statusCode int, err error) {
+	delivery.Attempts++
+	delivery.StatusCode = statusCode
+	delivery.Error = err.Error()
+
+	if delivery.Attempts >= maxAttempts {
+		delivery.Status = models.DeliveryFailed
+		delivery.NextAttemptAt = time.Now()
+		return
+	}
+
+	delivery.Status = models.DeliveryPending
+	delivery.NextAttemptAt = time.Now().Add(baseBackoff << (delivery.Attempts - 1))
+}